@@ -0,0 +1,223 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/encoding"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+// doctorCmd checks the prerequisites of a synchronization, without
+// synchronizing any issue.
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration, credentials, Jira project, custom fields and GitHub repositories",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		enc, err := formatEncoder(opts.Format, doctorFormatText)
+		if err != nil {
+			return err
+		}
+
+		w := cmd.OutOrStdout()
+		cfg, err := config.New(commandContext(cmd), cmd)
+		if err != nil {
+			return reportChecks(w, enc, []checkResult{
+				{name: configCheckName, err: fmt.Errorf("creating new config: %w", err)},
+			})
+		}
+
+		results := []checkResult{{name: configCheckName, details: cfg.GetConfigFile()}}
+		results = append(results, runChecks([]doctorCheck{
+			jiraAuthCheck(cfg),
+			jiraConfigCheck(cfg),
+			githubReposCheck(cfg, func() (github.Client, error) { return newGitHubClient(cfg) }),
+		})...)
+
+		return reportChecks(w, enc, results)
+	},
+}
+
+const (
+	// doctorFormatText is the default format of the doctor command: a
+	// checklist with a line per check.
+	doctorFormatText = "text"
+
+	// doctorKey is the key of the list of checks in the formats other than
+	// text.
+	doctorKey = "checks"
+
+	// configCheckName is the name of the check of the configuration, which
+	// every other check depends on.
+	configCheckName = "Configuration"
+)
+
+func init() {
+	doctorCmd.Flags().StringVar(
+		&opts.Format,
+		options.ConfigKeyFormat,
+		doctorFormatText,
+		"format of the checklist: text, json, yaml or toml",
+	)
+}
+
+// doctorCheck is a prerequisite of a synchronization checked by the doctor
+// command.
+type doctorCheck struct {
+	name string
+	// run performs the check. The details it returns are printed if the
+	// check passes.
+	run func() (string, error)
+}
+
+// checkResult is the outcome of a doctorCheck.
+type checkResult struct {
+	name    string
+	details string
+	err     error
+}
+
+// runChecks performs every check, even if some fail.
+func runChecks(checks []doctorCheck) []checkResult {
+	results := make([]checkResult, len(checks))
+	for i, check := range checks {
+		details, err := check.run()
+		results[i] = checkResult{name: check.name, details: details, err: err}
+	}
+
+	return results
+}
+
+// reportChecks writes whether each check passed: as a checklist if enc is
+// nil, or else as the list under the `checks` key of a document encoded by
+// enc. It returns an error if any check failed.
+func reportChecks(w io.Writer, enc encoding.Encoder, results []checkResult) error {
+	failed := 0
+	records := make([]map[string]interface{}, len(results))
+	for i, result := range results {
+		record := map[string]interface{}{
+			"name":   result.name,
+			"passed": result.err == nil,
+		}
+		if result.err != nil {
+			failed++
+			record["error"] = result.err.Error()
+		} else if result.details != "" {
+			record["details"] = result.details
+		}
+		records[i] = record
+
+		if enc == nil {
+			printCheck(w, result)
+		}
+	}
+
+	if enc != nil {
+		if err := writeEncoded(w, enc, map[string]interface{}{doctorKey: records}); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results)) //nolint:goerr113
+	}
+
+	return nil
+}
+
+// printCheck prints the outcome of a check as a line of the checklist.
+func printCheck(w io.Writer, result checkResult) {
+	switch {
+	case result.err != nil:
+		fmt.Fprintf(w, "[FAIL] %s: %v\n", result.name, result.err)
+	case result.details != "":
+		fmt.Fprintf(w, "[PASS] %s: %s\n", result.name, result.details)
+	default:
+		fmt.Fprintf(w, "[PASS] %s\n", result.name)
+	}
+}
+
+// jiraAuthCheck checks that the Jira credentials authenticate.
+func jiraAuthCheck(cfg *config.Config) doctorCheck {
+	return doctorCheck{
+		name: "Jira credentials",
+		run: func() (string, error) {
+			user, err := jira.CheckAuth(cfg)
+			if err != nil {
+				return "", err
+			}
+
+			name := user.DisplayName
+			if name == "" {
+				name = user.Name
+			}
+			return "authenticated as " + name, nil
+		},
+	}
+}
+
+// jiraConfigCheck checks that the Jira project, components and required
+// custom fields exist. The optional custom fields which do not exist are
+// listed, but do not fail the check.
+func jiraConfigCheck(cfg *config.Config) doctorCheck {
+	return doctorCheck{
+		name: "Jira project and custom fields",
+		run: func() (string, error) {
+			if _, err := jira.New(cfg); err != nil {
+				return "", err
+			}
+
+			details := "project " + cfg.GetProjectKey()
+			if missing := cfg.MissingOptionalFields(); len(missing) > 0 {
+				details += "; optional custom fields not found: " + strings.Join(missing, ", ")
+			}
+			return details, nil
+		},
+	}
+}
+
+// githubReposCheck checks that the GitHub credentials authenticate, and
+// give access to every configured repository.
+func githubReposCheck(cfg *config.Config, newClient func() (github.Client, error)) doctorCheck {
+	return doctorCheck{
+		name: "GitHub repositories",
+		run: func() (string, error) {
+			ghClient, err := newClient()
+			if err != nil {
+				return "", err
+			}
+
+			var found []string
+			for _, repo := range cfg.GetRepos() {
+				repository, err := ghClient.GetRepository(repo[0], repo[1])
+				if err != nil {
+					return "", err
+				}
+				found = append(found, repository.GetFullName())
+			}
+			return "found " + strings.Join(found, ", "), nil
+		},
+	}
+}
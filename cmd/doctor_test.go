@@ -0,0 +1,127 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	gogh "github.com/google/go-github/v56/github"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+func TestRunChecks(t *testing.T) {
+	checks := []doctorCheck{
+		{name: "First", run: func() (string, error) { return "", nil }},
+		{name: "Second", run: func() (string, error) { return "", errors.New("broken") }},
+		{name: "Third", run: func() (string, error) { return "all good", nil }},
+	}
+
+	var buf bytes.Buffer
+	err := reportChecks(&buf, nil, runChecks(checks))
+	if err == nil || err.Error() != "1 of 3 checks failed" {
+		t.Fatalf("Expected 1 of 3 checks to fail; got %v", err)
+	}
+
+	expected := "[PASS] First\n" +
+		"[FAIL] Second: broken\n" +
+		"[PASS] Third: all good\n"
+	if buf.String() != expected {
+		t.Fatalf("Expected checklist:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestReportChecksFormats(t *testing.T) {
+	results := []checkResult{
+		{name: "First", details: "all good"},
+		{name: "Second", err: errors.New("broken")},
+	}
+
+	for _, format := range []string{"json", "yaml", "toml"} {
+		t.Run(format, func(t *testing.T) {
+			enc, err := formatEncoder(format, doctorFormatText)
+			if err != nil {
+				t.Fatalf("formatEncoder() returned error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := reportChecks(&buf, enc, results); err == nil {
+				t.Fatal("Expected an error for the failed check")
+			}
+
+			values, err := enc.Decode(buf.Bytes())
+			if err != nil {
+				t.Fatalf("decoding checks: %v\n%s", err, buf.String())
+			}
+			checks, ok := values[doctorKey].([]interface{})
+			if !ok || len(checks) != 2 {
+				t.Fatalf("Expected two checks; got %v", values)
+			}
+
+			expected := []map[string]interface{}{
+				{"name": "First", "passed": true, "details": "all good"},
+				{"name": "Second", "passed": false, "error": "broken"},
+			}
+			for i, check := range checks {
+				if !reflect.DeepEqual(check, expected[i]) {
+					t.Fatalf("Expected check %v; got %v", expected[i], check)
+				}
+			}
+		})
+	}
+
+	if _, err := formatEncoder("ini", doctorFormatText); err == nil {
+		t.Fatal("Expected an error for an unsupported format")
+	}
+}
+
+func TestGitHubReposCheck(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyRepoName: "test-owner/test-repo, test-owner/other-repo",
+	})
+
+	check := githubReposCheck(cfg, func() (github.Client, error) {
+		return &github.GitHubClientMock{}, nil
+	})
+	details, err := check.run()
+	if err != nil {
+		t.Fatalf("Expected the check to pass; got %v", err)
+	}
+	if expected := "found test-owner/test-repo, test-owner/other-repo"; details != expected {
+		t.Fatalf("Expected details %q; got %q", expected, details)
+	}
+
+	check = githubReposCheck(cfg, func() (github.Client, error) {
+		return &github.GitHubClientMock{
+			GetRepositoryFn: func(owner, repo string) (*gogh.Repository, error) {
+				if repo == "other-repo" {
+					return nil, errors.New("not found")
+				}
+				return &gogh.Repository{FullName: gogh.String(owner + "/" + repo)}, nil
+			},
+		}, nil
+	})
+	if _, err := check.run(); err == nil {
+		t.Fatal("Expected the check to fail for a missing repository")
+	}
+}
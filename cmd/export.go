@@ -0,0 +1,210 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira/issue"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+const (
+	// exportFormatCSV is the default format of the export.
+	exportFormatCSV = "csv"
+
+	// exportKey is the key of the list of exported records in the formats
+	// other than CSV.
+	exportKey = "issues"
+)
+
+// exportHeader is the header row of the exported CSV mapping, and the keys
+// of its records in the other formats.
+var exportHeader = []string{"github-number", "github-id", "jira-key", "status", "last-sync"}
+
+// unmatchedHeader is the header row of the exported CSV of unmatched
+// GitHub issues, and the keys of its records in the other formats.
+var unmatchedHeader = []string{"github-number", "github-id", "title", "url"}
+
+// exportCmd writes the mapping between GitHub issues and the Jira issues
+// they're synchronized to.
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the GitHub to Jira issue mapping, or the unmatched GitHub issues, as CSV, JSON, YAML or TOML",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, ghClient, jiraClient, err := newClients(cmd)
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if opts.ExportOutput != "" {
+			f, err := os.Create(opts.ExportOutput)
+			if err != nil {
+				return fmt.Errorf("creating export file %s: %w", opts.ExportOutput, err)
+			}
+			defer f.Close()
+
+			w = f
+		}
+
+		if opts.ExportListUnmatched {
+			return exportUnmatched(cfg, ghClient, jiraClient, w, opts.Format)
+		}
+		return exportMapping(cfg, ghClient, jiraClient, w, opts.Format)
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(
+		&opts.ExportOutput,
+		options.ConfigKeyExportOutput,
+		"o",
+		"",
+		"file to write the export to; defaults to stdout",
+	)
+
+	exportCmd.Flags().BoolVar(
+		&opts.ExportListUnmatched,
+		options.ConfigKeyExportListUnmatched,
+		false,
+		"export the GitHub issues which have no Jira issue yet, and would be created, instead of the mapping",
+	)
+
+	exportCmd.Flags().StringVar(
+		&opts.Format,
+		options.ConfigKeyFormat,
+		exportFormatCSV,
+		"format of the export: csv, json, yaml or toml",
+	)
+}
+
+// exportMapping writes a record for every GitHub issue of the configured
+// repository which has a matching Jira issue.
+func exportMapping(
+	cfg *config.Config, ghClient github.Client, jiraClient jira.Client, w io.Writer, format string,
+) error {
+	owner, repo := cfg.GetRepo()
+	ghIssues, err := ghClient.ListIssues(owner, repo, github.ListIssuesOptions{})
+	if err != nil {
+		return fmt.Errorf("listing GitHub issues: %w", err)
+	}
+
+	ids := make([]int, len(ghIssues))
+	for i, v := range ghIssues {
+		ids[i] = int(v.GetID())
+	}
+
+	jiraIssues, err := jiraClient.ListIssues(ids)
+	if err != nil {
+		return fmt.Errorf("listing Jira issues: %w", err)
+	}
+
+	lastSyncKey := cfg.GetFieldKey(config.GitHubLastSync)
+	records := make([]map[string]interface{}, 0, len(ghIssues))
+	for _, ghIssue := range ghIssues {
+		jIssue := issue.FindJiraIssue(cfg, ghIssue, jiraIssues)
+		if jIssue == nil {
+			continue
+		}
+
+		var status string
+		if jIssue.Fields.Status != nil {
+			status = jIssue.Fields.Status.Name
+		}
+
+		lastSync, _ := jira.FieldString(jIssue, lastSyncKey) //nolint:errcheck // empty if never synced
+
+		records = append(records, map[string]interface{}{
+			"github-number": ghIssue.GetNumber(),
+			"github-id":     ghIssue.GetID(),
+			"jira-key":      jIssue.Key,
+			"status":        status,
+			"last-sync":     lastSync,
+		})
+	}
+
+	return writeExport(w, format, exportHeader, records)
+}
+
+// exportUnmatched writes a record for every GitHub issue of the configured
+// repository which has no matching Jira issue yet, and so would be created
+// by a synchronization.
+func exportUnmatched(
+	cfg *config.Config, ghClient github.Client, jiraClient jira.Client, w io.Writer, format string,
+) error {
+	ghIssues, err := issue.Unmatched(cfg, ghClient, jiraClient)
+	if err != nil {
+		return fmt.Errorf("listing unmatched GitHub issues: %w", err)
+	}
+
+	records := make([]map[string]interface{}, len(ghIssues))
+	for i, ghIssue := range ghIssues {
+		records[i] = map[string]interface{}{
+			"github-number": ghIssue.GetNumber(),
+			"github-id":     ghIssue.GetID(),
+			"title":         ghIssue.GetTitle(),
+			"url":           ghIssue.GetHTMLURL(),
+		}
+	}
+
+	return writeExport(w, format, unmatchedHeader, records)
+}
+
+// writeExport writes the exported records, whose keys are those of header.
+// As CSV, each record is a row whose columns are in the order of header;
+// in any other format, the records are the list under the `issues` key of
+// a document encoded by the Encoder of that format.
+func writeExport(w io.Writer, format string, header []string, records []map[string]interface{}) error {
+	enc, err := formatEncoder(format, exportFormatCSV)
+	if err != nil {
+		return err
+	}
+	if enc != nil {
+		return writeEncoded(w, enc, map[string]interface{}{exportKey: records})
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("writing export header: %w", err)
+	}
+
+	for _, record := range records {
+		row := make([]string, len(header))
+		for i, key := range header {
+			row[i] = fmt.Sprint(record[key])
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("writing export row for #%v: %w", record["github-number"], err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("flushing export: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	gogh "github.com/google/go-github/v56/github"
+	"github.com/trivago/tgo/tcontainer"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/encoding"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+func TestExportMapping(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	ghIssues := []*gogh.Issue{
+		{ID: gogh.Int64(1001), Number: gogh.Int(1)},
+		{ID: gogh.Int64(1002), Number: gogh.Int(2)},
+		{ID: gogh.Int64(1003), Number: gogh.Int(3)},
+	}
+
+	newJiraIssue := func(key string, ghID float64, status, lastSync string) gojira.Issue {
+		unknowns := tcontainer.NewMarshalMap()
+		unknowns.Set(cfg.GetFieldKey(config.GitHubID), ghID)
+		unknowns.Set(cfg.GetFieldKey(config.GitHubLastSync), lastSync)
+		return gojira.Issue{
+			Key: key,
+			Fields: &gojira.IssueFields{
+				Status:   &gojira.Status{Name: status},
+				Unknowns: unknowns,
+			},
+		}
+	}
+
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			return ghIssues, nil
+		},
+	}
+	jiraClient := &jira.JiraClientMock{
+		ListIssuesFn: func(ids []int) ([]gojira.Issue, error) {
+			return []gojira.Issue{
+				newJiraIssue("TEST-2", 1002, "Done", "2023-02-01T10:00:00.0+0000"),
+				newJiraIssue("TEST-1", 1001, "To Do", "2023-01-01T10:00:00.0+0000"),
+			}, nil
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := exportMapping(cfg, ghClient, jiraClient, &buf, exportFormatCSV); err != nil {
+		t.Fatalf("exportMapping() returned error: %v", err)
+	}
+
+	expected := "github-number,github-id,jira-key,status,last-sync\n" +
+		"1,1001,TEST-1,To Do,2023-01-01T10:00:00.0+0000\n" +
+		"2,1002,TEST-2,Done,2023-02-01T10:00:00.0+0000\n"
+	if buf.String() != expected {
+		t.Fatalf("Expected export:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestExportMappingFormats(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			return []*gogh.Issue{{ID: gogh.Int64(1001), Number: gogh.Int(1)}}, nil
+		},
+	}
+	jiraClient := &jira.JiraClientMock{
+		ListIssuesFn: func(ids []int) ([]gojira.Issue, error) {
+			unknowns := tcontainer.NewMarshalMap()
+			unknowns.Set(cfg.GetFieldKey(config.GitHubID), float64(1001))
+			unknowns.Set(cfg.GetFieldKey(config.GitHubLastSync), "2023-01-01T10:00:00.0+0000")
+			return []gojira.Issue{{
+				Key:    "TEST-1",
+				Fields: &gojira.IssueFields{Status: &gojira.Status{Name: "To Do"}, Unknowns: unknowns},
+			}}, nil
+		},
+	}
+
+	expected := map[string]string{
+		"github-number": "1",
+		"github-id":     "1001",
+		"jira-key":      "TEST-1",
+		"status":        "To Do",
+		"last-sync":     "2023-01-01T10:00:00.0+0000",
+	}
+
+	for _, format := range []string{"json", "yaml", "toml"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := exportMapping(cfg, ghClient, jiraClient, &buf, format); err != nil {
+				t.Fatalf("exportMapping() returned error: %v", err)
+			}
+
+			enc, err := encoding.ByExtension(format)
+			if err != nil {
+				t.Fatalf("ByExtension() returned error: %v", err)
+			}
+			values, err := enc.Decode(buf.Bytes())
+			if err != nil {
+				t.Fatalf("decoding export: %v\n%s", err, buf.String())
+			}
+
+			records, ok := values[exportKey].([]interface{})
+			if !ok || len(records) != 1 {
+				t.Fatalf("Expected a single exported record; got %v", values)
+			}
+			record, ok := records[0].(map[string]interface{})
+			if !ok {
+				t.Fatalf("Expected the exported record to be a map; got %T", records[0])
+			}
+			for key, value := range expected {
+				if fmt.Sprint(record[key]) != value {
+					t.Fatalf("Expected %s %q; got %v", key, value, record[key])
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := exportMapping(cfg, ghClient, jiraClient, &buf, "ini"); err == nil {
+		t.Fatal("Expected an error for an unsupported format")
+	}
+}
+
+func TestExportUnmatched(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyExcludeLabels: []string{"wontfix"},
+	})
+
+	newGitHubIssue := func(id int64, number int, title string, labels ...string) *gogh.Issue {
+		ghIssue := &gogh.Issue{
+			ID:      gogh.Int64(id),
+			Number:  gogh.Int(number),
+			Title:   gogh.String(title),
+			HTMLURL: gogh.String(fmt.Sprintf("https://github.com/test-owner/test-repo/issues/%d", number)),
+		}
+		for _, label := range labels {
+			ghIssue.Labels = append(ghIssue.Labels, &gogh.Label{Name: gogh.String(label)})
+		}
+		return ghIssue
+	}
+
+	var listOpts github.ListIssuesOptions
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			listOpts = opts
+			return []*gogh.Issue{
+				newGitHubIssue(1001, 1, "Synchronized"),
+				newGitHubIssue(1002, 2, "Not synchronized yet"),
+				newGitHubIssue(1003, 3, "Excluded", "wontfix"),
+				newGitHubIssue(1004, 4, "Also, not synchronized yet"),
+			}, nil
+		},
+	}
+	jiraClient := &jira.JiraClientMock{
+		ListIssuesFn: func(ids []int) ([]gojira.Issue, error) {
+			unknowns := tcontainer.NewMarshalMap()
+			unknowns.Set(cfg.GetFieldKey(config.GitHubID), float64(1001))
+			return []gojira.Issue{{Key: "TEST-1", Fields: &gojira.IssueFields{Unknowns: unknowns}}}, nil
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := exportUnmatched(cfg, ghClient, jiraClient, &buf, exportFormatCSV); err != nil {
+		t.Fatalf("exportUnmatched() returned error: %v", err)
+	}
+
+	if !listOpts.Since.IsZero() {
+		t.Fatalf("Expected the issues not to be filtered by since; Got %v", listOpts.Since)
+	}
+
+	expected := "github-number,github-id,title,url\n" +
+		"2,1002,Not synchronized yet,https://github.com/test-owner/test-repo/issues/2\n" +
+		"4,1004,\"Also, not synchronized yet\",https://github.com/test-owner/test-repo/issues/4\n"
+	if buf.String() != expected {
+		t.Fatalf("Expected export:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
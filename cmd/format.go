@@ -0,0 +1,55 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/encoding"
+)
+
+// formatEncoder returns the Encoder of the `--format` of a command: json,
+// yaml or toml. It returns nil if format is the default format of the
+// command, which the command writes itself.
+func formatEncoder(format, defaultFormat string) (encoding.Encoder, error) {
+	if strings.EqualFold(format, defaultFormat) {
+		return nil, nil //nolint:nilnil // the default format has no Encoder
+	}
+
+	enc, err := encoding.ByExtension(format)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported format %q: %w", format, err)
+	}
+
+	return enc, nil
+}
+
+// writeEncoded writes the document encoded by enc.
+func writeEncoded(w io.Writer, enc encoding.Encoder, values map[string]interface{}) error {
+	b, err := enc.Encode(values)
+	if err != nil {
+		return fmt.Errorf("encoding output: %w", err)
+	}
+
+	if _, err := w.Write(b); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	return nil
+}
@@ -18,30 +18,57 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 	"sigs.k8s.io/release-utils/log"
 	"sigs.k8s.io/release-utils/version"
 
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	synchttp "github.com/uwu-tools/gh-jira-issue-sync/internal/http"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira/issue"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/metrics"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/notify"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
 )
 
 var opts = &options.Options{}
 
 // Execute provides a single function to run the root command and handle errors.
+// The command is canceled on SIGINT or SIGTERM, which aborts the requests in
+// flight and stops a daemon once the current synchronization is saved.
 func Execute() {
-	if err := RootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := RootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		logrus.Fatal(err)
 	}
 }
 
+// commandContext returns the context of the command, which is canceled on
+// shutdown, or the background context if the command was not executed with
+// one, as in tests.
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
 // RootCmd represents the command itself and configures it.
 var RootCmd = &cobra.Command{
 	Use:               fmt.Sprintf("%s [options]", options.AppName),
@@ -49,40 +76,357 @@ var RootCmd = &cobra.Command{
 	Long:              "Full docs coming later; see https://github.com/uwu-tools/gh-jira-issue-sync",
 	PersistentPreRunE: initLogging,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		cfg, err := config.New(ctx, cmd)
-		if err != nil {
-			return fmt.Errorf("creating new config: %w", err)
+		if opts.CheckAuth {
+			return checkAuth(cmd)
 		}
 
-		jiraClient, err := jira.New(cfg)
+		cfg, ghClient, jiraClient, err := newClients(cmd)
 		if err != nil {
-			return fmt.Errorf("creating Jira client: %w", err)
+			return err
+		}
+
+		// Synchronization failures are not usage errors.
+		cmd.SilenceUsage = true
+
+		if err := serveMetrics(cfg); err != nil {
+			return err
+		}
+
+		return run(cfg, ghClient, jiraClient)
+	},
+}
+
+// run waits for the startup delay, then synchronizes once, or on every
+// period if running as a daemon. When synchronizing once, it returns the
+// errors of the synchronization, so that they fail the command; a daemon
+// carries on with the next period instead.
+//
+// A shutdown during the startup delay returns the error of the context, as
+// nothing was synchronized; a daemon shutting down between periods returns
+// nil.
+func run(cfg *config.Config, ghClient github.Client, jiraClient jira.Client) error {
+	if d := cfg.GetStartupDelay(); d > 0 {
+		logrus.Infof("Waiting %v before the first synchronization", d)
+		if err := wait(cfg, d); err != nil {
+			logrus.Info("Shutting down before the first synchronization")
+			return err
+		}
+	}
+
+	for {
+		err := reconcile(cfg, ghClient, jiraClient)
+		if !cfg.IsDaemon() {
+			return err
+		}
+
+		if err := wait(cfg, cfg.GetDaemonPeriod()); err != nil {
+			logrus.Info("Shutting down")
+			return nil
 		}
+	}
+}
+
+// wait waits for the duration to elapse on the clock of the configuration,
+// unless its context is done first, in which case it returns the error of
+// the context.
+func wait(cfg *config.Config, d time.Duration) error {
+	ctx := cfg.Context()
+
+	// A context which is already done wins over a clock which does not
+	// actually wait.
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	select {
+	case <-cfg.Clock().After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	}
+}
+
+// serveMetrics serves Prometheus metrics in the background until shutdown,
+// if `metrics-addr` is set. Only failing to listen on the address fails the
+// command; later errors of the server are logged.
+func serveMetrics(cfg *config.Config) error {
+	addr := cfg.GetMetricsAddr()
+	if addr == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listening on metrics address: %w", err)
+	}
+
+	go func() {
+		if err := metrics.Serve(cfg.Context(), listener); err != nil {
+			logrus.Error(err)
+		}
+	}()
+
+	return nil
+}
+
+// reconcile runs a single synchronization pass over every configured
+// repository, bounded by the configured maximum run duration, and saves the
+// configuration afterwards. If the pass is aborted because it ran out of
+// time, the saved `since` date is that of the last issue processed, so the
+// next pass picks up the remaining issues.
+//
+// The errors of every repository are logged as they occur, and returned
+// joined.
+func reconcile(cfg *config.Config, ghClient github.Client, jiraClient jira.Client) error {
+	cfg.StartRun()
+
+	start := cfg.Clock().Now()
+	defer func() {
+		metrics.SyncDuration.Observe(cfg.Clock().Now().Sub(start).Seconds())
+	}()
+
+	// The previous custom field IDs are kept if they can't be refreshed, as
+	// they are likely still valid.
+	if refreshed, err := jiraClient.RefreshFieldIDs(); err != nil {
+		logrus.Errorf("Error refreshing custom field IDs: %v", err)
+	} else if refreshed {
+		logrus.Debug("Refreshed custom field IDs")
+	}
+	if cfg.IsFullReconcile() {
+		logrus.Info("Running a full reconcile of all comments")
+	}
 
-		ghToken := cfg.GetConfigString(options.ConfigKeyGitHubToken)
-		ghClient, err := github.New(ghToken)
+	ctx := cfg.Context()
+	if d := cfg.GetMaxRunDuration(); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	// The requests of the clients are bounded by the run as well, so that a
+	// slow request or its retries don't overrun the maximum run duration.
+	cfg.SetRunContext(ctx)
+	defer cfg.SetRunContext(nil)
+
+	var lastSync time.Time
+	if cfg.ShouldDeriveSinceFromJira() {
+		since, err := jiraClient.GetLastSyncTime()
 		if err != nil {
-			return fmt.Errorf("creating GitHub client: %w", err)
+			// The configured date may be stale, e.g. on a fresh installation,
+			// so skip this run instead of synchronizing every issue again.
+			logrus.Errorf("Error deriving since date from Jira: %v", err)
+			return fmt.Errorf("deriving since date from Jira: %w", err)
 		}
+		lastSync = since
+	}
 
-		for {
-			if err := issue.Compare(cfg, ghClient, jiraClient); err != nil {
-				// TODO(log): Better error message
-				logrus.Error(err)
-			}
-			if !cfg.IsDryRun() {
-				if err := cfg.SaveConfig(); err != nil {
-					// TODO(log): Better error message
-					logrus.Error(err)
-				}
+	var total issue.Result
+	var errs []error
+	for _, repo := range cfg.GetRepos() {
+		cfg.SetRepo(repo[0], repo[1])
+		if cfg.ShouldDeriveSinceFromJira() {
+			if lastSync.IsZero() {
+				logrus.Infof("No Jira issue was synchronized yet; using since date %v", cfg.GetSinceParam())
+			} else {
+				logrus.Infof("Using since date %v from the last Jira synchronization", lastSync)
+				cfg.SetSince(repo[0]+"/"+repo[1], lastSync)
 			}
-			if !cfg.IsDaemon() {
-				return nil
-			}
-			<-time.After(cfg.GetDaemonPeriod())
 		}
-	},
+
+		result, err := reconcileRepo(ctx, cfg, ghClient, jiraClient)
+		total.Add(result)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("synchronizing %s/%s: %w", repo[0], repo[1], err))
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			// The remaining repositories would be aborted right away.
+			break
+		}
+	}
+
+	if cfg.ShouldPruneIssues() && ctx.Err() == nil {
+		pruned, err := issue.Prune(ctx, cfg, ghClient, jiraClient)
+		if err != nil {
+			logrus.Errorf("Error pruning Jira issues: %v", err)
+			errs = append(errs, fmt.Errorf("pruning Jira issues: %w", err))
+		}
+		logrus.Infof("Pruned %d Jira issues whose GitHub issue was deleted", pruned)
+	}
+
+	if !cfg.IsDryRun() && cfg.ShouldSaveState() {
+		if err := cfg.SaveState(); err != nil {
+			// TODO(log): Better error message
+			logrus.Error(err)
+			errs = append(errs, err)
+		}
+	}
+
+	logrus.Infof(
+		"Synchronization finished: %d created, %d updated, %d skipped, %d failed",
+		total.Created,
+		total.Updated,
+		total.Skipped,
+		total.Failed,
+	)
+
+	return errors.Join(errs...)
+}
+
+// reconcileRepo synchronizes the issues of the repository selected with
+// cfg.SetRepo, and returns a summary of the synchronization and its errors,
+// once they are logged.
+func reconcileRepo(
+	ctx context.Context,
+	cfg *config.Config,
+	ghClient github.Client,
+	jiraClient jira.Client,
+) (issue.Result, error) {
+	owner, repo := cfg.GetRepo()
+
+	result, err := issue.Compare(ctx, cfg, ghClient, jiraClient)
+	logrus.Infof(
+		"Synchronized issues of %s/%s: %d created, %d updated, %d skipped, %d failed",
+		owner,
+		repo,
+		result.Created,
+		result.Updated,
+		result.Skipped,
+		result.Failed,
+	)
+	notifyFailures(cfg, result, err)
+
+	if err != nil {
+		// The errors of the issues which failed were logged by Compare
+		// already.
+		if result.Failed == 0 {
+			// TODO(log): Better error message
+			logrus.Error(err)
+		}
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			logrus.Warnf(
+				"Synchronization exceeded the maximum run duration of %v; resuming from %v on the next run",
+				cfg.GetMaxRunDuration(),
+				cfg.GetSinceParam(),
+			)
+		}
+	}
+
+	return result, err
+}
+
+// notifyFailures posts a summary of the synchronization to the failure
+// webhook, if one is configured and the synchronization had failures. It is
+// best-effort: errors are logged, but don't fail the synchronization.
+func notifyFailures(cfg *config.Config, result issue.Result, syncErr error) {
+	webhook := cfg.GetFailureWebhookURL()
+	if webhook == "" {
+		return
+	}
+
+	owner, repo := cfg.GetRepo()
+
+	summary := &notify.Summary{
+		Repo:        fmt.Sprintf("%s/%s", owner, repo),
+		JiraProject: cfg.GetProjectKey(),
+		Created:     result.Created,
+		Updated:     result.Updated,
+		Skipped:     result.Skipped,
+		Failed:      result.Failed,
+	}
+	if syncErr != nil {
+		summary.Error = syncErr.Error()
+	}
+
+	client := &http.Client{Timeout: cfg.GetTimeout()}
+	if err := notify.PostFailures(cfg.Context(), client, webhook, summary); err != nil {
+		logrus.Warnf("Error notifying failure webhook: %v", err)
+	}
+}
+
+// checkAuth reports whether the configured Jira credentials authenticate,
+// without synchronizing anything.
+func checkAuth(cmd *cobra.Command) error {
+	cfg, err := config.New(commandContext(cmd), cmd)
+	if err != nil {
+		return fmt.Errorf("creating new config: %w", err)
+	}
+
+	user, err := jira.CheckAuth(cfg)
+	if err != nil {
+		return fmt.Errorf("checking Jira credentials: %w", err)
+	}
+
+	name := user.DisplayName
+	if name == "" {
+		name = user.Name
+	}
+	logrus.Infof("Jira credentials authenticate as %s", name)
+
+	return nil
+}
+
+// newClients creates the configuration for the command, along with the
+// GitHub and Jira clients it configures.
+func newClients(cmd *cobra.Command) (*config.Config, github.Client, jira.Client, error) {
+	cfg, err := config.New(commandContext(cmd), cmd)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("creating new config: %w", err)
+	}
+	// JSON logs are meant to be collected together, so they are always
+	// correlated by the run ID.
+	if cfg.ShouldLogRunID() || opts.LogFormat == options.LogFormatJSON {
+		logrus.AddHook(cfg.RunIDHook())
+	}
+
+	jiraClient, err := jira.New(cfg)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("creating Jira client: %w", err)
+	}
+
+	ghClient, err := newGitHubClient(cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return cfg, ghClient, jiraClient, nil
+}
+
+// newGitHubClient creates the GitHub client, authenticated with the
+// configured GitHub App or token.
+func newGitHubClient(cfg *config.Config) (github.Client, error) {
+	retry := synchttp.Retry{
+		InitialInterval: cfg.GetRetryInitialInterval(),
+		MaxInterval:     cfg.GetRetryMaxInterval(),
+		Multiplier:      cfg.GetRetryMultiplier(),
+	}
+
+	var ghClient github.Client
+	var err error
+	if cfg.IsGitHubAppAuth() {
+		ghClient, err = github.NewWithApp(
+			cfg.RequestContext,
+			cfg.GetGitHubAppID(),
+			cfg.GetGitHubAppInstallationID(),
+			cfg.GetConfigString(options.ConfigKeyGitHubAppPrivateKeyPath),
+			cfg.GetRateLimitBuffer(),
+			cfg.GetTimeout(),
+			retry,
+		)
+	} else {
+		ghClient, err = github.New(
+			cfg.RequestContext,
+			cfg.GetConfigString(options.ConfigKeyGitHubToken),
+			cfg.GetRateLimitBuffer(),
+			cfg.GetTimeout(),
+			retry,
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating GitHub client: %w", err)
+	}
+
+	return ghClient, nil
 }
 
 func init() {
@@ -94,10 +438,24 @@ func init() {
 	)
 
 	RootCmd.PersistentFlags().StringVar(
-		&opts.ConfigFile,
+		&opts.Color,
+		options.ConfigKeyColor,
+		options.DefaultColor,
+		"whether to color the log output: auto (if it is a terminal), always or never",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.LogFormat,
+		options.ConfigKeyLogFormat,
+		options.DefaultLogFormat,
+		"format of the log output: text or json (which always carries the run ID)",
+	)
+
+	RootCmd.PersistentFlags().StringSliceVar(
+		&opts.ConfigFiles,
 		options.ConfigKeyConfigFile,
-		"",
-		"viper config file location",
+		nil,
+		"viper config file location; may be repeated or comma-separated to merge several files, later files winning",
 	)
 
 	RootCmd.PersistentFlags().StringVarP(
@@ -108,6 +466,27 @@ func init() {
 		"set the API token used to access the GitHub repo",
 	)
 
+	RootCmd.PersistentFlags().Int64Var(
+		&opts.GitHubAppID,
+		options.ConfigKeyGitHubAppID,
+		0,
+		"set the ID of the GitHub App to access the GitHub repo as, instead of github-token",
+	)
+
+	RootCmd.PersistentFlags().Int64Var(
+		&opts.GitHubAppInstallationID,
+		options.ConfigKeyGitHubAppInstallationID,
+		0,
+		"set the ID of the installation of the GitHub App on the GitHub repo",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.GitHubAppPrivateKeyPath,
+		options.ConfigKeyGitHubAppPrivateKeyPath,
+		"",
+		"set the path to the PEM-encoded private key of the GitHub App",
+	)
+
 	RootCmd.PersistentFlags().StringVarP(
 		&opts.JiraUser,
 		options.ConfigKeyJiraUser,
@@ -124,12 +503,20 @@ func init() {
 		"set the Jira password to authenticate with",
 	)
 
+	RootCmd.PersistentFlags().StringVar(
+		&opts.JiraBearerToken,
+		options.ConfigKeyJiraBearerToken,
+		"",
+		"set the Jira Data Center personal access token to authenticate with",
+	)
+
 	RootCmd.PersistentFlags().StringVarP(
 		&opts.RepoName,
 		options.ConfigKeyRepoName,
 		"r",
 		"",
-		"set the repository path (should be form owner/repo)",
+		"set the repository path (should be form owner/repo); "+
+			"may be comma-separated to synchronize several repositories",
 	)
 
 	RootCmd.PersistentFlags().StringVarP(
@@ -161,7 +548,7 @@ func init() {
 		options.ConfigKeySince,
 		"s",
 		options.DefaultSince,
-		"set the day that the update should run forward from",
+		"set the day that the update should run forward from, for this invocation only (not saved)",
 	)
 
 	RootCmd.PersistentFlags().BoolVarP(
@@ -187,13 +574,494 @@ func init() {
 		"how often to synchronize; set to 0 for one-shot mode",
 	)
 
+	RootCmd.PersistentFlags().DurationVar(
+		&opts.MaxRunDuration,
+		options.ConfigKeyMaxRunDuration,
+		options.DefaultMaxRunDuration,
+		"the maximum duration of a single synchronization; set to 0 for no limit",
+	)
+
+	RootCmd.PersistentFlags().DurationVar(
+		&opts.MaxRetryAfter,
+		options.ConfigKeyMaxRetryAfter,
+		options.DefaultMaxRetryAfter,
+		"the longest honored Retry-After wait of an API response; set to 0 to ignore Retry-After",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.MatchStrategy,
+		options.ConfigKeyMatchStrategy,
+		options.DefaultMatchStrategy,
+		"how GitHub issues are matched to Jira issues (jira-field, github-marker)",
+	)
+
+	RootCmd.PersistentFlags().IntVar(
+		&opts.MaxDescriptionLength,
+		options.ConfigKeyMaxDescriptionLength,
+		options.DefaultMaxDescriptionLength,
+		"the maximum length of a Jira issue description; longer GitHub issue bodies are truncated (0 for no limit)",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.SinceFromLastJiraSync,
+		options.ConfigKeySinceFromLastJiraSync,
+		options.DefaultSinceFromLastJiraSync,
+		"derive the since date from the latest github-last-sync value in Jira",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.SyncedLabel,
+		options.ConfigKeySyncedLabel,
+		options.DefaultSyncedLabel,
+		"a Jira label set on every issue managed by the tool, e.g. gh-synced",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.LabelSpaceReplacement,
+		options.ConfigKeyLabelSpaceReplacement,
+		options.DefaultLabelSpaceReplacement,
+		"the string replacing spaces in GitHub labels, which Jira labels can't contain; may be empty",
+	)
+
+	RootCmd.PersistentFlags().IntVar(
+		&opts.FullReconcileEvery,
+		options.ConfigKeyFullReconcileEvery,
+		options.DefaultFullReconcileEvery,
+		"compare all comments of every issue on every Nth run; set to 0 to disable",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.SyncMode,
+		options.ConfigKeySyncMode,
+		options.DefaultSyncMode,
+		"which Jira issues to synchronize (create-only, update-only, both)",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.ReleaseClosedMilestones,
+		options.ConfigKeyReleaseClosedMilestones,
+		options.DefaultReleaseClosedMilestones,
+		"release the Jira version named after a GitHub milestone when the milestone is closed",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.FailureWebhookURL,
+		options.ConfigKeyFailureWebhookURL,
+		options.DefaultFailureWebhookURL,
+		"a URL to post a JSON summary to when a synchronization has failures",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.EnvironmentHeading,
+		options.ConfigKeyEnvironmentHeading,
+		options.DefaultEnvironmentHeading,
+		"the heading of the GitHub issue body section copied to the Jira Environment field, e.g. Environment",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.AssigneeChangeComments,
+		options.ConfigKeyAssigneeChangeComments,
+		options.DefaultAssigneeChangeComments,
+		"post a Jira comment when the assignee of a GitHub issue changes",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.RelinkBySummary,
+		options.ConfigKeyRelinkBySummary,
+		options.DefaultRelinkBySummary,
+		"match GitHub issues to Jira issues without GitHub fields by summary before creating new ones",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.RespectJiraUpdates,
+		options.ConfigKeyRespectJiraUpdates,
+		options.DefaultRespectJiraUpdates,
+		"do not overwrite Jira issues edited since their last synchronization, unless the GitHub issue changed since",
+	)
+
+	RootCmd.PersistentFlags().DurationVar(
+		&opts.SyncUpdateWindow,
+		options.ConfigKeySyncUpdateWindow,
+		options.DefaultSyncUpdateWindow,
+		"how long after a synchronization Jira updates are attributed to it with respect-jira-updates",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.CommentStripQuotes,
+		options.ConfigKeyCommentStripQuotes,
+		options.DefaultCommentStripQuotes,
+		"remove quote blocks from GitHub comments before synchronizing them",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.CommentSeparator,
+		options.ConfigKeyCommentSeparator,
+		options.DefaultCommentSeparator,
+		"do not synchronize the content of GitHub comments after this line, e.g. a signature separator",
+	)
+
+	RootCmd.PersistentFlags().Float64Var(
+		&opts.CreateRate,
+		options.ConfigKeyCreateRate,
+		options.DefaultCreateRate,
+		"the maximum number of Jira issues created per second; set to 0 for no limit",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.SyncTimeline,
+		options.ConfigKeySyncTimeline,
+		options.DefaultSyncTimeline,
+		"post GitHub timeline events (labeled, closed, reopened, referenced) as Jira comments",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.ConvertMarkdown,
+		options.ConfigKeyConvertMarkdown,
+		options.DefaultConvertMarkdown,
+		"convert the Markdown of GitHub comments to Jira wiki markup",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.EscapeMarkup,
+		options.ConfigKeyEscapeMarkup,
+		options.DefaultEscapeMarkup,
+		"escape the Jira wiki markup characters of GitHub comments, so that they are displayed as is",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.DefaultReporter,
+		options.ConfigKeyDefaultReporter,
+		options.DefaultDefaultReporter,
+		"the reporter recorded for GitHub issues without a user, e.g. of deleted accounts",
+	)
+
+	RootCmd.PersistentFlags().StringSliceVar(
+		&opts.OptionalFields,
+		options.ConfigKeyOptionalFields,
+		nil,
+		"required custom fields which may not exist in Jira, and are then not synchronized; "+
+			"github-id is always required",
+	)
+
+	RootCmd.PersistentFlags().StringSliceVar(
+		&opts.IncludeLabels,
+		options.ConfigKeyIncludeLabels,
+		nil,
+		"only synchronize GitHub issues with at least one of these labels",
+	)
+
+	RootCmd.PersistentFlags().StringSliceVar(
+		&opts.ExcludeLabels,
+		options.ConfigKeyExcludeLabels,
+		nil,
+		"do not synchronize GitHub issues with any of these labels",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.PruneIssues,
+		options.ConfigKeyPruneIssues,
+		options.DefaultPruneIssues,
+		"close Jira issues whose GitHub issue was deleted",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.PruneTransition,
+		options.ConfigKeyPruneTransition,
+		options.DefaultPruneTransition,
+		"the Jira transition closing issues pruned with prune-issues; set to \"\" not to transition them",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.PruneLabel,
+		options.ConfigKeyPruneLabel,
+		options.DefaultPruneLabel,
+		"the Jira label added to issues pruned with prune-issues; set to \"\" not to label them",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.SyncMilestones,
+		options.ConfigKeySyncMilestones,
+		options.DefaultSyncMilestones,
+		"set the Jira fix version of issues to a version named after their GitHub milestone, creating it if needed",
+	)
+
+	RootCmd.PersistentFlags().DurationVar(
+		&opts.StartupDelay,
+		options.ConfigKeyStartupDelay,
+		options.DefaultStartupDelay,
+		"how long to wait before the first synchronization, e.g. to stagger the start of replicas",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.SyncIssueState,
+		options.ConfigKeySyncIssueState,
+		options.DefaultSyncIssueState,
+		"the state of the GitHub issues to synchronize: open, closed or all",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.CommentTemplate,
+		options.ConfigKeyCommentTemplate,
+		"",
+		"a Go template rendering the header of the Jira comments copied from GitHub",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.ReporterFormat,
+		options.ConfigKeyReporterFormat,
+		"",
+		"a Go template rendering the github-reporter field from the author of the GitHub issue",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.LinkClosingPullRequests,
+		options.ConfigKeyLinkClosingPullRequests,
+		options.DefaultLinkClosingPullRequests,
+		"add a remote link to the pull request which closed the GitHub issue to its Jira issue",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.LogRateLimitWaits,
+		options.ConfigKeyLogRateLimitWaits,
+		options.DefaultLogRateLimitWaits,
+		"log the waits for rate limited API requests at info level, rather than debug",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.DueDateLabelPrefix,
+		options.ConfigKeyDueDateLabelPrefix,
+		options.DefaultDueDateLabelPrefix,
+		"the prefix of the GitHub labels holding the due date of the Jira issue, e.g. \"due:\"",
+	)
+
+	RootCmd.PersistentFlags().IntVar(
+		&opts.RateLimitBuffer,
+		options.ConfigKeyRateLimitBuffer,
+		options.DefaultRateLimitBuffer,
+		"the number of remaining GitHub API requests below which to wait for the rate limit to reset; 0 never waits",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.SyncLabelColors,
+		options.ConfigKeySyncLabelColors,
+		options.DefaultSyncLabelColors,
+		"write the colors of the GitHub labels to the github-label-colors custom field",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.LogRunID,
+		options.ConfigKeyLogRunID,
+		options.DefaultLogRunID,
+		"add the ID of the synchronization to every log entry, as the run field",
+	)
+
+	RootCmd.PersistentFlags().DurationVar(
+		&opts.FieldRefreshInterval,
+		options.ConfigKeyFieldRefreshInterval,
+		options.DefaultFieldRefreshInterval,
+		"how often a daemon pulls the IDs of the custom fields from Jira again (0 for only at startup)",
+	)
+
+	RootCmd.PersistentFlags().IntVar(
+		&opts.MaxLabels,
+		options.ConfigKeyMaxLabels,
+		options.DefaultMaxLabels,
+		"maximum number of GitHub labels written to a Jira issue (0 for no limit)",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.LabelOrder,
+		options.ConfigKeyLabelOrder,
+		options.DefaultLabelOrder,
+		"order of the labels kept when there are more than max-labels (github, name)",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.LabelPrefix,
+		options.ConfigKeyLabelPrefix,
+		options.DefaultLabelPrefix,
+		"prefix of the GitHub labels written to the github-labels field, e.g. gh/",
+	)
+
+	RootCmd.PersistentFlags().StringSliceVar(
+		&opts.ExcludeJiraStatus,
+		options.ConfigKeyExcludeJiraStatus,
+		nil,
+		"do not update Jira issues in any of these statuses",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.ExcludedStatusComments,
+		options.ConfigKeyExcludedStatusComments,
+		options.DefaultExcludedStatusComments,
+		"still copy GitHub comments to Jira issues in an exclude-jira-status status",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.MilestoneAsSprint,
+		options.ConfigKeyMilestoneAsSprint,
+		options.DefaultMilestoneAsSprint,
+		"set the Jira sprint of each issue to the sprint of jira-board-id named after its GitHub milestone (advanced)",
+	)
+
+	RootCmd.PersistentFlags().IntVar(
+		&opts.JiraBoardID,
+		options.ConfigKeyJiraBoardID,
+		options.DefaultJiraBoardID,
+		"ID of the Jira board whose sprints GitHub milestones are mapped to",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.Explain,
+		options.ConfigKeyExplain,
+		options.DefaultExplain,
+		"log the decision taken for every GitHub issue, and why",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.MetricsAddr,
+		options.ConfigKeyMetricsAddr,
+		options.DefaultMetricsAddr,
+		"address to serve Prometheus metrics on, e.g. :9090 (disabled if empty)",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.NoSave,
+		options.ConfigKeyNoSave,
+		options.DefaultNoSave,
+		"never save the state (the since date and Jira OAuth tokens); keep it in memory only",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.StateFile,
+		options.ConfigKeyStateFile,
+		options.DefaultStateFile,
+		"path of the file the state is saved to (default .issue-sync-state.json next to the config file)",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.JiraAPIVersion,
+		options.ConfigKeyJiraAPIVersion,
+		options.DefaultJiraAPIVersion,
+		"version of the Jira REST API of the requests built by issue-sync: 2 or 3 (Jira Cloud)",
+	)
+
+	RootCmd.PersistentFlags().DurationVar(
+		&opts.RetryInitialInterval,
+		options.ConfigKeyRetryInitialInterval,
+		options.DefaultRetryInitialInterval,
+		"wait before the first retry of a failed Jira request",
+	)
+
+	RootCmd.PersistentFlags().DurationVar(
+		&opts.RetryMaxInterval,
+		options.ConfigKeyRetryMaxInterval,
+		options.DefaultRetryMaxInterval,
+		"longest wait between two retries of a failed Jira request",
+	)
+
+	RootCmd.PersistentFlags().Float64Var(
+		&opts.RetryMultiplier,
+		options.ConfigKeyRetryMultiplier,
+		options.DefaultRetryMultiplier,
+		"factor the wait between retries of a failed Jira request grows by",
+	)
+
+	RootCmd.PersistentFlags().StringVar(
+		&opts.JiraReporter,
+		options.ConfigKeyJiraReporter,
+		options.DefaultJiraReporter,
+		"account ID of the Jira user set as the reporter of created Jira issues (default the Jira user of the tool)",
+	)
+
+	RootCmd.PersistentFlags().BoolVar(
+		&opts.LinkDuplicates,
+		options.ConfigKeyLinkDuplicates,
+		options.DefaultLinkDuplicates,
+		"link Jira issues of GitHub issues closed as duplicates to the Jira issue of the canonical GitHub issue",
+	)
+
+	RootCmd.Flags().BoolVar(
+		&opts.CheckAuth,
+		options.ConfigKeyCheckAuth,
+		false,
+		"only check that the Jira credentials authenticate, without synchronizing any issue",
+	)
+
+	RootCmd.SetGlobalNormalizationFunc(normalizeFlagName)
+
+	RootCmd.AddCommand(exportCmd)
+	RootCmd.AddCommand(doctorCmd)
 	RootCmd.AddCommand(version.Version())
 }
 
+// normalizeFlagName accepts `--repo` as an alias of `--repo-name`, which
+// reads better when several repositories are synchronized, and
+// `--prune-deleted` as an alias of `--prune-issues`.
+func normalizeFlagName(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+	switch name {
+	case "repo":
+		name = options.ConfigKeyRepoName
+	case options.ConfigKeyPruneDeleted:
+		name = options.ConfigKeyPruneIssues
+	}
+	return pflag.NormalizedName(name)
+}
+
 func initLogging(*cobra.Command, []string) error {
 	err := log.SetupGlobalLogger(opts.LogLevel)
 	if err != nil {
 		return fmt.Errorf("setting up global logger: %w", err)
 	}
+
+	color, err := useColor(opts.Color, logrus.StandardLogger().Out)
+	if err != nil {
+		return err
+	}
+	formatter, err := logFormatter(opts.LogFormat, color)
+	if err != nil {
+		return err
+	}
+	logrus.SetFormatter(formatter)
+
 	return nil
 }
+
+// logFormatter returns the logrus formatter of the log format. Colors only
+// apply to text; JSON entries carry their timestamp, for ingestion.
+func logFormatter(format string, color bool) (logrus.Formatter, error) {
+	switch format {
+	case options.LogFormatText:
+		return &logrus.TextFormatter{
+			DisableTimestamp: true,
+			ForceColors:      color,
+			DisableColors:    !color,
+		}, nil
+	case options.LogFormatJSON:
+		return &logrus.JSONFormatter{}, nil
+	default:
+		return nil, fmt.Errorf( //nolint:goerr113
+			"`log-format` must be one of `%s` or `%s`; got %q",
+			options.LogFormatText, options.LogFormatJSON, format,
+		)
+	}
+}
+
+// useColor returns whether log output written to w should be colored in the
+// `color` mode. In the auto mode, it is colored if w is a terminal.
+func useColor(mode string, w io.Writer) (bool, error) {
+	switch mode {
+	case options.ColorAlways:
+		return true, nil
+	case options.ColorNever:
+		return false, nil
+	case options.ColorAuto:
+		f, ok := w.(*os.File)
+		return ok && term.IsTerminal(int(f.Fd())), nil
+	default:
+		return false, fmt.Errorf( //nolint:goerr113
+			"`color` must be one of `%s`, `%s` or `%s`; got %q",
+			options.ColorAuto, options.ColorAlways, options.ColorNever, mode,
+		)
+	}
+}
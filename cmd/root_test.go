@@ -0,0 +1,305 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/clock"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+func TestUseColor(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("creating pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	tests := []struct {
+		name     string
+		mode     string
+		writer   io.Writer
+		expected bool
+	}{
+		{name: "auto on a pipe", mode: options.ColorAuto, writer: w, expected: false},
+		{name: "auto on a buffer", mode: options.ColorAuto, writer: &bytes.Buffer{}, expected: false},
+		{name: "always on a pipe", mode: options.ColorAlways, writer: w, expected: true},
+		{name: "never", mode: options.ColorNever, writer: w, expected: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			color, err := useColor(tc.mode, tc.writer)
+			if err != nil {
+				t.Fatalf("useColor() returned error: %v", err)
+			}
+			if color != tc.expected {
+				t.Fatalf("Expected color %t; got %t", tc.expected, color)
+			}
+		})
+	}
+
+	if _, err := useColor("sometimes", w); err == nil {
+		t.Fatal("Expected an error for an unknown color mode")
+	}
+}
+
+func TestLogFormatter(t *testing.T) {
+	formatter, err := logFormatter(options.LogFormatJSON, true)
+	if err != nil {
+		t.Fatalf("logFormatter() returned error: %v", err)
+	}
+
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{"gh_issue": 1, "jira_key": "TEST-1"})
+	entry.Message = "Created Jira issue TEST-1!"
+	out, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Format() returned error: %v", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(out, &fields); err != nil {
+		t.Fatalf("Expected a JSON log entry; got %q: %v", out, err)
+	}
+	if fields["gh_issue"] != float64(1) || fields["jira_key"] != "TEST-1" || fields["msg"] != entry.Message {
+		t.Fatalf("Expected the fields of the entry; got %v", fields)
+	}
+
+	if _, err := logFormatter(options.LogFormatText, false); err != nil {
+		t.Fatalf("logFormatter() returned error: %v", err)
+	}
+	if _, err := logFormatter("xml", false); err == nil {
+		t.Fatal("Expected an error for an unknown log format")
+	}
+}
+
+func TestRunWaitsForStartupDelay(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyPeriod:       time.Duration(0),
+		options.ConfigKeyStartupDelay: 30 * time.Second,
+	})
+
+	start := time.Date(2023, time.July, 14, 9, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	cfg.SetClock(clk)
+
+	var compared []time.Time
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			compared = append(compared, clk.Now())
+			return nil, nil
+		},
+	}
+
+	if err := run(cfg, ghClient, &jira.JiraClientMock{}); err != nil {
+		t.Fatalf("run() returned error: %v", err)
+	}
+
+	if len(compared) != 1 {
+		t.Fatalf("Expected a single synchronization; got %d", len(compared))
+	}
+	if expected := start.Add(30 * time.Second); !compared[0].Equal(expected) {
+		t.Fatalf("Expected the first synchronization at %v; got %v", expected, compared[0])
+	}
+}
+
+func TestRunStopsOnShutdownDuringStartupDelay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := configtest.NewConfig(ctx, map[string]interface{}{
+		options.ConfigKeyPeriod:       time.Duration(0),
+		options.ConfigKeyStartupDelay: time.Hour,
+	})
+
+	synchronizations := 0
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			synchronizations++
+			return nil, nil
+		},
+	}
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if err := run(cfg, ghClient, &jira.JiraClientMock{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected run() to return %v; got %v", context.Canceled, err)
+	}
+	if synchronizations != 0 {
+		t.Fatalf("Expected no synchronization after shutting down; got %d", synchronizations)
+	}
+}
+
+func TestRunStopsDaemonOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := configtest.NewConfig(ctx, map[string]interface{}{
+		options.ConfigKeyPeriod: time.Hour,
+	})
+
+	synchronizations := 0
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			synchronizations++
+			// Shut down during the first synchronization.
+			cancel()
+			return nil, nil
+		},
+	}
+
+	if err := run(cfg, ghClient, &jira.JiraClientMock{}); err != nil {
+		t.Fatalf("run() returned error: %v", err)
+	}
+	if synchronizations != 1 {
+		t.Fatalf("Expected the daemon to stop after the first synchronization; got %d", synchronizations)
+	}
+}
+
+func TestRunReturnsSynchronizationErrors(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyPeriod: time.Duration(0),
+	})
+
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			return []*gogh.Issue{
+				{ID: gogh.Int64(1001), Number: gogh.Int(1), User: &gogh.User{Login: gogh.String("octocat")}},
+				{ID: gogh.Int64(1002), Number: gogh.Int(2), User: &gogh.User{Login: gogh.String("octocat")}},
+			}, nil
+		},
+	}
+	jiraClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			if issue.Fields.Unknowns[cfg.GetFieldKey(config.GitHubNumber)] == 2 {
+				return nil, errors.New("jira is down")
+			}
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+	}
+
+	err := run(cfg, ghClient, jiraClient)
+	if err == nil || !strings.Contains(err.Error(), "jira is down") {
+		t.Fatalf("Expected run() to return the error of #2; got %v", err)
+	}
+}
+
+func TestReconcileBoundsRequestsByMaxRunDuration(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyMaxRunDuration: time.Hour,
+	})
+
+	var deadline time.Time
+	var bounded bool
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			deadline, bounded = cfg.RequestContext().Deadline()
+			return nil, nil
+		},
+	}
+
+	if err := reconcile(cfg, ghClient, &jira.JiraClientMock{}); err != nil {
+		t.Fatalf("reconcile() returned error: %v", err)
+	}
+
+	if !bounded || time.Until(deadline) > time.Hour {
+		t.Fatalf("Expected the requests of the run to be bounded by the maximum run duration; got deadline %v", deadline)
+	}
+	if _, ok := cfg.RequestContext().Deadline(); ok {
+		t.Fatal("Expected the requests after the run not to be bounded by it anymore")
+	}
+}
+
+func TestReconcileLogsRunID(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyLogRunID: true,
+	})
+	cfg.SetClock(clock.NewFake(time.Date(2023, time.July, 14, 9, 0, 0, 0, time.UTC)))
+
+	// The run ID hook must fire before the test hook records the entries.
+	logrus.AddHook(cfg.RunIDHook())
+	hook := logtest.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			return []*gogh.Issue{
+				{ID: gogh.Int64(1001), Number: gogh.Int(1), User: &gogh.User{Login: gogh.String("octocat")}},
+			}, nil
+		},
+	}
+	jiraClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+	}
+
+	var runIDs []string
+	for run := 1; run <= 2; run++ {
+		hook.Reset()
+		if err := reconcile(cfg, ghClient, jiraClient); err != nil {
+			t.Fatalf("reconcile() returned error: %v", err)
+		}
+
+		entries := hook.AllEntries()
+		if len(entries) == 0 {
+			t.Fatal("Expected the synchronization to log entries")
+		}
+		runID := entries[0].Data["run_id"]
+		for _, entry := range entries {
+			if entry.Data["run_id"] != runID {
+				t.Fatalf("Expected every entry to carry run ID %v; got %v on %q", runID, entry.Data["run_id"], entry.Message)
+			}
+		}
+		runIDs = append(runIDs, fmt.Sprint(runID))
+	}
+
+	if expected := []string{"20230714T090000Z-1", "20230714T090000Z-2"}; !reflect.DeepEqual(runIDs, expected) {
+		t.Fatalf("Expected run IDs %v; got %v", expected, runIDs)
+	}
+}
+
+func TestFlagAliases(t *testing.T) {
+	for alias, name := range map[string]string{
+		"repo":                        options.ConfigKeyRepoName,
+		options.ConfigKeyPruneDeleted: options.ConfigKeyPruneIssues,
+	} {
+		flag := RootCmd.PersistentFlags().Lookup(alias)
+		if flag == nil || flag.Name != name {
+			t.Fatalf("Expected --%s to be an alias of --%s; got %v", alias, name, flag)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clock abstracts the passage of time, so that code which waits can
+// be tested without sleeping.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time and waits for durations to elapse.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current
+	// time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is the Clock of the time package.
+type Real struct{}
+
+// Now returns time.Now().
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// After returns time.After(d).
+func (Real) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Fake is a Clock for tests, whose time only advances when it is waited on.
+// Waiting on it returns immediately, after advancing its time by the
+// duration waited.
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake returns a Fake clock set to now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the current time of the fake clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// After advances the fake clock by d and returns a channel on which the new
+// time is immediately available.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if d > 0 {
+		f.now = f.now.Add(d)
+	}
+
+	c := make(chan time.Time, 1)
+	c <- f.now
+	return c
+}
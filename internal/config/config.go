@@ -18,16 +18,21 @@ package config
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"syscall"
+	"text/template"
+	"text/template/parse"
 	"time"
+	"unicode"
 
 	"github.com/dghubble/oauth1"
 	"github.com/fsnotify/fsnotify"
@@ -37,28 +42,65 @@ import (
 	jira "github.com/uwu-tools/go-jira/v2/cloud"
 	"golang.org/x/term"
 
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/clock"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/encoding"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
 )
 
+// Log fields correlating the log entries of a synchronization.
+const (
+	// runIDField is the ID of the current synchronization.
+	runIDField = "run_id"
+	// repoField is the GitHub repository, in the form `owner/repo`.
+	repoField = "repo"
+	// ghIssueField is the number of the GitHub issue.
+	ghIssueField = "gh_issue"
+	// jiraKeyField is the key of the Jira issue.
+	jiraKeyField = "jira_key"
+)
+
 // fieldKey is an enum-like type to represent the customfield ID keys.
 type fieldKey int
 
 const (
-	GitHubID       fieldKey = iota
-	GitHubNumber   fieldKey = iota
-	GitHubLabels   fieldKey = iota
-	GitHubStatus   fieldKey = iota
-	GitHubReporter fieldKey = iota
-	GitHubLastSync fieldKey = iota
+	GitHubID                fieldKey = iota
+	GitHubNumber            fieldKey = iota
+	GitHubLabels            fieldKey = iota
+	GitHubStatus            fieldKey = iota
+	GitHubReporter          fieldKey = iota
+	GitHubLastSync          fieldKey = iota
+	GitHubAssignee          fieldKey = iota
+	GitHubComments          fieldKey = iota
+	GitHubUpdated           fieldKey = iota
+	SyncVersion             fieldKey = iota
+	GitHubLabelColors       fieldKey = iota
+	GitHubAuthorAssociation fieldKey = iota
+	GitHubCreated           fieldKey = iota
+	GitHubClosed            fieldKey = iota
+	Sprint                  fieldKey = iota
+	GitHubRepository        fieldKey = iota
 
 	// Custom field names.
-	CustomFieldNameGitHubID       = "github-id"
-	CustomFieldNameGitHubNumber   = "github-number"
-	CustomFieldNameGitHubLabels   = "github-labels"
-	CustomFieldNameGitHubStatus   = "github-status"
-	CustomFieldNameGitHubReporter = "github-reporter"
-	CustomFieldNameGitHubLastSync = "github-last-sync"
+	CustomFieldNameGitHubID                = "github-id"
+	CustomFieldNameGitHubNumber            = "github-number"
+	CustomFieldNameGitHubLabels            = "github-labels"
+	CustomFieldNameGitHubStatus            = "github-status"
+	CustomFieldNameGitHubReporter          = "github-reporter"
+	CustomFieldNameGitHubLastSync          = "github-last-sync"
+	CustomFieldNameGitHubAssignee          = "github-assignee"
+	CustomFieldNameGitHubComments          = "github-comment-count"
+	CustomFieldNameGitHubUpdated           = "github-updated-at"
+	CustomFieldNameSyncVersion             = "sync-version"
+	CustomFieldNameGitHubLabelColors       = "github-label-colors"
+	CustomFieldNameGitHubAuthorAssociation = "github-author-association"
+	CustomFieldNameGitHubCreated           = "github-created-at"
+	CustomFieldNameGitHubClosed            = "github-closed-at"
+	CustomFieldNameGitHubRepository        = "github-repository"
+
+	// sprintFieldSchema is the schema of the Sprint field of Jira Software,
+	// which is matched by schema rather than by its localized name.
+	sprintFieldSchema = "com.pyxis.greenhopper.jira:gh-sprint"
 )
 
 // fields represents the custom field IDs of the Jira custom fields we care about.
@@ -69,13 +111,32 @@ type fields struct {
 	githubReporter string
 	githubStatus   string
 	lastUpdate     string
+
+	// githubAssignee, githubComments, githubUpdated, syncVersion,
+	// githubLabelColors, githubAuthorAssociation, githubCreated,
+	// githubClosed and githubRepository are optional; they are empty if the
+	// custom field does not exist.
+	githubAssignee          string
+	githubComments          string
+	githubUpdated           string
+	syncVersion             string
+	githubLabelColors       string
+	githubAuthorAssociation string
+	githubCreated           string
+	githubClosed            string
+	githubRepository        string
+
+	// sprint is the Sprint field of Jira Software, which is not created for
+	// issue-sync; it is empty if Jira Software is not installed.
+	sprint string
 }
 
 // Config is the root configuration object the application creates.
 //
 //nolint:govet
 type Config struct {
-	// cmdFile is the file Viper is using for its configuration.
+	// cmdFile is the file Viper is using for its configuration. If several
+	// files were merged, it is the last one.
 	cmdFile string
 
 	// cmdConfig is the Viper configuration object created from the command line and config file.
@@ -85,12 +146,23 @@ type Config struct {
 	// API boundaries.
 	ctx context.Context
 
+	// runCtx, if not nil, is the context of the current synchronization,
+	// bounded by its maximum duration.
+	runCtx context.Context
+
 	// basicAuth represents whether we're using HTTP Basic authentication or OAuth.
 	basicAuth bool
 
+	// bearerAuth represents whether we're using a personal access token,
+	// which takes precedence over both HTTP Basic authentication and OAuth.
+	bearerAuth bool
+
 	// fieldIDs is the list of custom fields we pulled from the `fields` Jira endpoint.
 	fieldIDs *fields
 
+	// fieldIDsResolvedAt is when fieldIDs were last pulled from Jira.
+	fieldIDsResolvedAt time.Time
+
 	// project represents the Jira project the user has requested.
 	project *jira.Project
 
@@ -100,8 +172,58 @@ type Config struct {
 	components []*jira.Component
 
 	// since is the parsed value of the `since` configuration parameter, which is the earliest that
-	// a GitHub issue can have been updated to be retrieved.
+	// a GitHub issue can have been updated to be retrieved. If `since` is a map of dates keyed by
+	// repository, it is the date of repositories missing from the map.
 	since time.Time
+
+	// repoSince is the `since` date of each repository, keyed by the
+	// lowercase owner/repo path, as set by SetSince or parsed from a map
+	// `since` configuration parameter. Repositories without a date use since.
+	repoSince map[string]time.Time
+
+	// sinceOverride is whether `since` was set with the `--since` flag,
+	// which applies to this process only. SaveState then saves savedSince,
+	// the `since` value of the state or configuration files, instead.
+	sinceOverride bool
+	savedSince    interface{}
+
+	// repo is the owner and name of the repository being synchronized, as
+	// selected by SetRepo.
+	repo [2]string
+
+	// runs is the number of synchronizations started by this process.
+	runs int
+
+	// runID is the ID of the current synchronization, set by StartRun. It is
+	// read by the hook returned by RunIDHook, which may fire from any
+	// goroutine.
+	runID atomic.Value
+
+	// clock is the clock used to wait; it is the real clock if nil.
+	clock clock.Clock
+
+	// labelTypeRules is the parsed value of the `label-type-map`
+	// configuration parameter, in priority order.
+	labelTypeRules []LabelTypeRule
+
+	// commentTemplate is the parsed value of the `comment-template`
+	// configuration parameter, or nil if it is not set.
+	commentTemplate *template.Template
+
+	// reporterFormat is the parsed value of the `reporter-format`
+	// configuration parameter, or nil if it is not set.
+	reporterFormat *template.Template
+
+	// fieldTransforms is the parsed value of the `field-transforms`
+	// configuration parameter, keyed by field name.
+	fieldTransforms map[string]*template.Template
+}
+
+// LabelTypeRule maps a GitHub label to the type of the Jira issues created
+// for GitHub issues with that label.
+type LabelTypeRule struct {
+	Label string `json:"label" mapstructure:"label"`
+	Type  string `json:"type" mapstructure:"type"`
 }
 
 // New creates a new, immutable configuration object. This object
@@ -110,12 +232,12 @@ type Config struct {
 func New(ctx context.Context, cmd *cobra.Command) (*Config, error) {
 	var cfg Config
 
-	cfgFilePath, err := cmd.Flags().GetString(options.ConfigKeyConfigFile)
+	cfgFilePaths, err := cmd.Flags().GetStringSlice(options.ConfigKeyConfigFile)
 	if err != nil {
 		return nil, fmt.Errorf("getting config file: %w", err)
 	}
 
-	if cfgFilePath == "" {
+	if len(cfgFilePaths) == 0 {
 		log.Debug("config file path was not set, falling back to default")
 
 		cfgFileDir, err := os.Getwd()
@@ -123,21 +245,32 @@ func New(ctx context.Context, cmd *cobra.Command) (*Config, error) {
 			return nil, fmt.Errorf("getting working directory: %w", err)
 		}
 
-		cfgFilePath = filepath.Join(cfgFileDir, options.DefaultConfigFileName)
+		cfgFilePaths = []string{filepath.Join(cfgFileDir, options.DefaultConfigFileName)}
 	}
 
-	_, err = os.Stat(cfgFilePath)
-	if err != nil {
-		return nil, fmt.Errorf(
-			"checking if config file (%s) exists: %w",
-			cfgFilePath,
-			err,
-		)
+	for _, cfgFilePath := range cfgFilePaths {
+		_, err = os.Stat(cfgFilePath)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"checking if config file (%s) exists: %w",
+				cfgFilePath,
+				err,
+			)
+		}
 	}
 
-	log.Debugf("using config file: %s", cfgFilePath)
-	cfg.cmdFile = cfgFilePath
-	cfg.cmdConfig = *newViper(options.AppName, cfg.cmdFile)
+	log.Debugf("using config files: %v", cfgFilePaths)
+	cfg.cmdConfig = *newViper(options.AppName, cfgFilePaths)
+
+	// The flag takes precedence once bound, so the saved value is read
+	// first.
+	if cmd.Flags().Changed(options.ConfigKeySince) {
+		cfg.sinceOverride = true
+		cfg.savedSince = cfg.cmdConfig.Get(options.ConfigKeySince)
+		if cfg.savedSince == nil {
+			cfg.savedSince = options.DefaultSince
+		}
+	}
 	cfg.cmdConfig.BindPFlags(cmd.Flags()) //nolint:errcheck
 
 	cfg.cmdFile = cfg.cmdConfig.ConfigFileUsed()
@@ -151,14 +284,86 @@ func New(ctx context.Context, cmd *cobra.Command) (*Config, error) {
 	return &cfg, nil
 }
 
+// NewWithJiraConfig creates a configuration object from the values of v
+// and from the Jira project and fields already retrieved, without reading a
+// configuration file or contacting Jira. The custom field IDs are resolved
+// from jiraFields as by LoadJiraConfig, but the values of v are parsed
+// without being validated.
+func NewWithJiraConfig(
+	ctx context.Context,
+	v *viper.Viper,
+	project *jira.Project,
+	jiraFields []jira.Field,
+) (*Config, error) {
+	cfg := Config{
+		cmdConfig: *v,
+		ctx:       ctx,
+		project:   project,
+	}
+
+	cfg.bearerAuth = strings.TrimSpace(v.GetString(options.ConfigKeyJiraBearerToken)) != ""
+	cfg.basicAuth = !cfg.bearerAuth &&
+		(v.GetString(options.ConfigKeyJiraUser) != "") &&
+		(v.GetString(options.ConfigKeyJiraPassword) != "")
+
+	var err error
+	cfg.since, cfg.repoSince, err = parseSince(v)
+	if err != nil {
+		return nil, err
+	}
+	cfg.labelTypeRules, err = parseLabelTypeMap(v)
+	if err != nil {
+		return nil, err
+	}
+	cfg.commentTemplate, err = parseCommentTemplate(v)
+	if err != nil {
+		return nil, err
+	}
+	cfg.reporterFormat, err = parseReporterFormat(v)
+	if err != nil {
+		return nil, err
+	}
+	cfg.fieldTransforms, err = parseFieldTransforms(v)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.fieldIDs, err = parseFieldIDs(jiraFields, cfg.GetOptionalFields())
+	if err != nil {
+		return nil, err
+	}
+	cfg.fieldIDsResolvedAt = cfg.Clock().Now()
+
+	return &cfg, nil
+}
+
 // LoadJiraConfig loads the Jira configuration (project key,
 // custom field IDs) from a remote Jira server.
 func (c *Config) LoadJiraConfig(client *jira.Client) error {
-	proj, res, err := client.Project.Get(
-		c.Context(),
-		c.cmdConfig.GetString(options.ConfigKeyJiraProject),
-	)
+	key := c.cmdConfig.GetString(options.ConfigKeyJiraProject)
+	proj, res, err := client.Project.Get(c.Context(), key)
 	if err != nil {
+		if res == nil {
+			return fmt.Errorf("retrieving Jira project %s: %w", key, err)
+		}
+
+		// Jira also answers 404 for projects the user may not browse.
+		switch res.StatusCode {
+		case http.StatusNotFound:
+			return fmt.Errorf(
+				"%w: %s; check the `%s` key, and that the Jira user may browse the project",
+				ErrJiraProjectNotFound,
+				key,
+				options.ConfigKeyJiraProject,
+			)
+		case http.StatusUnauthorized:
+			return fmt.Errorf(
+				"%w while retrieving Jira project %s; check the Jira credentials",
+				ErrJiraAuthenticationFailed,
+				key,
+			)
+		}
+
 		log.Errorf("error retrieving Jira project; check key and credentials. Error: %s", err)
 		defer res.Body.Close()
 		body, err := io.ReadAll(res.Body)
@@ -181,15 +386,81 @@ func (c *Config) LoadJiraConfig(client *jira.Client) error {
 	if err != nil {
 		return err
 	}
+	c.fieldIDsResolvedAt = c.Clock().Now()
+	if c.ShouldSyncMilestonesAsSprints() && !c.HasField(Sprint) {
+		return errSprintFieldNotFound
+	}
+	if c.ShouldSyncLabelColors() && !c.HasField(GitHubLabelColors) {
+		log.Warnf(
+			"%s is set, but the custom field %s does not exist; label colors will not be synchronized",
+			options.ConfigKeySyncLabelColors,
+			CustomFieldNameGitHubLabelColors,
+		)
+	}
 
 	return nil
 }
 
+// RefreshFieldIDs pulls the IDs of the custom fields from Jira again if the
+// `field-refresh-interval` elapsed since they were last pulled, so that a
+// daemon follows custom fields recreated in Jira, and logs the IDs which
+// changed. It returns whether the IDs were pulled again. On error, the
+// previous IDs are kept.
+func (c *Config) RefreshFieldIDs(client *jira.Client) (bool, error) {
+	interval := c.GetFieldRefreshInterval()
+	if interval <= 0 || c.Clock().Now().Sub(c.fieldIDsResolvedAt) < interval {
+		return false, nil
+	}
+
+	fieldIDs, err := c.getFieldIDs(client)
+	if err != nil {
+		return false, err
+	}
+
+	previous := c.fieldIDs
+	c.fieldIDs = fieldIDs
+	c.fieldIDsResolvedAt = c.Clock().Now()
+
+	for _, field := range customFields {
+		oldID, newID := previous.id(field.key), fieldIDs.id(field.key)
+		if oldID == newID {
+			continue
+		}
+		switch {
+		case oldID == "":
+			log.Infof("Custom field %s was created with ID %s", field.name, newID)
+		case newID == "":
+			log.Warnf("Custom field %s with ID %s no longer exists", field.name, oldID)
+		default:
+			log.Warnf("Custom field %s changed ID from %s to %s", field.name, oldID, newID)
+		}
+	}
+
+	return true, nil
+}
+
 // Context returns the context.
 func (c *Config) Context() context.Context {
 	return c.ctx
 }
 
+// SetRunContext sets the context of the current synchronization, derived
+// from Context. Setting it to nil once the synchronization is over restores
+// Context for the requests.
+func (c *Config) SetRunContext(ctx context.Context) {
+	c.runCtx = ctx
+}
+
+// RequestContext returns the context of the requests to the Jira and GitHub
+// APIs: the context of the current synchronization, if any, so that reaching
+// its maximum duration aborts the requests in flight, and Context otherwise.
+func (c *Config) RequestContext() context.Context {
+	if c.runCtx != nil {
+		return c.runCtx
+	}
+	return c.ctx
+}
+
 // GetConfigFile returns the file that Viper loaded the configuration from.
 func (c *Config) GetConfigFile() string {
 	return c.cmdFile
@@ -206,11 +477,69 @@ func (c *Config) IsBasicAuth() bool {
 	return c.basicAuth
 }
 
-// GetSinceParam returns the `since` configuration parameter, parsed as a time.Time.
+// IsBearerAuth is true if we're authenticating with a Jira personal access
+// token, sent as a Bearer token.
+func (c *Config) IsBearerAuth() bool {
+	return c.bearerAuth
+}
+
+// IsGitHubAppAuth is true if we're authenticating to GitHub as an
+// installation of a GitHub App, and false if we're using a token.
+func (c *Config) IsGitHubAppAuth() bool {
+	return c.GetGitHubAppID() != 0
+}
+
+// GetGitHubAppID returns the ID of the GitHub App to authenticate as, or 0 if
+// a token is used.
+func (c *Config) GetGitHubAppID() int64 {
+	return c.cmdConfig.GetInt64(options.ConfigKeyGitHubAppID)
+}
+
+// GetGitHubAppInstallationID returns the ID of the installation of the GitHub
+// App to authenticate as.
+func (c *Config) GetGitHubAppInstallationID() int64 {
+	return c.cmdConfig.GetInt64(options.ConfigKeyGitHubAppInstallationID)
+}
+
+// GetSinceParam returns the `since` configuration parameter, parsed as a time.Time,
+// of the repository selected by SetRepo.
 func (c *Config) GetSinceParam() time.Time {
+	return c.sinceOf(c.repoPath())
+}
+
+// SetSince sets the effective `since` date of the synchronization of a
+// repository, identified by its owner/repo path, which SaveState records in
+// the state file. Each repository keeps its own date, so that a
+// repository which failed to synchronize doesn't hold back the others.
+func (c *Config) SetSince(repo string, since time.Time) {
+	if c.repoSince == nil {
+		c.repoSince = map[string]time.Time{}
+	}
+	c.repoSince[strings.ToLower(repo)] = since
+}
+
+// sinceOf returns the `since` date of the repository with the owner/repo
+// path. Paths are compared ignoring case, as GitHub does.
+func (c *Config) sinceOf(repo string) time.Time {
+	if since, ok := c.repoSince[strings.ToLower(repo)]; ok {
+		return since
+	}
 	return c.since
 }
 
+// ShouldDeriveSinceFromJira returns whether the `since` date should be
+// derived from the latest `github-last-sync` value stored in Jira, instead of
+// the configuration. A `--since` flag takes precedence.
+func (c *Config) ShouldDeriveSinceFromJira() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeySinceFromLastJiraSync) && !c.sinceOverride
+}
+
+// IsSinceOverridden returns whether `since` was set with the `--since`
+// flag, for this process only, so that it is not saved.
+func (c *Config) IsSinceOverridden() bool {
+	return c.sinceOverride
+}
+
 // IsDryRun returns whether the application is running in confirmed mode or not.
 func (c *Config) IsDryRun() bool {
 	return !c.cmdConfig.GetBool(options.ConfigKeyConfirm)
@@ -231,26 +560,619 @@ func (c *Config) GetTimeout() time.Duration {
 	return c.cmdConfig.GetDuration(options.ConfigKeyTimeout)
 }
 
+// ShouldLinkDuplicates returns whether Jira issues of GitHub issues closed as
+// duplicates should be linked to the Jira issue of the canonical issue.
+func (c *Config) ShouldLinkDuplicates() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyLinkDuplicates)
+}
+
+// GetMaxRunDuration returns the maximum duration of a single synchronization
+// pass. Zero means a pass may run indefinitely.
+func (c *Config) GetMaxRunDuration() time.Duration {
+	return c.cmdConfig.GetDuration(options.ConfigKeyMaxRunDuration)
+}
+
+// GetMaxRetryAfter returns the longest wait honored when an API response
+// requests a retry via its Retry-After header. Zero ignores the header.
+func (c *Config) GetMaxRetryAfter() time.Duration {
+	return c.cmdConfig.GetDuration(options.ConfigKeyMaxRetryAfter)
+}
+
+// GetRetryInitialInterval returns the wait before the first retry of a
+// failed request.
+func (c *Config) GetRetryInitialInterval() time.Duration {
+	if interval := c.cmdConfig.GetDuration(options.ConfigKeyRetryInitialInterval); interval != 0 {
+		return interval
+	}
+	return options.DefaultRetryInitialInterval
+}
+
+// GetRetryMaxInterval returns the longest wait between two retries of a
+// failed request.
+func (c *Config) GetRetryMaxInterval() time.Duration {
+	if interval := c.cmdConfig.GetDuration(options.ConfigKeyRetryMaxInterval); interval != 0 {
+		return interval
+	}
+	return options.DefaultRetryMaxInterval
+}
+
+// GetRetryMultiplier returns the factor the wait between retries of a failed
+// request grows by.
+func (c *Config) GetRetryMultiplier() float64 {
+	if multiplier := c.cmdConfig.GetFloat64(options.ConfigKeyRetryMultiplier); multiplier != 0 {
+		return multiplier
+	}
+	return options.DefaultRetryMultiplier
+}
+
+// GetMatchStrategy returns the strategy used to match GitHub issues to Jira
+// issues; it is one of options.MatchStrategyJiraField or
+// options.MatchStrategyGitHubMarker.
+func (c *Config) GetMatchStrategy() string {
+	if strategy := c.cmdConfig.GetString(options.ConfigKeyMatchStrategy); strategy != "" {
+		return strategy
+	}
+	return options.DefaultMatchStrategy
+}
+
+// GetMaxDescriptionLength returns the maximum length, in characters, of
+// the description of a Jira issue. Zero means no limit.
+func (c *Config) GetMaxDescriptionLength() int {
+	return c.cmdConfig.GetInt(options.ConfigKeyMaxDescriptionLength)
+}
+
+// GetSyncedLabel returns the Jira label set on every issue managed by the
+// tool, or an empty string if no label should be set.
+func (c *Config) GetSyncedLabel() string {
+	return strings.TrimSpace(c.cmdConfig.GetString(options.ConfigKeySyncedLabel))
+}
+
+// GetLabelPrefix returns the prefix of the GitHub labels written to the
+// `github-labels` field, e.g. "gh/", or an empty string for no prefix.
+func (c *Config) GetLabelPrefix() string {
+	return c.cmdConfig.GetString(options.ConfigKeyLabelPrefix)
+}
+
+// GetLabelSpaceReplacement returns the string which replaces spaces in
+// GitHub labels, as Jira labels can't contain spaces. It may be empty, in
+// which case spaces are removed.
+func (c *Config) GetLabelSpaceReplacement() string {
+	if !c.cmdConfig.IsSet(options.ConfigKeyLabelSpaceReplacement) {
+		return options.DefaultLabelSpaceReplacement
+	}
+	return c.cmdConfig.GetString(options.ConfigKeyLabelSpaceReplacement)
+}
+
+// GetSyncMode returns which of creating and updating Jira issues is allowed;
+// it is one of options.SyncModeCreateOnly, options.SyncModeUpdateOnly or
+// options.SyncModeBoth.
+func (c *Config) GetSyncMode() string {
+	if mode := c.cmdConfig.GetString(options.ConfigKeySyncMode); mode != "" {
+		return mode
+	}
+	return options.DefaultSyncMode
+}
+
+// ShouldReleaseClosedMilestones returns whether the Jira version named after
+// a closed GitHub milestone should be released.
+func (c *Config) ShouldReleaseClosedMilestones() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyReleaseClosedMilestones)
+}
+
+// GetFailureWebhookURL returns the URL a summary of synchronizations with
+// failures is posted to, or an empty string if none is configured.
+func (c *Config) GetFailureWebhookURL() string {
+	return strings.TrimSpace(c.cmdConfig.GetString(options.ConfigKeyFailureWebhookURL))
+}
+
+// GetEnvironmentHeading returns the heading of the GitHub issue body section
+// mirrored to the Jira `Environment` field, or an empty string if the field
+// should not be populated.
+func (c *Config) GetEnvironmentHeading() string {
+	return strings.TrimSpace(c.cmdConfig.GetString(options.ConfigKeyEnvironmentHeading))
+}
+
+// ShouldCommentAssigneeChanges returns whether a Jira comment should be
+// posted when the assignee of a GitHub issue changes.
+func (c *Config) ShouldCommentAssigneeChanges() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyAssigneeChangeComments)
+}
+
+// ShouldRelinkBySummary returns whether GitHub issues without a Jira issue
+// should be matched to unlinked Jira issues by their summary before a new
+// Jira issue is created.
+func (c *Config) ShouldRelinkBySummary() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyRelinkBySummary)
+}
+
+// GetIssueTypeForLabels returns the type of the Jira issue created for a
+// GitHub issue with the given labels. If several rules of `label-type-map`
+// match, the first one wins; if none does, the default issue type is used.
+func (c *Config) GetIssueTypeForLabels(labels []string) string {
+	for _, rule := range c.labelTypeRules {
+		for _, label := range labels {
+			if label == rule.Label {
+				return rule.Type
+			}
+		}
+	}
+	return options.DefaultIssueType
+}
+
+// ShouldRespectJiraUpdates returns whether Jira issues edited since they were
+// last synchronized should be kept, unless the GitHub issue changed since.
+func (c *Config) ShouldRespectJiraUpdates() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyRespectJiraUpdates)
+}
+
+// GetSyncUpdateWindow returns how long after a Jira issue was last
+// synchronized an update is still attributed to the synchronization itself,
+// rather than to a human edit.
+func (c *Config) GetSyncUpdateWindow() time.Duration {
+	return c.cmdConfig.GetDuration(options.ConfigKeySyncUpdateWindow)
+}
+
+// ShouldStripCommentQuotes returns whether quote blocks should be removed
+// from GitHub comments before they are synchronized to Jira.
+func (c *Config) ShouldStripCommentQuotes() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyCommentStripQuotes)
+}
+
+// GetCommentSeparator returns the line after which the content of GitHub
+// comments is not synchronized to Jira, e.g. a signature separator. An empty
+// separator keeps the whole comment.
+func (c *Config) GetCommentSeparator() string {
+	return c.cmdConfig.GetString(options.ConfigKeyCommentSeparator)
+}
+
+// GetStatusTransitionMap returns the name of the Jira workflow transition
+// to perform when a GitHub issue enters a state, keyed by GitHub state, e.g.
+// "closed" to "Done".
+func (c *Config) GetStatusTransitionMap() map[string]string {
+	return c.cmdConfig.GetStringMapString(options.ConfigKeyStatusTransitionMap)
+}
+
+// GetCreateRate returns the maximum number of Jira issues created per
+// second. Zero means no limit.
+func (c *Config) GetCreateRate() float64 {
+	return c.cmdConfig.GetFloat64(options.ConfigKeyCreateRate)
+}
+
+// ShouldSyncTimeline returns whether key GitHub timeline events should be
+// posted as Jira comments.
+func (c *Config) ShouldSyncTimeline() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeySyncTimeline)
+}
+
+// ShouldConvertMarkdown returns whether the Markdown of GitHub comments
+// should be converted to Jira wiki markup.
+func (c *Config) ShouldConvertMarkdown() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyConvertMarkdown)
+}
+
+// ShouldEscapeMarkup returns whether the Jira wiki markup special characters
+// of GitHub comments should be escaped, so that they are displayed as is.
+func (c *Config) ShouldEscapeMarkup() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyEscapeMarkup)
+}
+
+// GetJiraReporter returns the account ID of the Jira user set as the
+// reporter of created Jira issues, or an empty string to leave the reporter
+// to Jira, which is the acting user.
+func (c *Config) GetJiraReporter() string {
+	return strings.TrimSpace(c.cmdConfig.GetString(options.ConfigKeyJiraReporter))
+}
+
+// GetDefaultReporter returns the reporter recorded for GitHub issues without
+// a user, e.g. issues of deleted accounts.
+func (c *Config) GetDefaultReporter() string {
+	return c.cmdConfig.GetString(options.ConfigKeyDefaultReporter)
+}
+
+// GetIncludeLabels returns the GitHub labels of which an issue must have at
+// least one to be synchronized. If empty, all issues are synchronized.
+func (c *Config) GetIncludeLabels() []string {
+	return c.cmdConfig.GetStringSlice(options.ConfigKeyIncludeLabels)
+}
+
+// GetOptionalFields returns the names of the custom fields, otherwise
+// required, which may not exist in Jira, in lower case.
+func (c *Config) GetOptionalFields() []string {
+	names := c.cmdConfig.GetStringSlice(options.ConfigKeyOptionalFields)
+
+	optional := make([]string, len(names))
+	for i, name := range names {
+		optional[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	return optional
+}
+
+// GetExcludeLabels returns the GitHub labels of issues which are never
+// synchronized.
+func (c *Config) GetExcludeLabels() []string {
+	return c.cmdConfig.GetStringSlice(options.ConfigKeyExcludeLabels)
+}
+
+// IsExcludedJiraStatus returns whether Jira issues in the status are left
+// alone, as listed in `exclude-jira-status`. Statuses are compared ignoring
+// case.
+func (c *Config) IsExcludedJiraStatus(status string) bool {
+	for _, excluded := range c.cmdConfig.GetStringSlice(options.ConfigKeyExcludeJiraStatus) {
+		if strings.EqualFold(strings.TrimSpace(excluded), status) {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldSyncExcludedStatusComments returns whether GitHub comments are still
+// copied to Jira issues in a status listed in `exclude-jira-status`.
+func (c *Config) ShouldSyncExcludedStatusComments() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyExcludedStatusComments)
+}
+
+// ShouldPruneIssues returns whether Jira issues whose GitHub issue was
+// deleted should be closed or labeled. `prune-deleted` is an alias of
+// `prune-issues`.
+func (c *Config) ShouldPruneIssues() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyPruneIssues) ||
+		c.cmdConfig.GetBool(options.ConfigKeyPruneDeleted)
+}
+
+// GetPruneTransition returns the name of the Jira transition performed on
+// pruned issues. If empty, pruned issues are not transitioned.
+func (c *Config) GetPruneTransition() string {
+	return c.cmdConfig.GetString(options.ConfigKeyPruneTransition)
+}
+
+// GetPruneLabel returns the label added to pruned Jira issues. If empty,
+// pruned issues are not labeled.
+func (c *Config) GetPruneLabel() string {
+	return c.cmdConfig.GetString(options.ConfigKeyPruneLabel)
+}
+
+// ShouldSyncMilestones returns whether the fix version of Jira issues is set
+// to the version named after the milestone of their GitHub issue.
+func (c *Config) ShouldSyncMilestones() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeySyncMilestones)
+}
+
+// ShouldSyncMilestonesAsSprints returns whether the Sprint field of Jira
+// issues is set to the sprint of the `jira-board-id` board named after the
+// milestone of their GitHub issue.
+func (c *Config) ShouldSyncMilestonesAsSprints() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyMilestoneAsSprint)
+}
+
+// GetJiraBoardID returns the ID of the Jira board whose sprints GitHub
+// milestones are mapped to.
+func (c *Config) GetJiraBoardID() int64 {
+	return c.cmdConfig.GetInt64(options.ConfigKeyJiraBoardID)
+}
+
+// ShouldExplain returns whether the decision taken for each GitHub issue is
+// logged, along with its reason.
+func (c *Config) ShouldExplain() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyExplain)
+}
+
+// GetJiraAPIVersion returns the version of the Jira REST API of the requests
+// built by issue-sync, rather than by the Jira client library.
+func (c *Config) GetJiraAPIVersion() string {
+	if version := c.cmdConfig.GetString(options.ConfigKeyJiraAPIVersion); version != "" {
+		return version
+	}
+	return options.DefaultJiraAPIVersion
+}
+
+// JiraAPIPath returns the path of the Jira REST API endpoint, relative to
+// the Jira URI, for the configured API version, e.g. `rest/api/2/field`.
+func (c *Config) JiraAPIPath(endpoint string) string {
+	return "rest/api/" + c.GetJiraAPIVersion() + "/" + endpoint
+}
+
+// GetMetricsAddr returns the address to serve Prometheus metrics on, or an
+// empty string if they are not served.
+func (c *Config) GetMetricsAddr() string {
+	return c.cmdConfig.GetString(options.ConfigKeyMetricsAddr)
+}
+
+// GetStartupDelay returns how long to wait before the first synchronization.
+func (c *Config) GetStartupDelay() time.Duration {
+	return c.cmdConfig.GetDuration(options.ConfigKeyStartupDelay)
+}
+
+// GetSyncIssueState returns the state of the GitHub issues to synchronize; it
+// is one of options.IssueStateOpen, options.IssueStateClosed or
+// options.IssueStateAll.
+func (c *Config) GetSyncIssueState() string {
+	if state := c.cmdConfig.GetString(options.ConfigKeySyncIssueState); state != "" {
+		return state
+	}
+	return options.DefaultSyncIssueState
+}
+
+// GetCommentTemplate returns the template rendering the header of the Jira
+// comments copied from GitHub, or nil if the default header is used.
+func (c *Config) GetCommentTemplate() *template.Template {
+	return c.commentTemplate
+}
+
+// ReporterFields holds the fields of the author of a GitHub issue available
+// to the `reporter-format`.
+type ReporterFields struct {
+	Login string
+	Name  string
+	URL   string
+}
+
+// GetReporterFormat returns the template rendering the `github-reporter`
+// field, or nil if the login is written as is.
+func (c *Config) GetReporterFormat() *template.Template {
+	return c.reporterFormat
+}
+
+// ReporterFormatUsesName returns whether the `reporter-format` renders the
+// name of the author, which GitHub only returns along with the full user.
+func (c *Config) ReporterFormatUsesName() bool {
+	return c.reporterFormat != nil && usesField(c.reporterFormat.Tree.Root, "Name")
+}
+
+// TransformField returns the value written to a Jira field, after applying
+// the `field-transforms` template of the field, if any. If the template
+// fails, the value is returned unchanged.
+func (c *Config) TransformField(field, value string) string {
+	tmpl, ok := c.fieldTransforms[field]
+	if !ok {
+		return value
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, value); err != nil {
+		log.Warnf("Error applying the %s transform to %q, keeping it as is: %v", field, value, err)
+		return value
+	}
+
+	return b.String()
+}
+
+// ShouldLinkClosingPullRequests returns whether Jira issues should get a
+// remote link to the pull request which closed their GitHub issue.
+func (c *Config) ShouldLinkClosingPullRequests() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyLinkClosingPullRequests)
+}
+
+// ShouldLogRateLimitWaits returns whether the waits for the Retry-After
+// interval of rate limited API responses should be logged at info level,
+// rather than debug.
+func (c *Config) ShouldLogRateLimitWaits() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyLogRateLimitWaits)
+}
+
+// GetDueDateLabelPrefix returns the prefix of the GitHub labels holding the
+// due date of their issue, e.g. "due:" for `due:2024-12-01`, or an empty
+// string if the due date should not be set.
+func (c *Config) GetDueDateLabelPrefix() string {
+	return strings.TrimSpace(c.cmdConfig.GetString(options.ConfigKeyDueDateLabelPrefix))
+}
+
+// GetRateLimitBuffer returns the number of remaining GitHub API requests
+// below which the GitHub client waits for the rate limit to reset; zero
+// never waits.
+func (c *Config) GetRateLimitBuffer() int {
+	return c.cmdConfig.GetInt(options.ConfigKeyRateLimitBuffer)
+}
+
+// ShouldSyncLabelColors returns whether the colors of the labels of GitHub
+// issues should be written to the `github-label-colors` custom field.
+func (c *Config) ShouldSyncLabelColors() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeySyncLabelColors)
+}
+
+// ShouldLogRunID returns whether every log entry should carry the ID of the
+// synchronization it was logged in.
+func (c *Config) ShouldLogRunID() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyLogRunID)
+}
+
+// GetFieldRefreshInterval returns how often a daemon pulls the IDs of the
+// custom fields from Jira again, or 0 if they are only pulled at startup.
+func (c *Config) GetFieldRefreshInterval() time.Duration {
+	return c.cmdConfig.GetDuration(options.ConfigKeyFieldRefreshInterval)
+}
+
+// GetMaxLabels returns the maximum number of labels of a GitHub issue
+// written to its Jira issue, or 0 if they are not limited.
+func (c *Config) GetMaxLabels() int {
+	return c.cmdConfig.GetInt(options.ConfigKeyMaxLabels)
+}
+
+// GetLabelOrder returns the order of the labels kept when a GitHub issue has
+// more than `max-labels`; it is one of options.LabelOrderGitHub or
+// options.LabelOrderName.
+func (c *Config) GetLabelOrder() string {
+	if order := c.cmdConfig.GetString(options.ConfigKeyLabelOrder); order != "" {
+		return order
+	}
+	return options.DefaultLabelOrder
+}
+
+// ShouldCheckAuth returns whether the application should only check that
+// the Jira credentials authenticate, rather than synchronize issues.
+func (c *Config) ShouldCheckAuth() bool {
+	return c.cmdConfig.GetBool(options.ConfigKeyCheckAuth)
+}
+
+// Clock returns the clock used to wait between API calls.
+func (c *Config) Clock() clock.Clock {
+	if c.clock == nil {
+		return clock.Real{}
+	}
+	return c.clock
+}
+
+// SetClock replaces the clock used to wait between API calls, e.g. with a
+// fake clock in tests.
+func (c *Config) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+// StartRun records the start of a synchronization. It must be called once
+// before each synchronization, so that IsFullReconcile follows the
+// configured cadence.
+func (c *Config) StartRun() {
+	c.runs++
+	c.runID.Store(fmt.Sprintf("%s-%d", c.Clock().Now().UTC().Format("20060102T150405Z"), c.runs))
+}
+
+// GetRunID returns the ID of the current synchronization: the time it
+// started, followed by its number in this process. It is empty before the
+// first synchronization.
+func (c *Config) GetRunID() string {
+	id, _ := c.runID.Load().(string) //nolint:errcheck // empty before the first run
+	return id
+}
+
+// RunIDHook returns a logrus hook adding the ID of the current
+// synchronization to every log entry, as the `run_id` field, so that the log
+// entries of a synchronization can be correlated.
+func (c *Config) RunIDHook() log.Hook {
+	return runIDHook{cfg: c}
+}
+
+// runIDHook is the hook returned by RunIDHook.
+type runIDHook struct {
+	cfg *Config
+}
+
+// Levels returns every log level.
+func (runIDHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire adds the ID of the current synchronization to the log entry.
+func (h runIDHook) Fire(entry *log.Entry) error {
+	if id := h.cfg.GetRunID(); id != "" {
+		entry.Data[runIDField] = id
+	}
+	return nil
+}
+
+// IssueLogger returns a log entry with the fields identifying the GitHub
+// issue of the current repository and, unless the key is empty, its Jira
+// issue.
+func (c *Config) IssueLogger(number int, jiraKey string) *log.Entry {
+	owner, repo := c.GetRepo()
+	fields := log.Fields{
+		repoField:    owner + "/" + repo,
+		ghIssueField: number,
+	}
+	if jiraKey != "" {
+		fields[jiraKeyField] = jiraKey
+	}
+	return log.WithFields(fields)
+}
+
+// IsFullReconcile returns whether the current synchronization is a full
+// reconcile, which ignores the `since` date and comment counts reported by
+// GitHub when comparing comments. With a `full-reconcile-every` value of N,
+// every Nth run started by this process is a full reconcile.
+func (c *Config) IsFullReconcile() bool {
+	every := c.cmdConfig.GetInt(options.ConfigKeyFullReconcileEvery)
+	return every > 0 && c.runs > 0 && c.runs%every == 0
+}
+
 // GetFieldID returns the customfield ID of a Jira custom field.
 func (c *Config) GetFieldID(key fieldKey) string {
+	return c.fieldIDs.id(key)
+}
+
+// id returns the customfield ID of a Jira custom field, or an empty string
+// if it does not exist.
+func (f *fields) id(key fieldKey) string {
 	switch key {
 	case GitHubID:
-		return c.fieldIDs.githubID
+		return f.githubID
 	case GitHubNumber:
-		return c.fieldIDs.githubNumber
+		return f.githubNumber
 	case GitHubLabels:
-		return c.fieldIDs.githubLabels
+		return f.githubLabels
 	case GitHubReporter:
-		return c.fieldIDs.githubReporter
+		return f.githubReporter
 	case GitHubStatus:
-		return c.fieldIDs.githubStatus
+		return f.githubStatus
 	case GitHubLastSync:
-		return c.fieldIDs.lastUpdate
+		return f.lastUpdate
+	case GitHubAssignee:
+		return f.githubAssignee
+	case GitHubComments:
+		return f.githubComments
+	case GitHubUpdated:
+		return f.githubUpdated
+	case SyncVersion:
+		return f.syncVersion
+	case GitHubLabelColors:
+		return f.githubLabelColors
+	case GitHubAuthorAssociation:
+		return f.githubAuthorAssociation
+	case GitHubCreated:
+		return f.githubCreated
+	case GitHubClosed:
+		return f.githubClosed
+	case GitHubRepository:
+		return f.githubRepository
+	case Sprint:
+		return f.sprint
 	default:
 		return ""
 	}
 }
 
+// HasField returns whether the custom field exists in Jira. Only optional
+// custom fields may not exist.
+func (c *Config) HasField(key fieldKey) bool {
+	return c.GetFieldID(key) != ""
+}
+
+// MissingOptionalFields returns the names of the optional custom fields which
+// do not exist in Jira, including those listed in `optional-fields`.
+func (c *Config) MissingOptionalFields() []string {
+	var missing []string
+	for _, optional := range customFields {
+		if optional.key == GitHubID {
+			continue
+		}
+		if !c.HasField(optional.key) {
+			missing = append(missing, optional.name)
+		}
+	}
+
+	return missing
+}
+
+// customFields are the custom fields used by issue-sync, with their names.
+var customFields = []struct {
+	key  fieldKey
+	name string
+}{
+	{GitHubID, CustomFieldNameGitHubID},
+	{GitHubNumber, CustomFieldNameGitHubNumber},
+	{GitHubLabels, CustomFieldNameGitHubLabels},
+	{GitHubStatus, CustomFieldNameGitHubStatus},
+	{GitHubReporter, CustomFieldNameGitHubReporter},
+	{GitHubLastSync, CustomFieldNameGitHubLastSync},
+	{GitHubAssignee, CustomFieldNameGitHubAssignee},
+	{GitHubComments, CustomFieldNameGitHubComments},
+	{GitHubUpdated, CustomFieldNameGitHubUpdated},
+	{SyncVersion, CustomFieldNameSyncVersion},
+	{GitHubLabelColors, CustomFieldNameGitHubLabelColors},
+	{GitHubAuthorAssociation, CustomFieldNameGitHubAuthorAssociation},
+	{GitHubCreated, CustomFieldNameGitHubCreated},
+	{GitHubClosed, CustomFieldNameGitHubClosed},
+	{GitHubRepository, CustomFieldNameGitHubRepository},
+}
+
 // GetFieldKey returns customfield_XXXXX, where XXXXX is the custom field ID (see GetFieldID).
 func (c *Config) GetFieldKey(key fieldKey) string {
 	return fmt.Sprintf("customfield_%s", c.GetFieldID(key))
@@ -266,11 +1188,57 @@ func (c *Config) GetProjectKey() string {
 	return c.project.Key
 }
 
-// GetRepo returns the user/org name and the repo name of the configured GitHub repository.
+// GetRepo returns the user/org name and the repo name of the GitHub
+// repository being synchronized: the one selected by SetRepo, or else the
+// first configured repository.
 func (c *Config) GetRepo() (string, string) {
-	repoPath := c.cmdConfig.GetString(options.ConfigKeyRepoName)
-	// We check that repo-name is two parts separated by a slash in New, so this is safe
-	return github.GetRepo(repoPath)
+	if c.repo[0] != "" {
+		return c.repo[0], c.repo[1]
+	}
+
+	repos := c.GetRepos()
+	if len(repos) == 0 {
+		return "", ""
+	}
+	return repos[0][0], repos[0][1]
+}
+
+// GetRepos returns the user/org name and the repo name of every configured
+// GitHub repository, in the order of the comma-separated `repo-name`.
+func (c *Config) GetRepos() [][2]string {
+	var repos [][2]string
+	for _, repoPath := range splitRepoNames(c.cmdConfig.GetString(options.ConfigKeyRepoName)) {
+		// We check that each repo is two parts separated by a slash in New, so this is safe
+		owner, repo := github.GetRepo(repoPath)
+		repos = append(repos, [2]string{owner, repo})
+	}
+
+	return repos
+}
+
+// SetRepo selects the GitHub repository to synchronize, which GetRepo,
+// GetSinceParam and SetSince then refer to.
+func (c *Config) SetRepo(owner, repo string) {
+	c.repo = [2]string{owner, repo}
+}
+
+// repoPath returns the owner/repo path of the repository being
+// synchronized, which keys `repo-since`.
+func (c *Config) repoPath() string {
+	owner, repo := c.GetRepo()
+	return owner + "/" + repo
+}
+
+// splitRepoNames splits the comma-separated value of `repo-name`.
+func splitRepoNames(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
 }
 
 // GetJiraComponents returns the Jira component the user has configured.
@@ -279,60 +1247,44 @@ func (c *Config) GetJiraComponents() []*jira.Component {
 }
 
 // SetJiraToken adds the Jira OAuth tokens in the Viper configuration, ensuring that they
-// are saved for future runs.
+// are saved to the state file for future runs.
 func (c *Config) SetJiraToken(token *oauth1.Token) {
 	c.cmdConfig.Set(options.ConfigKeyJiraToken, token.Token)
 	c.cmdConfig.Set(options.ConfigKeyJiraSecret, token.TokenSecret)
 }
 
-// configFile is a serializable representation of the current Viper configuration.
-type configFile struct {
-	LogLevel       string        `json:"log-level,omitempty" mapstructure:"log-level"`
-	GithubToken    string        `json:"github-token,omitempty" mapstructure:"github-token"`
-	JiraUser       string        `json:"jira-user,omitempty" mapstructure:"jira-user"`
-	JiraPass       string        `json:"jira-pass,omitempty" mapstructure:"jira-pass"`
-	JiraToken      string        `json:"jira-token,omitempty" mapstructure:"jira-token"`
-	JiraSecret     string        `json:"jira-secret,omitempty" mapstructure:"jira-secret"`
-	JiraKey        string        `json:"jira-private-key-path,omitempty" mapstructure:"jira-private-key-path"`
-	JiraCKey       string        `json:"jira-consumer-key,omitempty" mapstructure:"jira-consumer-key"`
-	RepoName       string        `json:"repo-name,omitempty" mapstructure:"repo-name"`
-	JiraURI        string        `json:"jira-uri,omitempty" mapstructure:"jira-uri"`
-	JiraProject    string        `json:"jira-project,omitempty" mapstructure:"jira-project"`
-	Since          string        `json:"since,omitempty" mapstructure:"since"`
-	JiraComponents []string      `json:"jira-components,omitempty" mapstructure:"jira-components"`
-	Confirm        bool          `json:"confirm,omitempty" mapstructure:"confirm"`
-	Timeout        time.Duration `json:"timeout,omitempty" mapstructure:"timeout"`
-}
-
-// SaveConfig updates the `since` parameter to now, then saves the configuration file.
-func (c *Config) SaveConfig() error {
-	c.cmdConfig.Set(
-		options.ConfigKeySince,
-		time.Now().Format(options.DateFormat),
-	)
-
-	var cf configFile
-	if err := c.cmdConfig.Unmarshal(&cf); err != nil {
-		return fmt.Errorf("unmarshalling config: %w", err)
+// sinceToSave returns the `since` value to save: the date of each
+// repository keyed by owner/repo if several repositories are synchronized,
+// the current `since` date otherwise, or the saved value if `since` was
+// overridden with the `--since` flag.
+func (c *Config) sinceToSave() interface{} {
+	if c.sinceOverride {
+		return c.savedSince
 	}
 
-	b, err := json.MarshalIndent(cf, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshalling config: %w", err)
+	repos := c.GetRepos()
+	if len(repos) > 1 {
+		since := make(map[string]string, len(repos))
+		for _, repo := range repos {
+			path := repo[0] + "/" + repo[1]
+			since[path] = c.sinceOf(path).Format(options.DateFormat)
+		}
+		return since
 	}
 
-	f, err := os.OpenFile(c.cmdConfig.ConfigFileUsed(), os.O_RDWR|os.O_TRUNC|os.O_CREATE, 0o644)
-	if err != nil {
-		return fmt.Errorf("opening config file %s: %w", c.cmdConfig.ConfigFileUsed(), err)
-	}
-	defer f.Close()
+	return c.GetSinceParam().Format(options.DateFormat)
+}
 
-	_, err = f.WriteString(string(b))
-	if err != nil {
-		return fmt.Errorf("writing config: %w", err)
+// configType returns the Viper configuration type of the configuration file
+// at path, from its extension. Files with any other extension, or none, are
+// JSON.
+func configType(path string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	if _, err := encoding.ByExtension(ext); err != nil {
+		return "json"
 	}
 
-	return nil
+	return ext
 }
 
 // newViper generates a viper configuration object which
@@ -340,7 +1292,11 @@ func (c *Config) SaveConfig() error {
 // command line options, configuration file options, and
 // default configuration values. This viper object becomes
 // the single source of truth for the app configuration.
-func newViper(appName, cfgFile string) *viper.Viper {
+//
+// If several configuration files are provided, they are merged in order,
+// with values of later files overriding those of earlier ones. The default
+// state file is next to the last file.
+func newViper(appName string, cfgFiles []string) *viper.Viper {
 	logger := log.New()
 	v := viper.New()
 
@@ -350,19 +1306,31 @@ func newViper(appName, cfgFile string) *viper.Viper {
 
 	v.SetConfigName(fmt.Sprintf("config-%s", appName))
 	v.AddConfigPath(".")
-	if cfgFile != "" {
-		v.SetConfigFile(cfgFile)
-	}
 	v.SetConfigType("json")
 
-	if err := v.ReadInConfig(); err == nil {
+	if len(cfgFiles) == 0 {
+		if err := v.ReadInConfig(); err == nil {
+			log.WithField("file", v.ConfigFileUsed()).Infof("config file loaded")
+		}
+	}
+
+	for _, cfgFile := range cfgFiles {
+		v.SetConfigType(configType(cfgFile))
+		v.SetConfigFile(cfgFile)
+		if err := v.MergeInConfig(); err != nil {
+			log.WithError(err).Warningf("Error reading config file: %v", cfgFile)
+			continue
+		}
 		log.WithField("file", v.ConfigFileUsed()).Infof("config file loaded")
+	}
+
+	// Viper reloads only the changed file, which would drop the values of
+	// the other files, so merged configurations are not watched.
+	if v.ConfigFileUsed() != "" && len(cfgFiles) <= 1 {
 		v.WatchConfig()
 		v.OnConfigChange(func(e fsnotify.Event) {
 			log.WithField("file", e.Name).Info("config file changed")
 		})
-	} else if cfgFile != "" {
-		log.WithError(err).Warningf("Error reading config file: %v", cfgFile)
 	}
 
 	if logger.Level == log.DebugLevel {
@@ -381,15 +1349,39 @@ func (c *Config) validateConfig() error {
 	// Log level and config file location are validated already
 
 	log.Debug("Checking config variables...")
+	// Checking the Jira credentials needs neither GitHub nor a project.
+	checkAuth := c.ShouldCheckAuth()
+
 	token := c.cmdConfig.GetString(options.ConfigKeyGitHubToken)
-	if token == "" {
+	if token != "" && c.IsGitHubAppAuth() {
+		return errGitHubAuthConflict
+	}
+	if c.IsGitHubAppAuth() {
+		log.Debug("Using GitHub App authentication")
+
+		if c.GetGitHubAppInstallationID() == 0 {
+			return errGitHubInstallationIDRequired
+		}
+
+		privateKey := c.cmdConfig.GetString(options.ConfigKeyGitHubAppPrivateKeyPath)
+		if privateKey == "" {
+			return errGitHubAppPrivateKeyRequired
+		}
+		if _, err := os.Stat(privateKey); err != nil {
+			return errGitHubAppPEMFileInvalid
+		}
+	} else if token == "" && !checkAuth {
 		return errGitHubTokenRequired
 	}
 
-	c.basicAuth = (c.cmdConfig.GetString(options.ConfigKeyJiraUser) != "") &&
+	c.bearerAuth = strings.TrimSpace(c.cmdConfig.GetString(options.ConfigKeyJiraBearerToken)) != ""
+	c.basicAuth = !c.bearerAuth &&
+		(c.cmdConfig.GetString(options.ConfigKeyJiraUser) != "") &&
 		(c.cmdConfig.GetString(options.ConfigKeyJiraPassword) != "")
 
-	if c.basicAuth { //nolint:nestif // TODO(lint)
+	if c.bearerAuth {
+		log.Debug("Using personal access token authentication")
+	} else if c.basicAuth { //nolint:nestif // TODO(lint)
 		log.Debug("Using HTTP Basic Authentication")
 
 		jUser := c.cmdConfig.GetString(options.ConfigKeyJiraUser)
@@ -436,12 +1428,14 @@ func (c *Config) validateConfig() error {
 		}
 	}
 
-	repo := c.cmdConfig.GetString(options.ConfigKeyRepoName)
-	if repo == "" {
+	repos := splitRepoNames(c.cmdConfig.GetString(options.ConfigKeyRepoName))
+	if len(repos) == 0 && !checkAuth {
 		return errGitHubRepoRequired
 	}
-	if !strings.Contains(repo, "/") || len(strings.Split(repo, "/")) != 2 {
-		return errGitHubRepoFormatInvalid
+	for _, repo := range repos {
+		if !strings.Contains(repo, "/") || len(strings.Split(repo, "/")) != 2 {
+			return errGitHubRepoFormatInvalid
+		}
 	}
 
 	uri := c.cmdConfig.GetString(options.ConfigKeyJiraURI)
@@ -453,31 +1447,323 @@ func (c *Config) validateConfig() error {
 	}
 
 	project := c.cmdConfig.GetString(options.ConfigKeyJiraProject)
-	if project == "" {
+	if project == "" && !checkAuth {
 		return errJiraProjectRequired
 	}
 
-	sinceStr := c.cmdConfig.GetString(options.ConfigKeySince)
-	if sinceStr == "" {
-		c.cmdConfig.Set(options.ConfigKeySince, options.DefaultSince)
+	if err := c.loadState(); err != nil {
+		return err
 	}
 
-	since, err := time.Parse(options.DateFormat, sinceStr)
+	since, repoSince, err := parseSince(&c.cmdConfig)
 	if err != nil {
-		return errDateInvalid
+		return err
 	}
 	c.since = since
+	c.repoSince = repoSince
+
+	switch c.GetMatchStrategy() {
+	case options.MatchStrategyJiraField, options.MatchStrategyGitHubMarker:
+	default:
+		return errMatchStrategyInvalid
+	}
+
+	switch c.GetSyncMode() {
+	case options.SyncModeCreateOnly, options.SyncModeUpdateOnly, options.SyncModeBoth:
+	default:
+		return errSyncModeInvalid
+	}
+
+	switch c.GetSyncIssueState() {
+	case options.IssueStateOpen, options.IssueStateClosed, options.IssueStateAll:
+	default:
+		return errSyncIssueStateInvalid
+	}
+
+	if webhook := c.GetFailureWebhookURL(); webhook != "" {
+		if _, err := url.ParseRequestURI(webhook); err != nil {
+			return errFailureWebhookURLInvalid
+		}
+	}
+
+	rules, err := parseLabelTypeMap(&c.cmdConfig)
+	if err != nil {
+		return err
+	}
+	c.labelTypeRules = rules
+
+	tmpl, err := parseCommentTemplate(&c.cmdConfig)
+	if err != nil {
+		return err
+	}
+	c.commentTemplate = tmpl
+
+	format, err := parseReporterFormat(&c.cmdConfig)
+	if err != nil {
+		return err
+	}
+	c.reporterFormat = format
+
+	transforms, err := parseFieldTransforms(&c.cmdConfig)
+	if err != nil {
+		return err
+	}
+	c.fieldTransforms = transforms
+
+	if c.GetMaxLabels() < 0 {
+		return errMaxLabelsInvalid
+	}
+
+	switch c.GetLabelOrder() {
+	case options.LabelOrderGitHub, options.LabelOrderName:
+	default:
+		return errLabelOrderInvalid
+	}
+
+	if strings.IndexFunc(c.GetLabelPrefix(), unicode.IsSpace) >= 0 {
+		return errLabelPrefixInvalid
+	}
+
+	if c.ShouldSyncMilestonesAsSprints() {
+		if c.ShouldSyncMilestones() {
+			return errMilestoneAsSprintConflict
+		}
+		if c.GetJiraBoardID() <= 0 {
+			return errJiraBoardIDRequired
+		}
+	}
+
+	switch c.GetJiraAPIVersion() {
+	case options.JiraAPIVersion2, options.JiraAPIVersion3:
+	default:
+		return errJiraAPIVersionInvalid
+	}
+
+	if c.ShouldConvertMarkdown() && c.ShouldEscapeMarkup() {
+		return errEscapeMarkupConflict
+	}
+
+	if c.GetRetryInitialInterval() < 0 || c.GetRetryMaxInterval() < c.GetRetryInitialInterval() {
+		return errRetryIntervalsInvalid
+	}
+	if c.GetRetryMultiplier() < 1 {
+		return errRetryMultiplierInvalid
+	}
+
+	for _, name := range c.GetOptionalFields() {
+		if !optionalizableFields[name] {
+			return fmt.Errorf("%w: got %q", errOptionalFieldsInvalid, name)
+		}
+	}
 
 	log.Debug("All config variables are valid!")
 
 	return nil
 }
 
+// parseLabelTypeMap returns the rules of the `label-type-map` configuration
+// parameter, in the order they are listed, which is their priority order.
+func parseLabelTypeMap(v *viper.Viper) ([]LabelTypeRule, error) {
+	var rules []LabelTypeRule
+	if err := v.UnmarshalKey(options.ConfigKeyLabelTypeMap, &rules); err != nil {
+		return nil, errLabelTypeMapInvalid
+	}
+
+	for _, rule := range rules {
+		if rule.Label == "" || rule.Type == "" {
+			return nil, errLabelTypeMapInvalid
+		}
+	}
+
+	return rules, nil
+}
+
+// parseCommentTemplate parses the `comment-template` configuration
+// parameter. It returns nil if the parameter is not set.
+func parseCommentTemplate(v *viper.Viper) (*template.Template, error) {
+	text := v.GetString(options.ConfigKeyCommentTemplate)
+	if text == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New(options.ConfigKeyCommentTemplate).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errCommentTemplateInvalid, err)
+	}
+
+	return tmpl, nil
+}
+
+// parseReporterFormat parses the `reporter-format` configuration parameter.
+// It returns nil if the parameter is not set. The template is rendered once
+// with empty fields, so that references to unknown fields are reported now
+// rather than on every issue.
+func parseReporterFormat(v *viper.Viper) (*template.Template, error) {
+	text := v.GetString(options.ConfigKeyReporterFormat)
+	if text == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New(options.ConfigKeyReporterFormat).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errReporterFormatInvalid, err)
+	}
+	if err := tmpl.Execute(io.Discard, ReporterFields{}); err != nil {
+		return nil, fmt.Errorf("%w: %w", errReporterFormatInvalid, err)
+	}
+
+	return tmpl, nil
+}
+
+// usesField returns whether the template node, or any node below it,
+// refers to the field of the data, e.g. `.Name`.
+func usesField(node parse.Node, field string) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if usesField(child, field) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return usesField(n.Pipe, field)
+	case *parse.IfNode:
+		return usesField(n.Pipe, field) || usesField(n.List, field) || usesField(n.ElseList, field)
+	case *parse.RangeNode:
+		return usesField(n.Pipe, field) || usesField(n.List, field) || usesField(n.ElseList, field)
+	case *parse.WithNode:
+		return usesField(n.Pipe, field) || usesField(n.List, field) || usesField(n.ElseList, field)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if usesField(cmd, field) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if usesField(arg, field) {
+				return true
+			}
+		}
+	case *parse.FieldNode:
+		return len(n.Ident) > 0 && n.Ident[0] == field
+	}
+
+	return false
+}
+
+// transformableFields are the fields which `field-transforms` may be set for.
+var transformableFields = map[string]bool{
+	"summary":                     true,
+	"description":                 true,
+	CustomFieldNameGitHubReporter: true,
+	CustomFieldNameGitHubLabels:   true,
+}
+
+// transformFuncs are the functions available to `field-transforms`, on top
+// of the predefined functions of text/template. None of them has side
+// effects, so transforms can't access anything but the value they are
+// applied to.
+var transformFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"replace": func(old, replacement, s string) string {
+		return strings.ReplaceAll(s, old, replacement)
+	},
+	"trimPrefix": func(prefix, s string) string {
+		return strings.TrimPrefix(s, prefix)
+	},
+	"trimSuffix": func(suffix, s string) string {
+		return strings.TrimSuffix(s, suffix)
+	},
+}
+
+// optionalizableFields are the required custom fields which may be listed in
+// `optional-fields`. The `github-id` field is always required, as Jira issues
+// are matched to GitHub issues by it.
+var optionalizableFields = map[string]bool{
+	CustomFieldNameGitHubNumber:   true,
+	CustomFieldNameGitHubLabels:   true,
+	CustomFieldNameGitHubStatus:   true,
+	CustomFieldNameGitHubReporter: true,
+	CustomFieldNameGitHubLastSync: true,
+}
+
+// parseFieldTransforms parses the `field-transforms` configuration
+// parameter, a map of field names to Go templates.
+func parseFieldTransforms(v *viper.Viper) (map[string]*template.Template, error) {
+	exprs := v.GetStringMapString(options.ConfigKeyFieldTransforms)
+
+	transforms := make(map[string]*template.Template, len(exprs))
+	for field, expr := range exprs {
+		if !transformableFields[field] {
+			return nil, fmt.Errorf("%w: unknown field %q", errFieldTransformsInvalid, field)
+		}
+
+		tmpl, err := template.New(field).Funcs(transformFuncs).Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errFieldTransformsInvalid, err)
+		}
+		transforms[field] = tmpl
+	}
+
+	return transforms, nil
+}
+
+// parseSince parses the `since` configuration parameter, which is either a
+// single date for every repository, or a map of dates keyed by owner/repo.
+// It returns the date of repositories without a date of their own, and the
+// date of each repository, keyed by the lowercase owner/repo path.
+func parseSince(v *viper.Viper) (time.Time, map[string]time.Time, error) {
+	repoSince := map[string]time.Time{}
+
+	dates, ok := v.Get(options.ConfigKeySince).(map[string]interface{})
+	if !ok {
+		sinceStr := v.GetString(options.ConfigKeySince)
+		if sinceStr == "" {
+			sinceStr = options.DefaultSince
+			v.Set(options.ConfigKeySince, sinceStr)
+		}
+
+		since, err := time.Parse(options.DateFormat, sinceStr)
+		if err != nil {
+			return time.Time{}, nil, errDateInvalid
+		}
+		return since, repoSince, nil
+	}
+
+	for repo, date := range dates {
+		sinceStr, ok := date.(string)
+		if !ok {
+			return time.Time{}, nil, errDateInvalid
+		}
+
+		since, err := time.Parse(options.DateFormat, sinceStr)
+		if err != nil {
+			return time.Time{}, nil, errDateInvalid
+		}
+		repoSince[strings.ToLower(repo)] = since
+	}
+
+	since, err := time.Parse(options.DateFormat, options.DefaultSince)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("parsing default since date: %w", err)
+	}
+	return since, repoSince, nil
+}
+
 // getFieldIDs requests the metadata of every issue field in the Jira
 // project, and saves the IDs of the custom fields used by issue-sync.
 func (c *Config) getFieldIDs(client *jira.Client) (*fields, error) {
 	log.Debug("Collecting field IDs.")
-	req, err := client.NewRequest(c.Context(), "GET", "/rest/api/2/field", nil)
+	req, err := client.NewRequest(c.Context(), "GET", c.JiraAPIPath("field"), nil)
 	if err != nil {
 		return nil, fmt.Errorf("getting fields: %w", err)
 	}
@@ -488,12 +1774,23 @@ func (c *Config) getFieldIDs(client *jira.Client) (*fields, error) {
 		return nil, fmt.Errorf("getting field IDs: %w", err)
 	}
 
-	jFields := *jFieldsPtr
+	return parseFieldIDs(*jFieldsPtr, c.GetOptionalFields())
+}
+
+// parseFieldIDs returns the IDs of the custom fields used by issue-sync from
+// the metadata of every issue field in the Jira project. Field names are
+// matched case-insensitively, ignoring surrounding whitespace. The required
+// custom fields listed in optional may not exist.
+func parseFieldIDs(jFields []jira.Field, optional []string) (*fields, error) {
 	var fieldIDs fields
 
 	for i := range jFields {
 		field := jFields[i]
-		switch field.Name {
+		if field.Schema.Custom == sprintFieldSchema {
+			fieldIDs.sprint = fmt.Sprint(field.Schema.CustomID)
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(field.Name)) {
 		case CustomFieldNameGitHubID:
 			fieldIDs.githubID = fmt.Sprint(field.Schema.CustomID)
 		case CustomFieldNameGitHubNumber:
@@ -506,26 +1803,97 @@ func (c *Config) getFieldIDs(client *jira.Client) (*fields, error) {
 			fieldIDs.githubReporter = fmt.Sprint(field.Schema.CustomID)
 		case CustomFieldNameGitHubLastSync:
 			fieldIDs.lastUpdate = fmt.Sprint(field.Schema.CustomID)
+		case CustomFieldNameGitHubAssignee:
+			fieldIDs.githubAssignee = fmt.Sprint(field.Schema.CustomID)
+		case CustomFieldNameGitHubComments:
+			fieldIDs.githubComments = fmt.Sprint(field.Schema.CustomID)
+		case CustomFieldNameGitHubUpdated:
+			fieldIDs.githubUpdated = fmt.Sprint(field.Schema.CustomID)
+		case CustomFieldNameSyncVersion:
+			fieldIDs.syncVersion = fmt.Sprint(field.Schema.CustomID)
+		case CustomFieldNameGitHubLabelColors:
+			fieldIDs.githubLabelColors = fmt.Sprint(field.Schema.CustomID)
+		case CustomFieldNameGitHubAuthorAssociation:
+			fieldIDs.githubAuthorAssociation = fmt.Sprint(field.Schema.CustomID)
+		case CustomFieldNameGitHubCreated:
+			fieldIDs.githubCreated = fmt.Sprint(field.Schema.CustomID)
+		case CustomFieldNameGitHubClosed:
+			fieldIDs.githubClosed = fmt.Sprint(field.Schema.CustomID)
+		case CustomFieldNameGitHubRepository:
+			fieldIDs.githubRepository = fmt.Sprint(field.Schema.CustomID)
 		}
 	}
 
-	if fieldIDs.githubID == "" {
-		return nil, errCustomFieldIDNotFound(CustomFieldNameGitHubID)
+	// Every missing required field is reported at once, so that they can
+	// all be fixed before the next attempt.
+	var missing []string
+	for _, required := range []struct {
+		name string
+		id   string
+	}{
+		{CustomFieldNameGitHubID, fieldIDs.githubID},
+		{CustomFieldNameGitHubNumber, fieldIDs.githubNumber},
+		{CustomFieldNameGitHubLabels, fieldIDs.githubLabels},
+		{CustomFieldNameGitHubStatus, fieldIDs.githubStatus},
+		{CustomFieldNameGitHubReporter, fieldIDs.githubReporter},
+		{CustomFieldNameGitHubLastSync, fieldIDs.lastUpdate},
+	} {
+		if required.id != "" {
+			continue
+		}
+		if slices.Contains(optional, required.name) {
+			log.Debugf("Optional custom field %s not found; it will not be synchronized", required.name)
+			continue
+		}
+		missing = append(missing, required.name)
+	}
+	if len(missing) > 0 {
+		return nil, errCustomFieldIDNotFound(missing...)
+	}
+	if fieldIDs.githubAssignee == "" {
+		log.Debugf(
+			"Optional custom field %s not found; assignees will not be synchronized",
+			CustomFieldNameGitHubAssignee,
+		)
+	}
+	if fieldIDs.githubComments == "" {
+		log.Debugf(
+			"Optional custom field %s not found; comment counts will not be synchronized",
+			CustomFieldNameGitHubComments,
+		)
+	}
+	if fieldIDs.githubUpdated == "" {
+		log.Debugf(
+			"Optional custom field %s not found; update times will not be synchronized",
+			CustomFieldNameGitHubUpdated,
+		)
 	}
-	if fieldIDs.githubNumber == "" {
-		return nil, errCustomFieldIDNotFound(CustomFieldNameGitHubNumber)
+	if fieldIDs.syncVersion == "" {
+		log.Debugf(
+			"Optional custom field %s not found; the version of this tool will not be recorded",
+			CustomFieldNameSyncVersion,
+		)
 	}
-	if fieldIDs.githubLabels == "" {
-		return nil, errCustomFieldIDNotFound(CustomFieldNameGitHubLabels)
+	if fieldIDs.githubAuthorAssociation == "" {
+		log.Debugf(
+			"Optional custom field %s not found; author associations will not be synchronized",
+			CustomFieldNameGitHubAuthorAssociation,
+		)
 	}
-	if fieldIDs.githubStatus == "" {
-		return nil, errCustomFieldIDNotFound(CustomFieldNameGitHubStatus)
+	if fieldIDs.githubCreated == "" {
+		log.Debugf(
+			"Optional custom field %s not found; creation times will not be synchronized",
+			CustomFieldNameGitHubCreated,
+		)
 	}
-	if fieldIDs.githubReporter == "" {
-		return nil, errCustomFieldIDNotFound(CustomFieldNameGitHubReporter)
+	if fieldIDs.githubClosed == "" {
+		log.Debugf(
+			"Optional custom field %s not found; closing times will not be synchronized",
+			CustomFieldNameGitHubClosed,
+		)
 	}
-	if fieldIDs.lastUpdate == "" {
-		return nil, errCustomFieldIDNotFound(CustomFieldNameGitHubLastSync)
+	if fieldIDs.githubRepository == "" {
+		log.Debugf("Optional custom field %s not found; issues will not be pruned", CustomFieldNameGitHubRepository)
 	}
 
 	log.Debug("All fields have been checked.")
@@ -569,7 +1937,11 @@ func (c *Config) getComponents(proj *jira.Project) ([]*jira.Component, error) {
 // Errors
 
 var (
-	errGitHubTokenRequired           = errors.New("github token required")
+	errGitHubTokenRequired           = errors.New("github token or github app ID required")
+	errGitHubAuthConflict            = errors.New("only one of github token and github app ID may be set")
+	errGitHubInstallationIDRequired  = errors.New("github app installation ID required")
+	errGitHubAppPrivateKeyRequired   = errors.New("github app private key required")
+	errGitHubAppPEMFileInvalid       = errors.New("github app private key must point to existing PEM file")
 	errJiraUsernameRequired          = errors.New("jira username required")
 	errJiraPasswordRequired          = errors.New("jira password required")
 	errJiraAccessTokenRequired       = errors.New("jira access token required")
@@ -582,11 +1954,57 @@ var (
 	errJiraURIRequired               = errors.New("jira URI required")
 	errJiraURIInvalid                = errors.New("jira URI must be valid URI")
 	errJiraProjectRequired           = errors.New("jira project required")
-	errDateInvalid                   = errors.New("`since` date must be in ISO-8601 format")
+	errDateInvalid                   = errors.New("`since` date must be in ISO-8601 format, or a map of such dates keyed by repository") //nolint:lll
+	errMatchStrategyInvalid          = errors.New("`match-strategy` must be one of `jira-field` or `github-marker`")
+	errSyncModeInvalid               = errors.New("`sync-mode` must be one of `create-only`, `update-only` or `both`")
+	errSyncIssueStateInvalid         = errors.New("`sync-issue-state` must be one of `open`, `closed` or `all`")
+	errFailureWebhookURLInvalid      = errors.New("`failure-webhook-url` must be valid URI")
+	errLabelTypeMapInvalid           = errors.New("`label-type-map` must be a list of objects with a `label` and a `type`") //nolint:lll
+	errCommentTemplateInvalid        = errors.New("`comment-template` must be a valid Go template")
+	errReporterFormatInvalid         = errors.New("`reporter-format` must be a valid Go template of the `.Login`, `.Name` and `.URL` fields")                                   //nolint:lll
+	errOptionalFieldsInvalid         = errors.New("`optional-fields` may only list `github-number`, `github-labels`, `github-status`, `github-reporter` or `github-last-sync`") //nolint:lll
+	errMaxLabelsInvalid              = errors.New("`max-labels` must not be negative")
+	errLabelOrderInvalid             = errors.New("`label-order` must be one of `github` or `name`")
+	errLabelPrefixInvalid            = errors.New("`label-prefix` must not contain whitespace, as Jira labels can't")
+	errMilestoneAsSprintConflict     = errors.New("only one of `sync-milestones` and `milestone-as-sprint` may be set")
+	errJiraBoardIDRequired           = errors.New("`milestone-as-sprint` requires the `jira-board-id` of the board of the sprints")          //nolint:lll
+	errSprintFieldNotFound           = errors.New("`milestone-as-sprint` is set, but Jira has no Sprint field; is Jira Software installed?") //nolint:lll
+	errJiraAPIVersionInvalid         = errors.New("`jira-api-version` must be one of `2` or `3`")
+	errEscapeMarkupConflict          = errors.New("only one of `convert-markdown` and `escape-markup` may be set")
+	errRetryIntervalsInvalid         = errors.New("`retry-initial-interval` must not be negative nor exceed `retry-max-interval`; 0 means the default") //nolint:lll
+	errRetryMultiplierInvalid        = errors.New("`retry-multiplier` must be at least 1; 0 means the default")
+	errFieldTransformsInvalid        = errors.New("`field-transforms` must map `summary`, `description`, `github-reporter` or `github-labels` to a valid Go template") //nolint:lll
+)
+
+var (
+	// ErrJiraProjectNotFound is returned by LoadJiraConfig if the configured
+	// Jira project does not exist, or the Jira user may not browse it.
+	ErrJiraProjectNotFound = errors.New("jira project key not found")
+
+	// ErrJiraAuthenticationFailed is returned by LoadJiraConfig if Jira
+	// rejects the configured credentials.
+	ErrJiraAuthenticationFailed = errors.New("jira authentication failed")
 )
 
-func errCustomFieldIDNotFound(field string) error {
-	return fmt.Errorf("could not find ID custom field '%s'; check that it is named correctly", field) //nolint:goerr113
+func errCustomFieldIDNotFound(fields ...string) error {
+	return &MissingFieldsError{Fields: fields}
+}
+
+// MissingFieldsError is returned by LoadJiraConfig if required custom fields
+// do not exist in Jira.
+type MissingFieldsError struct {
+	// Fields are the names of the missing custom fields.
+	Fields []string
+}
+
+func (e *MissingFieldsError) Error() string {
+	if len(e.Fields) == 1 {
+		return fmt.Sprintf("could not find ID custom field '%s'; check that it is named correctly", e.Fields[0])
+	}
+	return fmt.Sprintf(
+		"could not find ID custom fields '%s'; check that they are named correctly",
+		strings.Join(e.Fields, "', '"),
+	)
 }
 
 type ReadingJiraComponentError string
@@ -0,0 +1,922 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	jira "github.com/uwu-tools/go-jira/v2/cloud"
+	"gopkg.in/yaml.v3"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/clock"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+func TestNewMergesConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	base := filepath.Join(dir, "base.json")
+	writeFile(t, base, `{
+  "github-token": "token",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/test-repo",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "BASE"
+}`)
+
+	overlay := filepath.Join(dir, "overlay.json")
+	writeFile(t, overlay, `{
+  "jira-project": "OVERLAY",
+  "since": "2023-01-02T03:04:05+0000"
+}`)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSlice(options.ConfigKeyConfigFile, nil, "")
+	if err := cmd.Flags().Set(options.ConfigKeyConfigFile, base+","+overlay); err != nil {
+		t.Fatalf("setting config flag: %v", err)
+	}
+
+	cfg, err := New(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	if project := cfg.GetConfigString(options.ConfigKeyJiraProject); project != "OVERLAY" {
+		t.Fatalf("Expected overlay to override jira-project; got %q", project)
+	}
+	if repo := cfg.GetConfigString(options.ConfigKeyRepoName); repo != "test-owner/test-repo" {
+		t.Fatalf("Expected repo-name to be inherited from base; got %q", repo)
+	}
+	if since := cfg.GetSinceParam().UTC().Format(options.DateFormat); since != "2023-01-02T03:04:05+0000" {
+		t.Fatalf("Expected merged since date to be validated and parsed; got %q", since)
+	}
+	if cfg.GetConfigFile() != overlay {
+		t.Fatalf("Expected configuration to be saved to %s; got %s", overlay, cfg.GetConfigFile())
+	}
+}
+
+func TestNewValidatesMergedConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	base := filepath.Join(dir, "base.json")
+	writeFile(t, base, `{
+  "github-token": "token",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/test-repo",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "BASE"
+}`)
+
+	overlay := filepath.Join(dir, "overlay.json")
+	writeFile(t, overlay, `{"repo-name": "not-a-repo"}`)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSlice(options.ConfigKeyConfigFile, nil, "")
+	if err := cmd.Flags().Set(options.ConfigKeyConfigFile, base+","+overlay); err != nil {
+		t.Fatalf("setting config flag: %v", err)
+	}
+
+	if _, err := New(context.Background(), cmd); err != errGitHubRepoFormatInvalid { //nolint:errorlint
+		t.Fatalf("Expected error %v; got %v", errGitHubRepoFormatInvalid, err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+// testProjectKey and testFieldIDGitHubID are the Jira project key and the
+// ID of the github-id custom field of the configs created by newTestConfig.
+const (
+	testProjectKey      = "TEST"
+	testFieldIDGitHubID = "10001"
+)
+
+// newTestConfig creates a Config from the provided configuration values,
+// with the required custom fields, as configtest.NewConfig does outside of
+// this package.
+func newTestConfig(t *testing.T, values map[string]interface{}) *Config {
+	t.Helper()
+
+	v := viper.New()
+	v.Set(options.ConfigKeyRepoName, "test-owner/test-repo")
+	v.Set(options.ConfigKeySince, options.DefaultSince)
+	for key, value := range values {
+		v.Set(key, value)
+	}
+
+	cfg, err := NewWithJiraConfig(context.Background(), v, &jira.Project{Key: testProjectKey}, []jira.Field{
+		{Name: CustomFieldNameGitHubID, Schema: jira.FieldSchema{CustomID: 10001}},
+		{Name: CustomFieldNameGitHubNumber, Schema: jira.FieldSchema{CustomID: 10002}},
+		{Name: CustomFieldNameGitHubLabels, Schema: jira.FieldSchema{CustomID: 10003}},
+		{Name: CustomFieldNameGitHubStatus, Schema: jira.FieldSchema{CustomID: 10004}},
+		{Name: CustomFieldNameGitHubReporter, Schema: jira.FieldSchema{CustomID: 10005}},
+		{Name: CustomFieldNameGitHubLastSync, Schema: jira.FieldSchema{CustomID: 10006}},
+	})
+	if err != nil {
+		t.Fatalf("creating test config: %v", err)
+	}
+
+	return cfg
+}
+
+func TestIsFullReconcile(t *testing.T) {
+	cfg := newTestConfig(t, map[string]interface{}{
+		options.ConfigKeyFullReconcileEvery: 3,
+	})
+
+	var full []int
+	for run := 1; run <= 7; run++ {
+		cfg.StartRun()
+		if cfg.IsFullReconcile() {
+			full = append(full, run)
+		}
+	}
+
+	if len(full) != 2 || full[0] != 3 || full[1] != 6 {
+		t.Fatalf("Expected runs 3 and 6 to be full reconciles; got %v", full)
+	}
+}
+
+func TestIsFullReconcileDisabled(t *testing.T) {
+	cfg := newTestConfig(t, nil)
+
+	for run := 1; run <= 3; run++ {
+		cfg.StartRun()
+		if cfg.IsFullReconcile() {
+			t.Fatalf("Expected run %d not to be a full reconcile", run)
+		}
+	}
+}
+
+func TestPruneDeletedIsAnAliasOfPruneIssues(t *testing.T) {
+	if newTestConfig(t, nil).ShouldPruneIssues() {
+		t.Fatal("Expected issues not to be pruned by default")
+	}
+
+	for _, key := range []string{options.ConfigKeyPruneIssues, options.ConfigKeyPruneDeleted} {
+		if !newTestConfig(t, map[string]interface{}{key: true}).ShouldPruneIssues() {
+			t.Fatalf("Expected issues to be pruned with %s", key)
+		}
+	}
+}
+
+func TestParseFieldIDs(t *testing.T) {
+	newField := func(name string, id int) jira.Field {
+		return jira.Field{Name: name, Schema: jira.FieldSchema{CustomID: int64(id)}}
+	}
+
+	fieldIDs, err := parseFieldIDs([]jira.Field{
+		newField("Summary", 1),
+		newField("GitHub-ID", 10001),
+		newField("github-Number", 10002),
+		newField(" github-labels ", 10003),
+		newField("GITHUB-STATUS", 10004),
+		newField("github-reporter\t", 10005),
+		newField("GitHub-Last-Sync", 10006),
+	}, nil)
+	if err != nil {
+		t.Fatalf("parseFieldIDs() returned error: %v", err)
+	}
+
+	expected := fields{
+		githubID:       "10001",
+		githubNumber:   "10002",
+		githubLabels:   "10003",
+		githubStatus:   "10004",
+		githubReporter: "10005",
+		lastUpdate:     "10006",
+	}
+	if *fieldIDs != expected {
+		t.Fatalf("Expected field IDs %+v; got %+v", expected, *fieldIDs)
+	}
+}
+
+func TestParseFieldIDsMissingField(t *testing.T) {
+	_, err := parseFieldIDs([]jira.Field{
+		{Name: "github-id", Schema: jira.FieldSchema{CustomID: 10001}},
+	}, nil)
+	expected := errCustomFieldIDNotFound(
+		CustomFieldNameGitHubNumber,
+		CustomFieldNameGitHubLabels,
+		CustomFieldNameGitHubStatus,
+		CustomFieldNameGitHubReporter,
+		CustomFieldNameGitHubLastSync,
+	)
+	if err == nil || err.Error() != expected.Error() {
+		t.Fatalf("Expected error %v; got %v", expected, err)
+	}
+}
+
+func TestParseFieldIDsMissingFields(t *testing.T) {
+	_, err := parseFieldIDs([]jira.Field{
+		{Name: "github-id", Schema: jira.FieldSchema{CustomID: 10001}},
+		{Name: "github-number", Schema: jira.FieldSchema{CustomID: 10002}},
+		{Name: "github-labels", Schema: jira.FieldSchema{CustomID: 10003}},
+		{Name: "github-status", Schema: jira.FieldSchema{CustomID: 10004}},
+	}, nil)
+
+	var missing *MissingFieldsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected a MissingFieldsError; got %v", err)
+	}
+	expected := []string{CustomFieldNameGitHubReporter, CustomFieldNameGitHubLastSync}
+	if !reflect.DeepEqual(missing.Fields, expected) {
+		t.Fatalf("Expected missing fields %v; got %v", expected, missing.Fields)
+	}
+}
+
+func TestLoadJiraConfigClassifiesProjectErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		expected error
+		message  string
+	}{
+		{
+			name:     "not found",
+			status:   http.StatusNotFound,
+			expected: ErrJiraProjectNotFound,
+			message:  "jira project key not found: TEST; check the `jira-project` key, and that the Jira user may browse the project",
+		},
+		{
+			name:     "unauthorized",
+			status:   http.StatusUnauthorized,
+			expected: ErrJiraAuthenticationFailed,
+			message:  "jira authentication failed while retrieving Jira project TEST; check the Jira credentials",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, `{"errorMessages": ["nope"]}`)
+			}))
+			defer server.Close()
+
+			client, err := jira.NewClient(server.URL, server.Client())
+			if err != nil {
+				t.Fatalf("creating Jira client: %v", err)
+			}
+
+			cfg := newTestConfig(t, map[string]interface{}{
+				options.ConfigKeyJiraProject: testProjectKey,
+			})
+			err = cfg.LoadJiraConfig(client)
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("Expected error %v; got %v", tc.expected, err)
+			}
+			if err.Error() != tc.message {
+				t.Fatalf("Expected message %q; got %q", tc.message, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseFieldIDsFindsSprintBySchema(t *testing.T) {
+	fieldIDs, err := parseFieldIDs([]jira.Field{
+		{Name: "github-id", Schema: jira.FieldSchema{CustomID: 10001}},
+		{Name: "github-number", Schema: jira.FieldSchema{CustomID: 10002}},
+		{Name: "github-labels", Schema: jira.FieldSchema{CustomID: 10003}},
+		{Name: "github-status", Schema: jira.FieldSchema{CustomID: 10004}},
+		{Name: "github-reporter", Schema: jira.FieldSchema{CustomID: 10005}},
+		{Name: "github-last-sync", Schema: jira.FieldSchema{CustomID: 10006}},
+		{Name: "Sprint", Schema: jira.FieldSchema{CustomID: 10100, Custom: "com.atlassian.jira.plugin.system.customfieldtypes:textfield"}},
+		{Name: "Iteration", Schema: jira.FieldSchema{CustomID: 10020, Custom: sprintFieldSchema}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("parseFieldIDs() returned error: %v", err)
+	}
+	if fieldIDs.sprint != "10020" {
+		t.Fatalf("Expected the Sprint field to be found by its schema; got ID %q", fieldIDs.sprint)
+	}
+}
+
+func TestParseFieldIDsOptionalFields(t *testing.T) {
+	fieldIDs, err := parseFieldIDs([]jira.Field{
+		{Name: "github-id", Schema: jira.FieldSchema{CustomID: 10001}},
+		{Name: "github-number", Schema: jira.FieldSchema{CustomID: 10002}},
+		{Name: "github-labels", Schema: jira.FieldSchema{CustomID: 10003}},
+		{Name: "github-status", Schema: jira.FieldSchema{CustomID: 10004}},
+	}, []string{CustomFieldNameGitHubReporter, CustomFieldNameGitHubLastSync})
+	if err != nil {
+		t.Fatalf("parseFieldIDs() returned error: %v", err)
+	}
+	if fieldIDs.githubReporter != "" || fieldIDs.lastUpdate != "" {
+		t.Fatalf("Expected the optional fields to be missing; got %+v", *fieldIDs)
+	}
+
+	// Only the missing fields which are not optional are reported.
+	_, err = parseFieldIDs([]jira.Field{
+		{Name: "github-id", Schema: jira.FieldSchema{CustomID: 10001}},
+	}, []string{CustomFieldNameGitHubNumber, CustomFieldNameGitHubLabels, CustomFieldNameGitHubStatus})
+	var missing *MissingFieldsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected a MissingFieldsError; got %v", err)
+	}
+	expected := []string{CustomFieldNameGitHubReporter, CustomFieldNameGitHubLastSync}
+	if !reflect.DeepEqual(missing.Fields, expected) {
+		t.Fatalf("Expected missing fields %v; got %v", expected, missing.Fields)
+	}
+}
+
+func TestRefreshFieldIDs(t *testing.T) {
+	githubID := 10001
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jFields := []jira.Field{
+			{Name: "github-id", Schema: jira.FieldSchema{CustomID: int64(githubID)}},
+			{Name: "github-number", Schema: jira.FieldSchema{CustomID: 10002}},
+			{Name: "github-labels", Schema: jira.FieldSchema{CustomID: 10003}},
+			{Name: "github-status", Schema: jira.FieldSchema{CustomID: 10004}},
+			{Name: "github-reporter", Schema: jira.FieldSchema{CustomID: 10005}},
+			{Name: "github-last-sync", Schema: jira.FieldSchema{CustomID: 10006}},
+		}
+		if err := json.NewEncoder(w).Encode(jFields); err != nil {
+			t.Errorf("encoding fields: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := jira.NewClient(server.URL, server.Client())
+	if err != nil {
+		t.Fatalf("creating Jira client: %v", err)
+	}
+
+	cfg := newTestConfig(t, map[string]interface{}{
+		options.ConfigKeyFieldRefreshInterval: time.Hour,
+	})
+	clk := clock.NewFake(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	cfg.SetClock(clk)
+	cfg.fieldIDsResolvedAt = clk.Now()
+
+	githubID = 20001
+	refreshed, err := cfg.RefreshFieldIDs(client)
+	if err != nil {
+		t.Fatalf("RefreshFieldIDs() returned error: %v", err)
+	}
+	if refreshed || cfg.GetFieldID(GitHubID) != testFieldIDGitHubID {
+		t.Fatalf("Expected the field IDs not to be refreshed before the interval; got %s", cfg.GetFieldID(GitHubID))
+	}
+
+	hook := logtest.NewGlobal()
+	<-clk.After(time.Hour)
+	refreshed, err = cfg.RefreshFieldIDs(client)
+	if err != nil {
+		t.Fatalf("RefreshFieldIDs() returned error: %v", err)
+	}
+	if !refreshed || cfg.GetFieldID(GitHubID) != "20001" {
+		t.Fatalf("Expected the github-id field ID to be refreshed to 20001; got %s", cfg.GetFieldID(GitHubID))
+	}
+
+	expected := "Custom field github-id changed ID from 10001 to 20001"
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == expected {
+			return
+		}
+	}
+	t.Fatalf("Expected the changed ID to be logged: %q", expected)
+}
+
+func TestNewParsesLabelTypeMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{
+  "github-token": "token",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/test-repo",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "TEST",
+  "since": "2023-01-02T03:04:05+0000",
+  "label-type-map": [
+    {"label": "kind/bug", "type": "Bug"},
+    {"label": "kind/feature", "type": "Story"}
+  ]
+}`)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSlice(options.ConfigKeyConfigFile, nil, "")
+	if err := cmd.Flags().Set(options.ConfigKeyConfigFile, path); err != nil {
+		t.Fatalf("setting config flag: %v", err)
+	}
+
+	cfg, err := New(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	tests := []struct {
+		labels   []string
+		expected string
+	}{
+		{labels: []string{"kind/bug"}, expected: "Bug"},
+		{labels: []string{"good-first-issue", "kind/feature"}, expected: "Story"},
+		// kind/bug is listed first, so it wins over kind/feature.
+		{labels: []string{"kind/feature", "kind/bug"}, expected: "Bug"},
+		{labels: []string{"question"}, expected: options.DefaultIssueType},
+		{labels: nil, expected: options.DefaultIssueType},
+	}
+
+	for _, tt := range tests {
+		if issueType := cfg.GetIssueTypeForLabels(tt.labels); issueType != tt.expected {
+			t.Fatalf("Expected issue type %q for labels %v; got %q", tt.expected, tt.labels, issueType)
+		}
+	}
+}
+
+func TestNewRejectsInvalidLabelTypeMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{
+  "github-token": "token",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/test-repo",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "TEST",
+  "since": "2023-01-02T03:04:05+0000",
+  "label-type-map": [{"label": "kind/bug"}]
+}`)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSlice(options.ConfigKeyConfigFile, nil, "")
+	if err := cmd.Flags().Set(options.ConfigKeyConfigFile, path); err != nil {
+		t.Fatalf("setting config flag: %v", err)
+	}
+
+	if _, err := New(context.Background(), cmd); err != errLabelTypeMapInvalid { //nolint:errorlint
+		t.Fatalf("Expected error %v; got %v", errLabelTypeMapInvalid, err)
+	}
+}
+
+func TestNewRejectsOptionalGitHubID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{
+  "github-token": "token",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/test-repo",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "TEST",
+  "since": "2023-01-02T03:04:05+0000",
+  "optional-fields": ["github-reporter", "GitHub-ID"]
+}`)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSlice(options.ConfigKeyConfigFile, nil, "")
+	if err := cmd.Flags().Set(options.ConfigKeyConfigFile, path); err != nil {
+		t.Fatalf("setting config flag: %v", err)
+	}
+
+	if _, err := New(context.Background(), cmd); !errors.Is(err, errOptionalFieldsInvalid) {
+		t.Fatalf("Expected error %v; got %v", errOptionalFieldsInvalid, err)
+	}
+}
+
+func TestNewRejectsEscapedConvertedMarkdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{
+  "github-token": "token",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/test-repo",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "TEST",
+  "since": "2023-01-02T03:04:05+0000",
+  "convert-markdown": true,
+  "escape-markup": true
+}`)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSlice(options.ConfigKeyConfigFile, nil, "")
+	if err := cmd.Flags().Set(options.ConfigKeyConfigFile, path); err != nil {
+		t.Fatalf("setting config flag: %v", err)
+	}
+
+	if _, err := New(context.Background(), cmd); !errors.Is(err, errEscapeMarkupConflict) {
+		t.Fatalf("Expected error %v; got %v", errEscapeMarkupConflict, err)
+	}
+}
+
+func TestParseReporterFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		usesName bool
+		wantErr  bool
+	}{
+		{name: "not set", format: ""},
+		{name: "login", format: "@{{.Login}}"},
+		{name: "name", format: "{{.Name}} (@{{.Login}})", usesName: true},
+		{name: "name in a condition", format: "{{if .Name}}{{.Login}}{{end}}", usesName: true},
+		{name: "name in a function", format: "{{printf \"%s\" .Name}}", usesName: true},
+		{name: "unknown field", format: "{{.Email}}", wantErr: true},
+		{name: "invalid", format: "{{.Login", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := viper.New()
+			v.Set(options.ConfigKeyReporterFormat, tc.format)
+
+			tmpl, err := parseReporterFormat(v)
+			if tc.wantErr {
+				if !errors.Is(err, errReporterFormatInvalid) {
+					t.Fatalf("Expected error %v; got %v", errReporterFormatInvalid, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseReporterFormat() returned error: %v", err)
+			}
+
+			cfg := &Config{reporterFormat: tmpl}
+			if got := cfg.ReporterFormatUsesName(); got != tc.usesName {
+				t.Fatalf("Expected ReporterFormatUsesName() %t; got %t", tc.usesName, got)
+			}
+		})
+	}
+}
+
+func TestFieldTransforms(t *testing.T) {
+	cfg := newTestConfig(t, map[string]interface{}{
+		options.ConfigKeyFieldTransforms: map[string]interface{}{
+			"summary":                     `[GH] {{trim .}}`,
+			CustomFieldNameGitHubLabels:   `{{if eq . "bug"}}defect{{else}}{{. | replace "/" "-" | upper}}{{end}}`,
+			CustomFieldNameGitHubReporter: `{{.Login}}`,
+		},
+	})
+
+	tests := []struct {
+		field    string
+		value    string
+		expected string
+	}{
+		{field: "summary", value: " Login page is broken ", expected: "[GH] Login page is broken"},
+		{field: CustomFieldNameGitHubLabels, value: "bug", expected: "defect"},
+		{field: CustomFieldNameGitHubLabels, value: "kind/feature", expected: "KIND-FEATURE"},
+		// Fields without a transform are kept as is.
+		{field: "description", value: "Steps to reproduce", expected: "Steps to reproduce"},
+		// The value is a string, so the transform fails and is skipped.
+		{field: CustomFieldNameGitHubReporter, value: "octocat", expected: "octocat"},
+	}
+
+	for _, tt := range tests {
+		if value := cfg.TransformField(tt.field, tt.value); value != tt.expected {
+			t.Fatalf("Expected %s %q to be transformed to %q; got %q", tt.field, tt.value, tt.expected, value)
+		}
+	}
+}
+
+func TestParseFieldTransformsRejectsInvalidTransforms(t *testing.T) {
+	tests := []struct {
+		name       string
+		transforms map[string]interface{}
+	}{
+		{name: "malformed template", transforms: map[string]interface{}{"summary": "{{upper ."}},
+		{name: "unknown function", transforms: map[string]interface{}{"summary": "{{exec .}}"}},
+		{name: "unknown field", transforms: map[string]interface{}{"priority": "{{.}}"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := viper.New()
+			v.Set(options.ConfigKeyFieldTransforms, tc.transforms)
+
+			if _, err := parseFieldTransforms(v); !errors.Is(err, errFieldTransformsInvalid) {
+				t.Fatalf("Expected error %v; got %v", errFieldTransformsInvalid, err)
+			}
+		})
+	}
+}
+
+func TestNewAcceptsBearerToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{
+  "github-token": "token",
+  "jira-bearer-token": "pat",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/test-repo",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "TEST",
+  "since": "2023-01-02T03:04:05+0000"
+}`)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSlice(options.ConfigKeyConfigFile, nil, "")
+	if err := cmd.Flags().Set(options.ConfigKeyConfigFile, path); err != nil {
+		t.Fatalf("setting config flag: %v", err)
+	}
+
+	cfg, err := New(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	// The personal access token takes precedence over basic auth.
+	if !cfg.IsBearerAuth() || cfg.IsBasicAuth() {
+		t.Fatalf("Expected bearer auth only; got bearer %v, basic %v", cfg.IsBearerAuth(), cfg.IsBasicAuth())
+	}
+}
+
+func TestSinceIsTrackedPerRepo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{
+  "github-token": "token",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/first, test-owner/second",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "TEST",
+  "since": {"Test-Owner/First": "2023-02-01T00:00:00+0000"}
+}`)
+
+	cfg := newTestFileConfig(t, path)
+
+	expected := [][2]string{{"test-owner", "first"}, {"test-owner", "second"}}
+	if repos := cfg.GetRepos(); !reflect.DeepEqual(repos, expected) {
+		t.Fatalf("Expected repos %v; got %v", expected, repos)
+	}
+
+	cfg.SetRepo("test-owner", "first")
+	if since := cfg.GetSinceParam().UTC().Format(options.DateFormat); since != "2023-02-01T00:00:00+0000" {
+		t.Fatalf("Expected the since date of the first repo; got %q", since)
+	}
+
+	// The second repo has no date of its own yet, so it starts from the
+	// default date.
+	cfg.SetRepo("test-owner", "second")
+	if since := cfg.GetSinceParam().UTC().Format(options.DateFormat); since != options.DefaultSince {
+		t.Fatalf("Expected the second repo to use the default since date; got %q", since)
+	}
+
+	cfg.SetSince("test-owner/second", time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC))
+
+	expectedSince := map[string]interface{}{
+		"test-owner/first":  "2023-02-01T00:00:00+0000",
+		"test-owner/second": "2023-03-01T00:00:00+0000",
+	}
+	if saved := saveAndRead(t, cfg, path); !reflect.DeepEqual(saved.Since, expectedSince) {
+		t.Fatalf("Expected since %v; got %v", expectedSince, saved.Since)
+	}
+}
+
+func TestScalarSinceAppliesToEveryRepo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{
+  "github-token": "token",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/first,test-owner/second",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "TEST",
+  "since": "2023-01-01T00:00:00+0000"
+}`)
+
+	cfg := newTestFileConfig(t, path)
+
+	for _, repo := range cfg.GetRepos() {
+		cfg.SetRepo(repo[0], repo[1])
+		if since := cfg.GetSinceParam().UTC().Format(options.DateFormat); since != "2023-01-01T00:00:00+0000" {
+			t.Fatalf("Expected %s/%s to use the since date; got %q", repo[0], repo[1], since)
+		}
+	}
+
+	// Once saved, each repo has a date of its own.
+	cfg.SetSince("test-owner/first", time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC))
+
+	expectedSince := map[string]interface{}{
+		"test-owner/first":  "2023-02-01T00:00:00+0000",
+		"test-owner/second": "2023-01-01T00:00:00+0000",
+	}
+	if saved := saveAndRead(t, cfg, path); !reflect.DeepEqual(saved.Since, expectedSince) {
+		t.Fatalf("Expected since %v; got %v", expectedSince, saved.Since)
+	}
+}
+
+func TestSingleRepoSinceIsSavedAsScalar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{
+  "github-token": "token",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/test-repo",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "TEST",
+  "since": "2023-01-01T00:00:00+0000"
+}`)
+
+	cfg := newTestFileConfig(t, path)
+	cfg.SetSince("test-owner/test-repo", time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC))
+
+	if saved := saveAndRead(t, cfg, path); saved.Since != "2023-02-01T00:00:00+0000" {
+		t.Fatalf("Expected since to be saved as a single date; got %v", saved.Since)
+	}
+}
+
+func TestSinceFlagIsNotSaved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{
+  "github-token": "token",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/test-repo",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "TEST",
+  "since": "2023-02-01T00:00:00+0000",
+  "since-from-last-jira-sync": true
+}`)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSlice(options.ConfigKeyConfigFile, nil, "")
+	cmd.Flags().String(options.ConfigKeySince, options.DefaultSince, "")
+	if err := cmd.Flags().Set(options.ConfigKeyConfigFile, path); err != nil {
+		t.Fatalf("setting config flag: %v", err)
+	}
+	if err := cmd.Flags().Set(options.ConfigKeySince, "2023-01-01T00:00:00+0000"); err != nil {
+		t.Fatalf("setting since flag: %v", err)
+	}
+
+	cfg, err := New(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	if since := cfg.GetSinceParam().UTC().Format(options.DateFormat); since != "2023-01-01T00:00:00+0000" {
+		t.Fatalf("Expected the since date of the flag; got %q", since)
+	}
+	if cfg.ShouldDeriveSinceFromJira() {
+		t.Fatal("Expected the since flag to take precedence over since-from-last-jira-sync")
+	}
+
+	cfg.SetSince("test-owner/test-repo", time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC))
+	if saved := saveAndRead(t, cfg, path); saved.Since != "2023-02-01T00:00:00+0000" {
+		t.Fatalf("Expected the saved since date to be left as it was; got %v", saved.Since)
+	}
+}
+
+func TestConfigFileFormats(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		unmarshal func([]byte, interface{}) error
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `github-token: token
+jira-user: user@jira.example.com
+jira-pass: pass
+repo-name: test-owner/test-repo
+jira-uri: https://jira.example.com
+jira-project: TEST
+timeout: 30s
+since: "2023-01-01T00:00:00+0000"
+`,
+			unmarshal: yaml.Unmarshal,
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			content: `github-token = "token"
+jira-user = "user@jira.example.com"
+jira-pass = "pass"
+repo-name = "test-owner/test-repo"
+jira-uri = "https://jira.example.com"
+jira-project = "TEST"
+timeout = "30s"
+since = "2023-01-01T00:00:00+0000"
+`,
+			unmarshal: toml.Unmarshal,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			writeFile(t, path, tc.content)
+
+			cfg := newTestFileConfig(t, path)
+			if project := cfg.GetConfigString(options.ConfigKeyJiraProject); project != "TEST" {
+				t.Fatalf("Expected jira-project to be read from the %s file; got %q", tc.name, project)
+			}
+			if timeout := cfg.GetTimeout(); timeout != 30*time.Second {
+				t.Fatalf("Expected timeout of 30s; got %v", timeout)
+			}
+
+			cfg.SetSince("test-owner/test-repo", time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC))
+			if saved := saveAndRead(t, cfg, path); saved.Since != "2023-02-01T00:00:00+0000" {
+				t.Fatalf("Expected the since date to be saved to the state file; got %v", saved.Since)
+			}
+
+			// The config file is left as it was, so it still decodes as
+			// written.
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading config: %v", err)
+			}
+			if string(b) != tc.content {
+				t.Fatalf("Expected the %s config to be left as it was; got:\n%s", tc.name, b)
+			}
+			var values map[string]interface{}
+			if err := tc.unmarshal(b, &values); err != nil {
+				t.Fatalf("decoding the %s config: %v", tc.name, err)
+			}
+
+			// The saved state reads back over the config.
+			if since := newTestFileConfig(t, path).GetSinceParam().UTC().Format(options.DateFormat); since != "2023-02-01T00:00:00+0000" {
+				t.Fatalf("Expected the saved since date to read back; got %q", since)
+			}
+		})
+	}
+}
+
+// newTestFileConfig creates a Config from the configuration file at path.
+func newTestFileConfig(t *testing.T, path string) *Config {
+	t.Helper()
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSlice(options.ConfigKeyConfigFile, nil, "")
+	if err := cmd.Flags().Set(options.ConfigKeyConfigFile, path); err != nil {
+		t.Fatalf("setting config flag: %v", err)
+	}
+
+	cfg, err := New(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	return cfg
+}
+
+// saveAndRead saves the state of the configuration at path to the default
+// state file, and returns the saved state.
+func saveAndRead(t *testing.T, cfg *Config, path string) state {
+	t.Helper()
+
+	if err := cfg.SaveState(); err != nil {
+		t.Fatalf("SaveState() returned error: %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(filepath.Dir(path), options.DefaultStateFileName))
+	if err != nil {
+		t.Fatalf("reading saved state: %v", err)
+	}
+
+	var saved state
+	if err := json.Unmarshal(b, &saved); err != nil {
+		t.Fatalf("decoding saved state: %v", err)
+	}
+
+	return saved
+}
+
+func TestIssueLogger(t *testing.T) {
+	cfg := newTestConfig(t, nil)
+
+	expected := log.Fields{"repo": "test-owner/test-repo", "gh_issue": 1, "jira_key": "TEST-1"}
+	if fields := cfg.IssueLogger(1, "TEST-1").Data; !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("Expected fields %v; got %v", expected, fields)
+	}
+
+	// The Jira issue of a GitHub issue may not exist yet.
+	if _, ok := cfg.IssueLogger(1, "").Data["jira_key"]; ok {
+		t.Fatal("Expected no jira_key field without a Jira issue")
+	}
+}
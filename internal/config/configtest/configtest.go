@@ -0,0 +1,131 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package configtest creates configuration objects for tests, without
+// configuration files or a Jira server. It is only imported by tests.
+package configtest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	jira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+// Custom field IDs assigned by NewConfig.
+const (
+	FieldIDGitHubID                = "10001"
+	FieldIDGitHubNumber            = "10002"
+	FieldIDGitHubLabels            = "10003"
+	FieldIDGitHubStatus            = "10004"
+	FieldIDGitHubReporter          = "10005"
+	FieldIDGitHubLastSync          = "10006"
+	FieldIDGitHubAssignee          = "10007"
+	FieldIDGitHubComments          = "10008"
+	FieldIDGitHubUpdated           = "10009"
+	FieldIDSyncVersion             = "10010"
+	FieldIDGitHubLabelColors       = "10011"
+	FieldIDGitHubAuthorAssociation = "10012"
+	FieldIDGitHubCreated           = "10013"
+	FieldIDGitHubClosed            = "10014"
+	FieldIDSprint                  = "10015"
+	FieldIDGitHubRepository        = "10016"
+
+	// ProjectKey is the Jira project key assigned by NewConfig.
+	ProjectKey = "TEST"
+
+	// sprintFieldSchema is the schema of the Sprint field of Jira Software.
+	sprintFieldSchema = "com.pyxis.greenhopper.jira:gh-sprint"
+)
+
+// fieldIDs are the IDs of the custom fields, keyed by name.
+var fieldIDs = map[string]string{
+	config.CustomFieldNameGitHubID:                FieldIDGitHubID,
+	config.CustomFieldNameGitHubNumber:            FieldIDGitHubNumber,
+	config.CustomFieldNameGitHubLabels:            FieldIDGitHubLabels,
+	config.CustomFieldNameGitHubStatus:            FieldIDGitHubStatus,
+	config.CustomFieldNameGitHubReporter:          FieldIDGitHubReporter,
+	config.CustomFieldNameGitHubLastSync:          FieldIDGitHubLastSync,
+	config.CustomFieldNameGitHubAssignee:          FieldIDGitHubAssignee,
+	config.CustomFieldNameGitHubComments:          FieldIDGitHubComments,
+	config.CustomFieldNameGitHubUpdated:           FieldIDGitHubUpdated,
+	config.CustomFieldNameSyncVersion:             FieldIDSyncVersion,
+	config.CustomFieldNameGitHubLabelColors:       FieldIDGitHubLabelColors,
+	config.CustomFieldNameGitHubAuthorAssociation: FieldIDGitHubAuthorAssociation,
+	config.CustomFieldNameGitHubCreated:           FieldIDGitHubCreated,
+	config.CustomFieldNameGitHubClosed:            FieldIDGitHubClosed,
+	config.CustomFieldNameGitHubRepository:        FieldIDGitHubRepository,
+}
+
+// NewConfig creates a Config from the provided configuration values without
+// reading a configuration file or contacting Jira. The Jira project and
+// custom field IDs are set to well-known test values, except for the custom
+// fields listed in `optional-fields`, which do not exist. It panics if the
+// values can't be parsed.
+func NewConfig(ctx context.Context, values map[string]interface{}) *config.Config {
+	v := viper.New()
+	v.Set(options.ConfigKeyRepoName, "test-owner/test-repo")
+	v.Set(options.ConfigKeySince, options.DefaultSince)
+	v.Set(options.ConfigKeyJiraUser, "test-user")
+	v.Set(options.ConfigKeyJiraPassword, "test-password")
+	for key, value := range values {
+		v.Set(key, value)
+	}
+
+	var optional []string
+	for _, name := range v.GetStringSlice(options.ConfigKeyOptionalFields) {
+		optional = append(optional, strings.ToLower(strings.TrimSpace(name)))
+	}
+
+	jiraFields := []jira.Field{newField("Sprint", FieldIDSprint, sprintFieldSchema)}
+	for name, id := range fieldIDs {
+		if !slices.Contains(optional, name) {
+			jiraFields = append(jiraFields, newField(name, id, ""))
+		}
+	}
+
+	cfg, err := config.NewWithJiraConfig(ctx, v, &jira.Project{Key: ProjectKey}, jiraFields)
+	if err != nil {
+		panic(fmt.Sprintf("creating test config: %v", err))
+	}
+
+	return cfg
+}
+
+// newField returns the metadata of the custom field name with the given ID
+// and schema.
+func newField(name, id, schema string) jira.Field {
+	customID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		panic(err)
+	}
+
+	return jira.Field{
+		ID:   "customfield_" + id,
+		Name: name,
+		Schema: jira.FieldSchema{
+			Custom:   schema,
+			CustomID: customID,
+		},
+	}
+}
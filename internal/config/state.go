@@ -0,0 +1,129 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+// state is the content of the `state-file`: the state of the
+// synchronization, kept apart from the configuration files, which are never
+// written.
+type state struct {
+	Since interface{} `json:"since,omitempty"`
+
+	// JiraToken and JiraSecret are the Jira OAuth tokens obtained by the
+	// handshake, as set by SetJiraToken.
+	JiraToken  string `json:"jira-token,omitempty"`
+	JiraSecret string `json:"jira-secret,omitempty"`
+}
+
+// ShouldSaveState returns whether the state is saved to the `state-file`
+// after each synchronization. With `no-save`, it is only kept in memory.
+func (c *Config) ShouldSaveState() bool {
+	return !c.cmdConfig.GetBool(options.ConfigKeyNoSave)
+}
+
+// GetStateFile returns the path of the file the state is saved to: the
+// `state-file`, or the default state file next to the configuration file.
+// It is empty if there is neither.
+func (c *Config) GetStateFile() string {
+	if path := c.cmdConfig.GetString(options.ConfigKeyStateFile); path != "" {
+		return path
+	}
+	if c.cmdFile == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(c.cmdFile), options.DefaultStateFileName)
+}
+
+// loadState reads the state from the state file, if it exists, in place of
+// the `since` date and Jira OAuth tokens of the configuration, which apply
+// until the state is first saved. A `--since` flag takes precedence over the
+// saved date, which is then saved again as it was.
+func (c *Config) loadState() error {
+	path := c.GetStateFile()
+	if path == "" {
+		return nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Debugf("State file %s does not exist yet; using the configured state", path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading state file: %w", err)
+	}
+
+	var s state
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("decoding state file %s: %w", path, err)
+	}
+
+	if s.Since != nil {
+		if c.sinceOverride {
+			c.savedSince = s.Since
+		} else {
+			c.cmdConfig.Set(options.ConfigKeySince, s.Since)
+		}
+	}
+	if s.JiraToken != "" {
+		c.cmdConfig.Set(options.ConfigKeyJiraToken, s.JiraToken)
+		c.cmdConfig.Set(options.ConfigKeyJiraSecret, s.JiraSecret)
+	}
+
+	return nil
+}
+
+// SaveState saves the `since` date and the Jira OAuth tokens, if any, to the
+// state file. If several repositories are synchronized, `since` is saved as
+// a map of the date of each repository, keyed by owner/repo. If `since` was
+// overridden with the `--since` flag, the saved `since` is left as it was.
+func (c *Config) SaveState() error {
+	path := c.GetStateFile()
+	if path == "" {
+		log.Debug("No state file is set; not saving the state")
+		return nil
+	}
+
+	s := state{
+		Since:      c.sinceToSave(),
+		JiraToken:  c.cmdConfig.GetString(options.ConfigKeyJiraToken),
+		JiraSecret: c.cmdConfig.GetString(options.ConfigKeyJiraSecret),
+	}
+	b, err := json.MarshalIndent(&s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling state: %w", err)
+	}
+
+	// The state may hold the Jira OAuth tokens.
+	if err := os.WriteFile(path, append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing state file %s: %w", path, err)
+	}
+
+	return nil
+}
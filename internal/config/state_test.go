@@ -0,0 +1,104 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dghubble/oauth1"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+func TestStateIsSavedApartFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeFile(t, path, `{
+  "github-token": "token",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/test-repo",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "TEST",
+  "since": "2023-02-01T00:00:00+0000"
+}`)
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+
+	// Without a state file yet, the since date of the config applies.
+	cfg := newTestFileConfig(t, path)
+	if since := cfg.GetSinceParam().UTC().Format(options.DateFormat); since != "2023-02-01T00:00:00+0000" {
+		t.Fatalf("Expected the since date of the config; got %q", since)
+	}
+
+	cfg.SetSince("test-owner/test-repo", time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC))
+	cfg.SetJiraToken(&oauth1.Token{Token: "oauth-token", TokenSecret: "oauth-secret"})
+	saved := saveAndRead(t, cfg, path)
+	if saved.Since != "2023-03-01T00:00:00+0000" || saved.JiraToken != "oauth-token" || saved.JiraSecret != "oauth-secret" {
+		t.Fatalf("Expected the since date and Jira tokens to be saved; got %+v", saved)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, options.DefaultStateFileName))
+	if err != nil {
+		t.Fatalf("checking state file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("Expected the state file, which holds tokens, to be private; got %v", perm)
+	}
+
+	if b, err := os.ReadFile(path); err != nil || !bytes.Equal(b, original) {
+		t.Fatalf("Expected the config file to be left as it was; got %q (%v)", b, err)
+	}
+
+	cfg = newTestFileConfig(t, path)
+	if since := cfg.GetSinceParam().UTC().Format(options.DateFormat); since != "2023-03-01T00:00:00+0000" {
+		t.Fatalf("Expected the since date of the state file; got %q", since)
+	}
+	if token := cfg.GetConfigString(options.ConfigKeyJiraToken); token != "oauth-token" {
+		t.Fatalf("Expected the Jira token of the state file; got %q", token)
+	}
+}
+
+func TestStateFileAndNoSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	statePath := filepath.Join(dir, "state", "sync.json")
+	writeFile(t, path, `{
+  "github-token": "token",
+  "jira-user": "user@jira.example.com",
+  "jira-pass": "pass",
+  "repo-name": "test-owner/test-repo",
+  "jira-uri": "https://jira.example.com",
+  "jira-project": "TEST",
+  "no-save": true,
+  "state-file": "`+filepath.ToSlash(statePath)+`"
+}`)
+
+	cfg := newTestFileConfig(t, path)
+	if cfg.ShouldSaveState() {
+		t.Fatal("Expected the state not to be saved with no-save")
+	}
+	if stateFile := cfg.GetStateFile(); stateFile != filepath.ToSlash(statePath) {
+		t.Fatalf("Expected the state file %q; got %q", statePath, stateFile)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package encoding encodes and decodes documents, such as the configuration
+// file, in the formats they may be written in.
+package encoding
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Encoder encodes and decodes the keys and values of a document in one
+// format.
+type Encoder interface {
+	// Encode encodes the keys and values of a document.
+	Encode(values map[string]interface{}) ([]byte, error)
+	// Decode decodes the keys and values of a document.
+	Decode(b []byte) (map[string]interface{}, error)
+}
+
+// encoders are the Encoders of each supported file extension.
+var encoders = map[string]Encoder{
+	"json": JSON{},
+	"yaml": YAML{},
+	"yml":  YAML{},
+	"toml": TOML{},
+}
+
+// ByExtension returns the Encoder of files with the extension ext, e.g.
+// ".yaml". The leading dot is optional, and the extension is matched
+// case-insensitively.
+func ByExtension(ext string) (Encoder, error) {
+	enc, ok := encoders[strings.ToLower(strings.TrimPrefix(ext, "."))]
+	if !ok {
+		return nil, fmt.Errorf("no encoder for extension %q", ext)
+	}
+
+	return enc, nil
+}
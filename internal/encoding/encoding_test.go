@@ -0,0 +1,93 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encoding
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestByExtension(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      string
+		expected Encoder
+	}{
+		{name: "json", ext: ".json", expected: JSON{}},
+		{name: "yaml", ext: ".yaml", expected: YAML{}},
+		{name: "yml", ext: ".yml", expected: YAML{}},
+		{name: "toml", ext: ".toml", expected: TOML{}},
+		{name: "without dot", ext: "toml", expected: TOML{}},
+		{name: "upper case", ext: ".YAML", expected: YAML{}},
+		{name: "unknown", ext: ".ini"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			enc, err := ByExtension(tc.ext)
+			if tc.expected == nil {
+				if err == nil {
+					t.Fatalf("Expected an error for extension %q; got %T", tc.ext, enc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ByExtension(%q) returned error: %v", tc.ext, err)
+			}
+			if enc != tc.expected {
+				t.Fatalf("Expected %T for extension %q; got %T", tc.expected, tc.ext, enc)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	values, err := JSON{}.Decode([]byte(`{"repo-name": "test-owner/test-repo", "period": 600, "ratio": 0.5}`))
+	if err != nil {
+		t.Fatalf("Decode() returned error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"repo-name": "test-owner/test-repo",
+		"period":    int64(600),
+		"ratio":     0.5,
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("Expected decoded JSON %v; got %v", expected, values)
+	}
+
+	for _, enc := range []Encoder{JSON{}, YAML{}, TOML{}} {
+		t.Run(reflect.TypeOf(enc).Name(), func(t *testing.T) {
+			b, err := enc.Encode(values)
+			if err != nil {
+				t.Fatalf("Encode() returned error: %v", err)
+			}
+
+			decoded, err := enc.Decode(b)
+			if err != nil {
+				t.Fatalf("Decode() returned error: %v", err)
+			}
+			if decoded["repo-name"] != "test-owner/test-repo" {
+				t.Fatalf("Expected repo-name to round-trip; got %v", decoded["repo-name"])
+			}
+			if _, ok := decoded["period"].(string); ok || fmt.Sprint(decoded["period"]) != "600" {
+				t.Fatalf("Expected period to round-trip as a number; got %#v", decoded["period"])
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// JSON is the Encoder of JSON documents.
+type JSON struct{}
+
+// Encode encodes values as indented JSON.
+func (JSON) Encode(values map[string]interface{}) ([]byte, error) {
+	b, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding JSON: %w", err)
+	}
+
+	return b, nil
+}
+
+// Decode decodes a JSON object. Its numbers are decoded as integers, or
+// floats if they are not integral, so that they are encoded as numbers by
+// the other Encoders.
+func (JSON) Decode(b []byte) (map[string]interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var values map[string]interface{}
+	if err := d.Decode(&values); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+
+	return withNumbers(values).(map[string]interface{}), nil //nolint:forcetypeassert // maps stay maps
+}
+
+// withNumbers replaces the JSON numbers of a decoded JSON value with
+// integers, or floats if they are not integral.
+func withNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64() //nolint:errcheck // JSON numbers are valid floats
+		return f
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = withNumbers(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = withNumbers(e)
+		}
+		return v
+	default:
+		return value
+	}
+}
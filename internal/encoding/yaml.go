@@ -0,0 +1,46 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encoding
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML is the Encoder of YAML documents.
+type YAML struct{}
+
+// Encode encodes values as YAML.
+func (YAML) Encode(values map[string]interface{}) ([]byte, error) {
+	b, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("encoding YAML: %w", err)
+	}
+
+	return b, nil
+}
+
+// Decode decodes a YAML document.
+func (YAML) Decode(b []byte) (map[string]interface{}, error) {
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("decoding YAML: %w", err)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package github
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// appJWTLifetime is the lifetime of the JWTs authenticating as a GitHub App,
+// which GitHub caps at 10 minutes.
+const appJWTLifetime = 9 * time.Minute
+
+// appTokenSource is an oauth2.TokenSource of installation access tokens of a
+// GitHub App. Each call to Token requests a new installation access token;
+// wrap it in oauth2.ReuseTokenSource to reuse tokens until they expire.
+type appTokenSource struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+
+	// client is the unauthenticated client requesting installation access
+	// tokens.
+	client *gogh.Client
+}
+
+// newAppTokenSource creates an appTokenSource from the app ID, the
+// installation ID and the path to the PEM-encoded private key of the app.
+func newAppTokenSource(appID, installationID int64, privateKeyPath string) (*appTokenSource, error) {
+	pemKey, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading GitHub App private key: %w", err)
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(pemKey)
+	if err != nil {
+		return nil, fmt.Errorf("parsing GitHub App private key: %w", err)
+	}
+
+	return &appTokenSource{
+		appID:          appID,
+		installationID: installationID,
+		key:            key,
+		client:         gogh.NewClient(nil),
+	}, nil
+}
+
+// Token requests a new installation access token, authenticating as the
+// GitHub App with a JWT signed by its private key.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	// The issue date is backdated to allow for clock drift.
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Issuer:    strconv.FormatInt(s.appID, 10),
+		IssuedAt:  jwt.NewNumericDate(now.Add(-time.Minute)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(appJWTLifetime)),
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(s.key)
+	if err != nil {
+		return nil, fmt.Errorf("signing GitHub App JWT: %w", err)
+	}
+
+	token, resp, err := s.client.WithAuthToken(signed).Apps.CreateInstallationToken(
+		context.Background(),
+		s.installationID,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"creating access token of GitHub App installation %d: %w (response: %v)",
+			s.installationID,
+			err,
+			resp,
+		)
+	}
+
+	log.Debugf(
+		"Created access token of GitHub App installation %d, expiring at %s",
+		s.installationID,
+		token.GetExpiresAt(),
+	)
+
+	return &oauth2.Token{
+		AccessToken: token.GetToken(),
+		Expiry:      token.GetExpiresAt().Time,
+	}, nil
+}
@@ -18,66 +18,136 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	gogh "github.com/google/go-github/v56/github"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
-	"sigs.k8s.io/release-sdk/github"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/clock"
+	synchttp "github.com/uwu-tools/gh-jira-issue-sync/internal/http"
 )
 
 // Client is a wrapper around the GitHub API Client library we
 // use. It allows us to swap in other implementations, such as a dry run
 // clients, or mock clients for testing.
 type Client interface {
-	ListIssues(owner, repo string) ([]*gogh.Issue, error)
+	ListIssues(owner, repo string, opts ListIssuesOptions) ([]*gogh.Issue, error)
 	ListComments(
 		owner, repo string, issue *gogh.Issue, since time.Time,
 	) ([]*gogh.IssueComment, error)
 	GetUser(login string) (*gogh.User, error)
+	GetIssue(owner, repo string, number int) (*gogh.Issue, error)
+	EditIssue(owner, repo string, number int, req *gogh.IssueRequest) (*gogh.Issue, error)
+	ListTimeline(owner, repo string, number int) ([]*gogh.Timeline, error)
+	// GetClosingPullRequest returns the merged pull request which closed a
+	// GitHub issue, or nil if the issue was not closed by a pull request.
+	GetClosingPullRequest(owner, repo string, number int) (*gogh.PullRequest, error)
+	GetRepository(owner, repo string) (*gogh.Repository, error)
 }
 
 // githubClient is a standard GitHub clients, that actually makes all of the
 // requests against the GitHub REST API. It is the canonical implementation
 // of GitHubClient.
 type githubClient struct {
-	client     *github.GitHub
 	goghClient *gogh.Client
+
+	// parentContext returns the parent context of each request, which
+	// aborts it on shutdown or once the synchronization is over its maximum
+	// duration, and timeout bounds each of them; zero means no timeout.
+	parentContext func() context.Context
+	timeout       time.Duration
+
+	// rateLimit, if not nil, records the rate limit left by the responses,
+	// which is waited for before the next request.
+	rateLimit *rateLimitTransport
 }
 
 const itemsPerPage = 100
 
-// ListIssues returns the list of GitHub issues since the last run of the tool.
-func (g *githubClient) ListIssues(owner, repo string) ([]*gogh.Issue, error) {
+// ListIssuesOptions selects and orders the issues returned by ListIssues. The
+// zero value lists the issues of any state, in the default order of the
+// GitHub API.
+type ListIssuesOptions struct {
+	// State is the state of the issues to list: "open", "closed" or "all".
+	// If empty, issues of any state are listed.
+	State string
+	// Since, if not zero, restricts the issues to those updated at or after
+	// it.
+	Since time.Time
+	// Sort is the field to order the issues by: "created", "updated" or
+	// "comments". If empty, the issues are ordered by creation.
+	Sort string
+	// Direction is the direction of the order: "asc" or "desc". If empty,
+	// the issues are in descending order.
+	Direction string
+}
+
+// ErrIssueNotFound is returned by GetIssue if the GitHub issue does not
+// exist, e.g. because it was deleted.
+var ErrIssueNotFound = errors.New("GitHub issue not found")
+
+// requestContext returns the context of a single request, derived from the
+// context of the client and bounded by its timeout. It first waits for the
+// rate limit to reset if the last response left fewer requests than the
+// buffer, which the timeout does not bound. If the client context is done
+// during the wait, so is the returned context, which fails the request.
+func (g *githubClient) requestContext() (context.Context, context.CancelFunc) {
+	ctx := context.Background()
+	if g.parentContext != nil {
+		ctx = g.parentContext()
+	}
+	if g.rateLimit != nil {
+		_ = g.rateLimit.wait(ctx)
+	}
+	if g.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, g.timeout)
+}
+
+// ListIssues returns the list of GitHub issues of a repository selected by
+// opts, excluding pull requests.
+func (g *githubClient) ListIssues(owner, repo string, opts ListIssuesOptions) ([]*gogh.Issue, error) {
 	var issues []*gogh.Issue
 
-	// TODO(github): Should issue state be configurable?
-	issueState := github.IssueStateAll
-
-	// TODO(github): Consider if these options need to be exposed upstream.
-	/*
-		gogh.IssueListByRepoOptions{
-			Since:     g.cfg.GetSinceParam(),
-			State:     string(issueState),
-			Sort:      "created",
-			Direction: "asc",
-			ListOptions: gogh.ListOptions{
-				PerPage: itemsPerPage,
-			},
-		}
-	*/
-	is, err := g.client.ListIssues(owner, repo, issueState)
-	if err != nil {
-		return nil, fmt.Errorf("listing GitHub issues: %w", err)
+	state := opts.State
+	if state == "" {
+		state = "all"
 	}
 
-	for _, v := range is {
-		// If PullRequestLinks is not nil, it's a Pull Request
-		if v.PullRequestLinks == nil {
-			issues = append(issues, v)
+	listOpts := &gogh.IssueListByRepoOptions{
+		State:     state,
+		Since:     opts.Since,
+		Sort:      opts.Sort,
+		Direction: opts.Direction,
+		ListOptions: gogh.ListOptions{
+			PerPage: itemsPerPage,
+		},
+	}
+	for {
+		ctx, cancel := g.requestContext()
+		page, resp, err := g.goghClient.Issues.ListByRepo(ctx, owner, repo, listOpts)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("listing GitHub issues: %w", err)
+		}
+
+		for _, v := range page {
+			// If PullRequestLinks is not nil, it's a Pull Request
+			if v.PullRequestLinks == nil {
+				issues = append(issues, v)
+			}
 		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
 	}
 
 	log.Debug("Collected all GitHub issues")
@@ -90,21 +160,34 @@ func (g *githubClient) ListComments(
 	owner, repo string, issue *gogh.Issue, since time.Time,
 ) ([]*gogh.IssueComment, error) {
 	issueNum := issue.GetNumber()
-	comments, err := g.client.ListComments(
-		owner,
-		repo,
-		issueNum,
-		github.SortCreated,
-		github.SortDirectionAscending,
-		&since,
-	)
-	if err != nil {
-		log.Errorf("Error retrieving GitHub comments for issue #%d. Error: %v.", issueNum, err)
-		return nil, fmt.Errorf(
-			"listing GitHub comments for issue #%d. Error: %w",
-			issueNum,
-			err,
-		)
+
+	var comments []*gogh.IssueComment
+	opts := &gogh.IssueListCommentsOptions{
+		Sort:      gogh.String("created"),
+		Direction: gogh.String("asc"),
+		Since:     &since,
+		ListOptions: gogh.ListOptions{
+			PerPage: itemsPerPage,
+		},
+	}
+	for {
+		ctx, cancel := g.requestContext()
+		page, resp, err := g.goghClient.Issues.ListComments(ctx, owner, repo, issueNum, opts)
+		cancel()
+		if err != nil {
+			log.Errorf("Error retrieving GitHub comments for issue #%d. Error: %v.", issueNum, err)
+			return nil, fmt.Errorf(
+				"listing GitHub comments for issue #%d. Error: %w",
+				issueNum,
+				err,
+			)
+		}
+		comments = append(comments, page...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
 	}
 
 	return comments, nil
@@ -113,7 +196,10 @@ func (g *githubClient) ListComments(
 // GetUser returns a GitHub user from its login.
 func (g *githubClient) GetUser(login string) (*gogh.User, error) {
 	log.Debugf("Retrieving GitHub user (%s)", login)
-	user, resp, err := g.goghClient.Users.Get(context.Background(), login)
+	ctx, cancel := g.requestContext()
+	defer cancel()
+
+	user, resp, err := g.goghClient.Users.Get(ctx, login)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"retrieving GitHub user (%s): %w (response: %v)",
@@ -126,40 +212,232 @@ func (g *githubClient) GetUser(login string) (*gogh.User, error) {
 	return user, nil
 }
 
-// New creates a GitHubClient and returns it; which
-// implementation it uses depends on the configuration of this
-// run. For example, a dry-run clients may be created which does
-// not make any requests that would change anything on the server,
-// but instead simply prints out the actions that it's asked to take.
-func New(token string) (Client, error) {
-	client, err := github.NewWithToken(token)
+// GetIssue returns a single GitHub issue from its number.
+func (g *githubClient) GetIssue(owner, repo string, number int) (*gogh.Issue, error) {
+	log.Debugf("Retrieving GitHub issue #%d", number)
+	ctx, cancel := g.requestContext()
+	defer cancel()
+
+	issue, resp, err := g.goghClient.Issues.Get(ctx, owner, repo, number)
 	if err != nil {
-		return nil, fmt.Errorf("creating sync client: %w", err)
+		// GitHub answers 410 Gone for deleted issues, and 404 Not Found for
+		// issues which never existed.
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone) {
+			return nil, fmt.Errorf("retrieving GitHub issue #%d: %w", number, ErrIssueNotFound)
+		}
+		return nil, fmt.Errorf(
+			"retrieving GitHub issue #%d: %w (response: %v)",
+			number,
+			err,
+			resp,
+		)
 	}
 
-	opts := &github.Options{
-		ItemsPerPage: itemsPerPage,
+	return issue, nil
+}
+
+// GetRepository returns a GitHub repository from its owner and name.
+func (g *githubClient) GetRepository(owner, repo string) (*gogh.Repository, error) {
+	log.Debugf("Retrieving GitHub repository %s/%s", owner, repo)
+	ctx, cancel := g.requestContext()
+	defer cancel()
+
+	repository, resp, err := g.goghClient.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"retrieving GitHub repository %s/%s: %w (response: %v)",
+			owner,
+			repo,
+			err,
+			resp,
+		)
 	}
 
-	client.SetOptions(opts)
+	return repository, nil
+}
+
+// EditIssue updates a single GitHub issue from its number with the non-nil
+// fields of req.
+func (g *githubClient) EditIssue(owner, repo string, number int, req *gogh.IssueRequest) (*gogh.Issue, error) {
+	log.Debugf("Editing GitHub issue #%d", number)
+	ctx, cancel := g.requestContext()
+	defer cancel()
 
-	ctx := context.Background()
+	issue, resp, err := g.goghClient.Issues.Edit(ctx, owner, repo, number, req)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"editing GitHub issue #%d: %w (response: %v)",
+			number,
+			err,
+			resp,
+		)
+	}
+
+	return issue, nil
+}
+
+// ListTimeline returns all the timeline events of a GitHub issue in ascending
+// order of creation.
+func (g *githubClient) ListTimeline(owner, repo string, number int) ([]*gogh.Timeline, error) {
+	log.Debugf("Retrieving timeline of GitHub issue #%d", number)
+
+	var events []*gogh.Timeline
+	opts := &gogh.ListOptions{PerPage: itemsPerPage}
+	for {
+		ctx, cancel := g.requestContext()
+		page, resp, err := g.goghClient.Issues.ListIssueTimeline(ctx, owner, repo, number, opts)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"retrieving timeline of GitHub issue #%d: %w (response: %v)",
+				number,
+				err,
+				resp,
+			)
+		}
+		events = append(events, page...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return events, nil
+}
+
+// GetClosingPullRequest returns the merged pull request which closed a
+// GitHub issue, or nil if it was not closed by a pull request. The pull
+// request is found from the commit of the last "closed" event of the
+// issue's timeline.
+func (g *githubClient) GetClosingPullRequest(owner, repo string, number int) (*gogh.PullRequest, error) {
+	events, err := g.ListTimeline(owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	var commitID string
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].GetEvent() == "closed" && events[i].GetCommitID() != "" {
+			commitID = events[i].GetCommitID()
+			break
+		}
+	}
+	if commitID == "" {
+		return nil, nil
+	}
+
+	log.Debugf("Retrieving pull requests of commit %s, which closed GitHub issue #%d", commitID, number)
+	ctx, cancel := g.requestContext()
+	defer cancel()
+
+	prs, resp, err := g.goghClient.PullRequests.ListPullRequestsWithCommit(
+		ctx, owner, repo, commitID, &gogh.ListOptions{PerPage: itemsPerPage},
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"retrieving pull requests of commit %s: %w (response: %v)",
+			commitID,
+			err,
+			resp,
+		)
+	}
+
+	for _, pr := range prs {
+		if pr.MergedAt != nil {
+			return pr, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// New creates a GitHubClient and returns it; which
+// implementation it uses depends on the configuration of this
+// run. For example, a dry-run clients may be created which does
+// not make any requests that would change anything on the server,
+// but instead simply prints out the actions that it's asked to take.
+//
+// Every request is aborted once the context returned by parentContext for
+// it is done, or after timeout, unless it is zero. Requests failing with a
+// network error or a server error are retried with the exponential backoff
+// configured by retry until then. Once fewer than rateLimitBuffer requests
+// remain before the rate limit of the GitHub API, the client waits for it to
+// reset. A zero rateLimitBuffer never waits.
+func New(
+	parentContext func() context.Context,
+	token string,
+	rateLimitBuffer int,
+	timeout time.Duration,
+	retry synchttp.Retry,
+) (Client, error) {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{
 			AccessToken: token,
 		},
 	)
-	tc := oauth2.NewClient(ctx, ts)
 
-	goghClient := gogh.NewClient(tc)
+	return newClient(parentContext, ts, rateLimitBuffer, timeout, retry), nil
+}
+
+// NewWithApp creates a GitHubClient authenticated as an installation of a
+// GitHub App, from the app ID, the installation ID and the path to the
+// private key of the app. Installation access tokens expire after an hour;
+// a new one is requested whenever the current one expires. The context, rate
+// limit, timeout and retries are handled as by New.
+func NewWithApp(
+	parentContext func() context.Context,
+	appID, installationID int64,
+	privateKeyPath string,
+	rateLimitBuffer int,
+	timeout time.Duration,
+	retry synchttp.Retry,
+) (Client, error) {
+	ts, err := newAppTokenSource(appID, installationID, privateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return newClient(parentContext, oauth2.ReuseTokenSource(nil, ts), rateLimitBuffer, timeout, retry), nil
+}
+
+// newClient creates a GitHubClient authenticated with the tokens of ts,
+// which waits for the rate limit to reset once fewer than rateLimitBuffer
+// requests remain, and whose requests are bounded by the context returned by
+// parentContext and by timeout, and retried as configured by retry.
+func newClient(
+	parentContext func() context.Context,
+	ts oauth2.TokenSource,
+	rateLimitBuffer int,
+	timeout time.Duration,
+	retry synchttp.Retry,
+) Client {
+	tc := oauth2.NewClient(parentContext(), ts)
+
+	var rateLimit *rateLimitTransport
+	if rateLimitBuffer > 0 {
+		rateLimit = &rateLimitTransport{
+			base:   tc.Transport,
+			buffer: rateLimitBuffer,
+			clock:  clock.Real{},
+		}
+		tc.Transport = rateLimit
+	}
+	tc.Transport = &retryTransport{
+		base:    tc.Transport,
+		retry:   retry,
+		timeout: timeout,
+	}
 
 	ret := &githubClient{
-		client:     client,
-		goghClient: goghClient,
+		goghClient:    gogh.NewClient(tc),
+		parentContext: parentContext,
+		timeout:       timeout,
+		rateLimit:     rateLimit,
 	}
 
 	log.Debug("Successfully connected to GitHub.")
-	return ret, nil
+	return ret
 }
 
 // GetRepo returns the user/org name and the repo name of the configured GitHub
@@ -0,0 +1,515 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	gogh "github.com/google/go-github/v56/github"
+	"golang.org/x/oauth2"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/clock"
+	synchttp "github.com/uwu-tools/gh-jira-issue-sync/internal/http"
+)
+
+func TestRequestsAreBoundByContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		fmt.Fprint(w, `{"number": 1}`)
+	}))
+	defer server.Close()
+
+	goghClient := gogh.NewClient(server.Client())
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("parsing server URL: %v", err)
+	}
+	goghClient.BaseURL = baseURL
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		g := &githubClient{goghClient: goghClient, parentContext: func() context.Context { return ctx }}
+		if _, err := g.GetIssue("test-owner", "test-repo", 1); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected error wrapping %v; got %v", context.Canceled, err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		g := &githubClient{goghClient: goghClient, timeout: 10 * time.Millisecond}
+		if _, err := g.GetIssue("test-owner", "test-repo", 1); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected error wrapping %v; got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
+
+func TestListTimeline(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/test-owner/test-repo/issues/1/timeline" {
+			t.Errorf("Unexpected request %s", r.URL.Path)
+			return
+		}
+
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2>; rel="next"`, server.URL, r.URL.Path))
+			fmt.Fprint(w, `[{"id": 1, "event": "labeled"}, {"id": 2, "event": "closed"}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"id": 3, "event": "reopened"}]`)
+	}))
+	defer server.Close()
+
+	goghClient := gogh.NewClient(server.Client())
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("parsing server URL: %v", err)
+	}
+	goghClient.BaseURL = baseURL
+
+	g := &githubClient{goghClient: goghClient}
+
+	events, err := g.ListTimeline("test-owner", "test-repo", 1)
+	if err != nil {
+		t.Fatalf("ListTimeline() returned error: %v", err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("Expected the events of both pages; got %d events", len(events))
+	}
+	for i, event := range events {
+		if event.GetID() != int64(i+1) {
+			t.Fatalf("Expected event %d to have ID %d; got %d", i, i+1, event.GetID())
+		}
+	}
+}
+
+func TestGetClosingPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/test-owner/test-repo/issues/1/timeline":
+			fmt.Fprint(w, `[
+				{"id": 1, "event": "closed", "commit_id": "aaa"},
+				{"id": 2, "event": "reopened"},
+				{"id": 3, "event": "closed", "commit_id": "bbb"}
+			]`)
+		case "/repos/test-owner/test-repo/issues/2/timeline":
+			fmt.Fprint(w, `[{"id": 4, "event": "closed"}]`)
+		case "/repos/test-owner/test-repo/commits/bbb/pulls":
+			fmt.Fprint(w, `[
+				{"number": 4, "state": "closed"},
+				{"number": 5, "state": "closed", "merged_at": "2023-01-01T10:00:00Z"}
+			]`)
+		default:
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	goghClient := gogh.NewClient(server.Client())
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("parsing server URL: %v", err)
+	}
+	goghClient.BaseURL = baseURL
+
+	g := &githubClient{goghClient: goghClient}
+
+	pr, err := g.GetClosingPullRequest("test-owner", "test-repo", 1)
+	if err != nil {
+		t.Fatalf("GetClosingPullRequest() returned error: %v", err)
+	}
+	if pr.GetNumber() != 5 {
+		t.Fatalf("Expected the merged pull request of the last closing commit, #5; got %+v", pr)
+	}
+
+	// Issues closed by hand have no closing commit.
+	pr, err = g.GetClosingPullRequest("test-owner", "test-repo", 2)
+	if err != nil {
+		t.Fatalf("GetClosingPullRequest() returned error: %v", err)
+	}
+	if pr != nil {
+		t.Fatalf("Expected no pull request; got #%d", pr.GetNumber())
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	reset := now.Add(30 * time.Second)
+
+	tests := []struct {
+		name      string
+		remaining string
+		expected  time.Time
+	}{
+		{name: "below the buffer", remaining: "5", expected: reset},
+		{name: "at the buffer", remaining: "10", expected: now},
+		{name: "no rate limit headers", remaining: "", expected: now},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.remaining != "" {
+					w.Header().Set(headerRateRemaining, tc.remaining)
+					w.Header().Set(headerRateReset, fmt.Sprint(reset.Unix()))
+				}
+				fmt.Fprint(w, `{}`)
+			}))
+			defer server.Close()
+
+			fake := clock.NewFake(now)
+			transport := &rateLimitTransport{
+				base:   server.Client().Transport,
+				buffer: 10,
+				clock:  fake,
+			}
+			client := &http.Client{Transport: transport}
+
+			res, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("Get() returned error: %v", err)
+			}
+			res.Body.Close()
+
+			if !fake.Now().Equal(now) {
+				t.Fatalf("Expected the response not to wait; waited until %v", fake.Now())
+			}
+
+			// The wait is before the next request.
+			if err := transport.wait(context.Background()); err != nil {
+				t.Fatalf("wait() returned error: %v", err)
+			}
+			if !fake.Now().Equal(tc.expected) {
+				t.Fatalf("Expected to wait until %v; waited until %v", tc.expected, fake.Now())
+			}
+		})
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		status   int
+		calls    int
+	}{
+		{
+			name:     "server errors",
+			statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable},
+			status:   http.StatusOK,
+			calls:    3,
+		},
+		{
+			name:     "client error",
+			statuses: []int{http.StatusNotFound},
+			status:   http.StatusNotFound,
+			calls:    1,
+		},
+		{
+			// The request is retried until the timeout.
+			name:     "persistent server error",
+			statuses: repeat(http.StatusInternalServerError, 1000),
+			status:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("reading request body: %v", err)
+				}
+				bodies = append(bodies, string(body))
+
+				if len(bodies) <= len(tc.statuses) {
+					w.WriteHeader(tc.statuses[len(bodies)-1])
+				}
+				fmt.Fprint(w, `{"number": 1}`)
+			}))
+			defer server.Close()
+
+			transport := &retryTransport{
+				base:    server.Client().Transport,
+				retry:   synchttp.Retry{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond},
+				timeout: 50 * time.Millisecond,
+			}
+			client := &http.Client{Transport: transport}
+
+			res, err := client.Post(server.URL, "application/json", strings.NewReader(`{"state": "closed"}`))
+			if err != nil {
+				t.Fatalf("Post() returned error: %v", err)
+			}
+			res.Body.Close()
+
+			if res.StatusCode != tc.status {
+				t.Fatalf("Expected status %d; got %d", tc.status, res.StatusCode)
+			}
+			if tc.calls != 0 && len(bodies) != tc.calls {
+				t.Fatalf("Expected %d requests; got %d", tc.calls, len(bodies))
+			}
+			if tc.calls == 0 && len(bodies) < 2 {
+				t.Fatalf("Expected the request to be retried until the timeout; got %d requests", len(bodies))
+			}
+			for i, body := range bodies {
+				if body != `{"state": "closed"}` {
+					t.Fatalf("Expected request %d to send the body again; got %q", i+1, body)
+				}
+			}
+		})
+	}
+}
+
+// repeat returns a slice of n times the status.
+func repeat(status, n int) []int {
+	statuses := make([]int, n)
+	for i := range statuses {
+		statuses[i] = status
+	}
+	return statuses
+}
+
+// sleepClock is a Clock whose waits last for d, whatever the duration waited.
+type sleepClock struct {
+	d time.Duration
+}
+
+func (c sleepClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c sleepClock) After(time.Duration) <-chan time.Time {
+	return time.After(c.d)
+}
+
+func TestRateLimitWaitIsNotBoundByTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRateRemaining, "5")
+		w.Header().Set(headerRateReset, fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+		fmt.Fprint(w, `{"number": 1}`)
+	}))
+	defer server.Close()
+
+	newClient := func(ctx context.Context) *githubClient {
+		rateLimit := &rateLimitTransport{
+			base:   server.Client().Transport,
+			buffer: 10,
+			clock:  sleepClock{d: 100 * time.Millisecond},
+		}
+		goghClient := gogh.NewClient(&http.Client{Transport: rateLimit})
+		baseURL, err := url.Parse(server.URL + "/")
+		if err != nil {
+			t.Fatalf("parsing server URL: %v", err)
+		}
+		goghClient.BaseURL = baseURL
+
+		// The wait for the rate limit to reset is longer than the timeout.
+		return &githubClient{
+			goghClient:    goghClient,
+			parentContext: func() context.Context { return ctx },
+			timeout:       20 * time.Millisecond,
+			rateLimit:     rateLimit,
+		}
+	}
+
+	t.Run("waits", func(t *testing.T) {
+		g := newClient(context.Background())
+		if _, err := g.GetIssue("test-owner", "test-repo", 1); err != nil {
+			t.Fatalf("GetIssue() returned error: %v", err)
+		}
+
+		start := time.Now()
+		if _, err := g.GetIssue("test-owner", "test-repo", 1); err != nil {
+			t.Fatalf("Expected the request after the wait to succeed; got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+			t.Fatalf("Expected the request to wait for the rate limit to reset; it returned after %v", elapsed)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		g := newClient(ctx)
+		if _, err := g.GetIssue("test-owner", "test-repo", 1); err != nil {
+			t.Fatalf("GetIssue() returned error: %v", err)
+		}
+
+		time.AfterFunc(10*time.Millisecond, cancel)
+		if _, err := g.GetIssue("test-owner", "test-repo", 1); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected error wrapping %v; got %v", context.Canceled, err)
+		}
+	})
+}
+
+func TestListIssues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/test-owner/test-repo/issues" {
+			t.Errorf("Unexpected request %s", r.URL.Path)
+			return
+		}
+
+		expected := url.Values{
+			"per_page":  {"100"},
+			"state":     {"open"},
+			"since":     {"2023-06-01T12:00:00Z"},
+			"sort":      {"updated"},
+			"direction": {"asc"},
+		}
+		if query := r.URL.Query(); !reflect.DeepEqual(query, expected) {
+			t.Errorf("Expected query %v; got %v", expected, query)
+		}
+
+		fmt.Fprint(w, `[{"id": 1}, {"id": 2, "pull_request": {"url": "https://example.com/pulls/2"}}]`)
+	}))
+	defer server.Close()
+
+	goghClient := gogh.NewClient(server.Client())
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("parsing server URL: %v", err)
+	}
+	goghClient.BaseURL = baseURL
+
+	g := &githubClient{goghClient: goghClient}
+
+	issues, err := g.ListIssues("test-owner", "test-repo", ListIssuesOptions{
+		State:     "open",
+		Since:     time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC),
+		Sort:      "updated",
+		Direction: "asc",
+	})
+	if err != nil {
+		t.Fatalf("ListIssues() returned error: %v", err)
+	}
+
+	if len(issues) != 1 || issues[0].GetID() != 1 {
+		t.Fatalf("Expected only issue 1, excluding the pull request; got %v", issues)
+	}
+}
+
+func TestListIssuesWithoutSince(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Has("since") {
+			t.Errorf("Expected no since parameter for a zero Since; got %q", query.Get("since"))
+		}
+		if state := query.Get("state"); state != "all" {
+			t.Errorf("Expected issues of any state to be listed; got state %q", state)
+		}
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	goghClient := gogh.NewClient(server.Client())
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("parsing server URL: %v", err)
+	}
+	goghClient.BaseURL = baseURL
+
+	g := &githubClient{goghClient: goghClient}
+
+	if _, err := g.ListIssues("test-owner", "test-repo", ListIssuesOptions{}); err != nil {
+		t.Fatalf("ListIssues() returned error: %v", err)
+	}
+}
+
+func TestAppTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating private key: %v", err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		t.Fatalf("writing private key: %v", err)
+	}
+
+	// The first token is already expired, so that the second call to Token
+	// has to renew it.
+	expiries := []time.Time{time.Now().Add(-time.Minute), time.Now().Add(time.Hour)}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/app/installations/42/access_tokens" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+
+		signed := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		claims := &jwt.RegisteredClaims{}
+		if _, err := jwt.ParseWithClaims(signed, claims, func(*jwt.Token) (interface{}, error) {
+			return &key.PublicKey, nil
+		}); err != nil {
+			t.Errorf("parsing JWT: %v", err)
+		}
+		if claims.Issuer != "7" {
+			t.Errorf("Expected JWT issued by app 7; got %q", claims.Issuer)
+		}
+
+		fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, requests, expiries[requests].Format(time.RFC3339))
+		requests++
+	}))
+	defer server.Close()
+
+	source, err := newAppTokenSource(7, 42, keyPath)
+	if err != nil {
+		t.Fatalf("newAppTokenSource() returned error: %v", err)
+	}
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("parsing server URL: %v", err)
+	}
+	source.client.BaseURL = baseURL
+
+	ts := oauth2.ReuseTokenSource(nil, source)
+	for i, expected := range []string{"ghs_0", "ghs_1", "ghs_1"} {
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatalf("Token() returned error: %v", err)
+		}
+		if token.AccessToken != expected {
+			t.Fatalf("Expected token %d to be %s; got %s", i, expected, token.AccessToken)
+		}
+	}
+
+	if requests != 2 {
+		t.Fatalf("Expected 2 installation tokens to be created; got %d", requests)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package github
+
+import (
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+)
+
+// GitHubClientMock is a Client whose behaviour is provided by its function
+// fields, for use in tests. Methods whose function field is nil return
+// empty results.
+//
+//nolint:revive // GitHubClientMock mirrors jira.JiraClientMock
+type GitHubClientMock struct {
+	ListIssuesFn   func(owner, repo string, opts ListIssuesOptions) ([]*gogh.Issue, error)
+	ListCommentsFn func(owner, repo string, issue *gogh.Issue, since time.Time) ([]*gogh.IssueComment, error)
+	GetUserFn      func(login string) (*gogh.User, error)
+	GetIssueFn     func(owner, repo string, number int) (*gogh.Issue, error)
+	EditIssueFn    func(owner, repo string, number int, req *gogh.IssueRequest) (*gogh.Issue, error)
+	ListTimelineFn func(owner, repo string, number int) ([]*gogh.Timeline, error)
+
+	GetClosingPullRequestFn func(owner, repo string, number int) (*gogh.PullRequest, error)
+	GetRepositoryFn         func(owner, repo string) (*gogh.Repository, error)
+}
+
+// ListIssues calls ListIssuesFn.
+func (m *GitHubClientMock) ListIssues(owner, repo string, opts ListIssuesOptions) ([]*gogh.Issue, error) {
+	if m.ListIssuesFn == nil {
+		return nil, nil
+	}
+	return m.ListIssuesFn(owner, repo, opts)
+}
+
+// ListComments calls ListCommentsFn.
+func (m *GitHubClientMock) ListComments(
+	owner, repo string, issue *gogh.Issue, since time.Time,
+) ([]*gogh.IssueComment, error) {
+	if m.ListCommentsFn == nil {
+		return nil, nil
+	}
+	return m.ListCommentsFn(owner, repo, issue, since)
+}
+
+// GetUser calls GetUserFn. If GetUserFn is nil, it returns a user with only
+// the login set.
+func (m *GitHubClientMock) GetUser(login string) (*gogh.User, error) {
+	if m.GetUserFn == nil {
+		return &gogh.User{Login: gogh.String(login)}, nil
+	}
+	return m.GetUserFn(login)
+}
+
+// GetIssue calls GetIssueFn.
+func (m *GitHubClientMock) GetIssue(owner, repo string, number int) (*gogh.Issue, error) {
+	if m.GetIssueFn == nil {
+		return nil, nil
+	}
+	return m.GetIssueFn(owner, repo, number)
+}
+
+// EditIssue calls EditIssueFn. If EditIssueFn is nil, it returns an issue
+// with only the number and body set.
+func (m *GitHubClientMock) EditIssue(owner, repo string, number int, req *gogh.IssueRequest) (*gogh.Issue, error) {
+	if m.EditIssueFn == nil {
+		return &gogh.Issue{Number: gogh.Int(number), Body: req.Body}, nil
+	}
+	return m.EditIssueFn(owner, repo, number, req)
+}
+
+// ListTimeline calls ListTimelineFn.
+func (m *GitHubClientMock) ListTimeline(owner, repo string, number int) ([]*gogh.Timeline, error) {
+	if m.ListTimelineFn == nil {
+		return nil, nil
+	}
+	return m.ListTimelineFn(owner, repo, number)
+}
+
+// GetClosingPullRequest calls GetClosingPullRequestFn.
+func (m *GitHubClientMock) GetClosingPullRequest(owner, repo string, number int) (*gogh.PullRequest, error) {
+	if m.GetClosingPullRequestFn == nil {
+		return nil, nil
+	}
+	return m.GetClosingPullRequestFn(owner, repo, number)
+}
+
+// GetRepository calls GetRepositoryFn. If GetRepositoryFn is nil, it returns
+// a repository with only the full name set.
+func (m *GitHubClientMock) GetRepository(owner, repo string) (*gogh.Repository, error) {
+	if m.GetRepositoryFn == nil {
+		return &gogh.Repository{FullName: gogh.String(owner + "/" + repo)}, nil
+	}
+	return m.GetRepositoryFn(owner, repo)
+}
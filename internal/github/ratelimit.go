@@ -0,0 +1,127 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/clock"
+)
+
+const (
+	headerRateRemaining = "X-RateLimit-Remaining"
+	headerRateReset     = "X-RateLimit-Reset"
+)
+
+// rateLimitTransport is an http.RoundTripper which records when the rate
+// limit of the GitHub API resets once fewer than buffer requests remain, so
+// that long synchronizations wait for it before their next request instead
+// of being rate limited.
+type rateLimitTransport struct {
+	base   http.RoundTripper
+	buffer int
+	clock  clock.Clock
+
+	// mu guards remaining and reset, which are only set while fewer than
+	// buffer requests remain.
+	mu        sync.Mutex
+	remaining int
+	reset     time.Time
+}
+
+// RoundTrip performs the request with the base transport, and records the
+// reset time of the rate limit if the response leaves fewer than buffer
+// requests.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return res, err //nolint:wrapcheck
+	}
+
+	remaining, reset, ok := rateLimit(res)
+	if !ok {
+		return res, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if remaining >= t.buffer {
+		t.reset = time.Time{}
+		return res, nil
+	}
+	t.remaining, t.reset = remaining, reset
+
+	return res, nil
+}
+
+// wait waits until the rate limit resets, if the last response left fewer
+// than buffer requests. The wait is bounded by ctx rather than by the
+// timeout of a single request, as the reset may be up to an hour away; it
+// returns the error of ctx if ctx is done first.
+func (t *rateLimitTransport) wait(ctx context.Context) error {
+	t.mu.Lock()
+	remaining, reset := t.remaining, t.reset
+	t.mu.Unlock()
+
+	wait := reset.Sub(t.clock.Now())
+	if reset.IsZero() || wait <= 0 {
+		return nil
+	}
+
+	log.Infof(
+		"Only %d GitHub API requests remain before the rate limit; waiting %v until it resets",
+		remaining,
+		wait.Round(time.Second),
+	)
+	select {
+	case <-t.clock.After(wait):
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	}
+
+	t.mu.Lock()
+	if t.reset.Equal(reset) {
+		t.reset = time.Time{}
+	}
+	t.mu.Unlock()
+
+	return nil
+}
+
+// rateLimit returns the number of remaining requests and the reset time of
+// the rate limit of a GitHub API response. The boolean is false if the
+// response has no rate limit headers.
+func rateLimit(res *http.Response) (int, time.Time, bool) {
+	remaining, err := strconv.Atoi(res.Header.Get(headerRateRemaining))
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	reset, err := strconv.ParseInt(res.Header.Get(headerRateReset), 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	return remaining, time.Unix(reset, 0), true
+}
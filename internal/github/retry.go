@@ -0,0 +1,99 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	log "github.com/sirupsen/logrus"
+
+	synchttp "github.com/uwu-tools/gh-jira-issue-sync/internal/http"
+)
+
+// errServerError is returned by an attempt of a request which the GitHub
+// API answered with a server error.
+var errServerError = errors.New("GitHub API server error")
+
+// retryTransport is an http.RoundTripper which retries the requests failing
+// with a network error or a server error, with the exponential backoff
+// configured by retry, until timeout is reached or the context of the
+// request is done. Client errors, including rate limits, are returned right
+// away.
+type retryTransport struct {
+	base    http.RoundTripper
+	retry   synchttp.Retry
+	timeout time.Duration
+}
+
+// RoundTrip performs the request with the base transport, retrying it while
+// it fails. The response of the last attempt is returned if it got one, so
+// that the failure is reported by the GitHub client. Requests whose body
+// can't be read again are not retried.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return t.base.RoundTrip(req) //nolint:wrapcheck
+	}
+
+	var res *http.Response
+	attempts := 0
+	op := func() error {
+		// The response of the failed attempt is superseded by this one.
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+			res = nil
+		}
+
+		attempt := req
+		if attempts > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return backoff.Permanent(err)
+			}
+			attempt = req.Clone(req.Context())
+			attempt.Body = body
+		}
+		attempts++
+
+		var err error
+		res, err = t.base.RoundTrip(attempt)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		if res.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%w: %s", errServerError, res.Status)
+		}
+		return nil
+	}
+
+	b := backoff.WithContext(synchttp.NewExponentialBackOff(t.retry, t.timeout), req.Context())
+	err := backoff.RetryNotify(op, b, func(err error, wait time.Duration) {
+		log.Warnf(
+			"GitHub request %s %s failed; retrying in %v: %v",
+			req.Method, req.URL.Path, wait.Round(time.Millisecond), err,
+		)
+	})
+	if res != nil {
+		return res, nil
+	}
+	return nil, err //nolint:wrapcheck
+}
@@ -17,7 +17,11 @@
 package http
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -27,39 +31,100 @@ import (
 
 const retryBackoffRoundRatio = time.Millisecond / time.Nanosecond
 
+// Retry configures the exponential backoff between the retries of a failed
+// request. Zero fields keep the defaults of the backoff package.
+type Retry struct {
+	// InitialInterval is the wait before the first retry.
+	InitialInterval time.Duration
+	// MaxInterval is the longest wait between two retries.
+	MaxInterval time.Duration
+	// Multiplier is the factor the wait grows by after each retry.
+	Multiplier float64
+}
+
+// NewExponentialBackOff returns the exponential backoff configured by retry,
+// which stops retrying after timeout.
+func NewExponentialBackOff(retry Retry, timeout time.Duration) *backoff.ExponentialBackOff {
+	b := backoff.NewExponentialBackOff()
+	if retry.InitialInterval > 0 {
+		b.InitialInterval = retry.InitialInterval
+	}
+	if retry.MaxInterval > 0 {
+		b.MaxInterval = retry.MaxInterval
+	}
+	if retry.Multiplier > 0 {
+		b.Multiplier = retry.Multiplier
+	}
+	b.MaxElapsedTime = timeout
+
+	return b
+}
+
 // NewJiraRequest takes an API function from the Jira library and calls it with
 // exponential backoff. If the function succeeds, it returns the expected value
 // and the Jira API response, as well as a nil error. If it continues to fail
 // until a maximum time is reached, it returns a nil result as well as the
-// returned HTTP response and a timeout error.
+// returned HTTP response and a timeout error. The waits between attempts are
+// configured by retry. Once ctx is done, the request is no longer retried,
+// and the error of ctx is returned.
+//
+// A Retry-After header on a failed response is honored in place of the next
+// backoff interval, but never waits longer than maxRetryAfter. A zero
+// maxRetryAfter ignores Retry-After headers. Client errors other than 429 Too
+// Many Requests are not retried. Those waits are logged at info
+// level if logWaits is true, and at debug level otherwise.
 func NewJiraRequest(
+	ctx context.Context,
 	f func() (interface{}, *jira.Response, error),
 	timeout time.Duration,
+	retry Retry,
+	maxRetryAfter time.Duration,
+	logWaits bool,
 ) (interface{}, *jira.Response, error) {
 	var ret interface{}
 	var res *jira.Response
 
+	b := &retryAfterBackOff{
+		ExponentialBackOff: NewExponentialBackOff(retry, timeout),
+		max:                maxRetryAfter,
+		logWaits:           logWaits,
+	}
+
 	op := func() error {
+		// The response of the failed attempt is superseded by this one.
+		CloseBody(res)
+
 		var err error
 		ret, res, err = f()
+		if err != nil && res != nil {
+			b.wait, b.ok = RetryAfter(res.Response, time.Now())
+			b.status = res.StatusCode
+
+			// A client error fails the same way when retried, except
+			// when rate limited, so it is returned right away for the
+			// caller to handle.
+			if res.StatusCode >= 400 && res.StatusCode < 500 && res.StatusCode != http.StatusTooManyRequests {
+				return backoff.Permanent(err)
+			}
+		}
 		return err
 	}
 
-	backoffErr := retryNotify(op, timeout)
+	backoffErr := retryNotify(op, backoff.WithContext(b, ctx))
 	if backoffErr != nil {
+		// The body of the failed response is left for the caller to read.
 		return ret, res, errBackoff(backoffErr)
 	}
 
+	CloseBody(res)
+
 	return ret, res, nil
 }
 
 func retryNotify(
 	op backoff.Operation,
-	timeout time.Duration,
+	b backoff.BackOff,
 ) error {
-	b := backoff.NewExponentialBackOff()
-	b.MaxElapsedTime = timeout
-
 	err := backoff.RetryNotify(
 		op,
 		b,
@@ -78,6 +143,99 @@ func retryNotify(
 	return nil
 }
 
+// retryAfterBackOff is an exponential backoff which waits for the interval
+// requested by the last failed response instead, when there is one.
+type retryAfterBackOff struct {
+	*backoff.ExponentialBackOff
+
+	// max is the longest honored Retry-After interval.
+	max time.Duration
+
+	// logWaits logs the Retry-After waits at info level instead of debug.
+	logWaits bool
+
+	// wait is the interval requested by the last failed response, if ok,
+	// and status is the status of that response.
+	wait   time.Duration
+	ok     bool
+	status int
+}
+
+// NextBackOff returns the clamped Retry-After interval of the last failed
+// response, if any, and the next exponential backoff interval otherwise. It
+// stops once the maximum elapsed time is reached either way.
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.ExponentialBackOff.NextBackOff()
+	if next == backoff.Stop || !b.ok || b.max <= 0 {
+		return next
+	}
+
+	b.ok = false
+	if b.wait > b.max {
+		log.Warnf("Server requested a retry after %v; waiting for at most %v", b.wait, b.max)
+		return b.max
+	}
+
+	logf := log.Debugf
+	if b.logWaits {
+		logf = log.Infof
+	}
+	logf(
+		"Rate limited by the server (%d %s); waiting %v before retrying",
+		b.status,
+		http.StatusText(b.status),
+		b.wait,
+	)
+
+	return b.wait
+}
+
+// RetryAfter returns the interval requested by the Retry-After header of a
+// rate limited or unavailable response, relative to now. The boolean is false
+// if the response does not request one.
+func RetryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+	if res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	header := res.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(header)
+	if err != nil {
+		log.Debugf("Ignoring malformed Retry-After header %q", header)
+		return 0, false
+	}
+	if wait := date.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
 func errBackoff(e error) error {
 	return fmt.Errorf("backoff error: %w", e)
 }
+
+// CloseBody drains and closes the body of a Jira API response, if any. The
+// Jira client leaves the body of responses it doesn't decode open, and a
+// connection is only reused for the next request once the body of its
+// response was read to the end.
+func CloseBody(res *jira.Response) {
+	if res == nil || res.Response == nil || res.Body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+}
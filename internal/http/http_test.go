@@ -0,0 +1,271 @@
+// Copyright 2022 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	jira "github.com/uwu-tools/go-jira/v2/cloud"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2023, time.January, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		status   int
+		header   string
+		expected time.Duration
+		ok       bool
+	}{
+		{name: "seconds", status: http.StatusTooManyRequests, header: "120", expected: 2 * time.Minute, ok: true},
+		{name: "date", status: http.StatusServiceUnavailable, header: "Sun, 01 Jan 2023 10:00:30 GMT", expected: 30 * time.Second, ok: true},
+		{name: "past date", status: http.StatusTooManyRequests, header: "Sun, 01 Jan 2023 09:00:00 GMT", expected: 0, ok: true},
+		{name: "no header", status: http.StatusTooManyRequests, header: "", ok: false},
+		{name: "malformed", status: http.StatusTooManyRequests, header: "soon", ok: false},
+		{name: "not rate limited", status: http.StatusBadRequest, header: "120", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.header != "" {
+				res.Header.Set("Retry-After", tt.header)
+			}
+
+			wait, ok := RetryAfter(res, now)
+			if ok != tt.ok || wait != tt.expected {
+				t.Fatalf("Expected (%v, %v); got (%v, %v)", tt.expected, tt.ok, wait, ok)
+			}
+		})
+	}
+}
+
+func TestNewJiraRequestClampsRetryAfter(t *testing.T) {
+	errRateLimited := errors.New("rate limited")
+
+	calls := 0
+	f := func() (interface{}, *jira.Response, error) {
+		calls++
+		if calls == 1 {
+			res := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+			res.Header.Set("Retry-After", "86400")
+			return nil, &jira.Response{Response: res}, errRateLimited
+		}
+		return "ok", nil, nil
+	}
+
+	start := time.Now()
+	ret, _, err := NewJiraRequest(context.Background(), f, time.Minute, Retry{}, 10*time.Millisecond, false)
+	if err != nil {
+		t.Fatalf("NewJiraRequest() returned error: %v", err)
+	}
+	if ret != "ok" || calls != 2 {
+		t.Fatalf("Expected the request to succeed on its second call; got %v after %d calls", ret, calls)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Expected the Retry-After wait to be clamped; waited %v", elapsed)
+	}
+}
+
+func TestNewJiraRequestLogsRateLimitWaits(t *testing.T) {
+	errRateLimited := errors.New("rate limited")
+
+	tests := []struct {
+		name     string
+		logWaits bool
+		level    log.Level
+	}{
+		{name: "info", logWaits: true, level: log.InfoLevel},
+		{name: "debug", logWaits: false, level: log.DebugLevel},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hook := logtest.NewGlobal()
+			defer hook.Reset()
+			level := log.GetLevel()
+			log.SetLevel(log.DebugLevel)
+			defer log.SetLevel(level)
+
+			calls := 0
+			f := func() (interface{}, *jira.Response, error) {
+				calls++
+				if calls == 1 {
+					res := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+					res.Header.Set("Retry-After", "0")
+					return nil, &jira.Response{Response: res}, errRateLimited
+				}
+				return "ok", nil, nil
+			}
+
+			if _, _, err := NewJiraRequest(context.Background(), f, time.Minute, Retry{}, time.Minute, tc.logWaits); err != nil {
+				t.Fatalf("NewJiraRequest() returned error: %v", err)
+			}
+
+			for _, entry := range hook.AllEntries() {
+				if entry.Message == "Rate limited by the server (429 Too Many Requests); waiting 0s before retrying" {
+					if entry.Level != tc.level {
+						t.Fatalf("Expected the wait to be logged at %v; got %v", tc.level, entry.Level)
+					}
+					return
+				}
+			}
+			t.Fatalf("Expected the wait to be logged; got %d entries", len(hook.AllEntries()))
+		})
+	}
+}
+
+func TestNewJiraRequestDoesNotRetryClientErrors(t *testing.T) {
+	errRequest := errors.New("request failed")
+
+	tests := []struct {
+		status        int
+		expectedCalls int
+	}{
+		{status: http.StatusBadRequest, expectedCalls: 1},
+		{status: http.StatusNotFound, expectedCalls: 1},
+		{status: http.StatusTooManyRequests, expectedCalls: 2},
+		{status: http.StatusInternalServerError, expectedCalls: 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			calls := 0
+			f := func() (interface{}, *jira.Response, error) {
+				calls++
+				if calls == 1 {
+					res := &http.Response{StatusCode: tc.status, Header: http.Header{}}
+					return nil, &jira.Response{Response: res}, errRequest
+				}
+				return "ok", nil, nil
+			}
+
+			retry := Retry{InitialInterval: time.Millisecond}
+			_, _, err := NewJiraRequest(context.Background(), f, time.Minute, retry, time.Minute, false)
+			if calls != tc.expectedCalls {
+				t.Fatalf("Expected %d calls; got %d", tc.expectedCalls, calls)
+			}
+			if tc.expectedCalls == 1 && !errors.Is(err, errRequest) {
+				t.Fatalf("Expected error wrapping %v; got %v", errRequest, err)
+			}
+		})
+	}
+}
+
+func TestNewJiraRequestStopsRetryingOnceContextIsDone(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	f := func() (interface{}, *jira.Response, error) {
+		calls++
+		cancel()
+		return nil, nil, errUnavailable
+	}
+
+	_, _, err := NewJiraRequest(ctx, f, time.Minute, Retry{InitialInterval: time.Millisecond}, 0, false)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected error wrapping %v; got %v", context.Canceled, err)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected the request not to be retried; got %d calls", calls)
+	}
+}
+
+func TestNewJiraRequestReusesConnections(t *testing.T) {
+	// A body which the Jira client does not decode, nor close. It is
+	// larger than what the transport discards by itself on close, so that
+	// the connection is only reused if the body is drained.
+	body := `{"warnings": ["` + strings.Repeat("x", 1<<20) + `"]}`
+
+	// The connections are counted by the remote address of the requests,
+	// which the server knows by the time the handler runs, unlike the
+	// state of its connections.
+	var mu sync.Mutex
+	connections := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		connections[r.RemoteAddr] = true
+		mu.Unlock()
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	client, err := jira.NewClient(server.URL, server.Client())
+	if err != nil {
+		t.Fatalf("creating Jira client: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, _, err := NewJiraRequest(context.Background(), func() (interface{}, *jira.Response, error) {
+			res, err := client.Issue.DoTransition(context.Background(), "TEST-1", "31")
+			return nil, res, err //nolint:wrapcheck
+		}, time.Second, Retry{}, 0, false)
+		if err != nil {
+			t.Fatalf("transitioning Jira issue: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(connections) != 1 {
+		t.Fatalf("Expected the requests to share a single connection; got %d connections", len(connections))
+	}
+}
+
+func TestNewExponentialBackOff(t *testing.T) {
+	b := NewExponentialBackOff(Retry{
+		InitialInterval: 10 * time.Millisecond,
+		MaxInterval:     40 * time.Millisecond,
+		Multiplier:      2,
+	}, time.Minute)
+	b.RandomizationFactor = 0
+	b.Reset()
+
+	var intervals []time.Duration
+	for i := 0; i < 4; i++ {
+		intervals = append(intervals, b.NextBackOff())
+	}
+
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond}
+	if !reflect.DeepEqual(intervals, expected) {
+		t.Fatalf("Expected backoff intervals %v; got %v", expected, intervals)
+	}
+	if b.MaxElapsedTime != time.Minute {
+		t.Fatalf("Expected the backoff to stop after the timeout; got %v", b.MaxElapsedTime)
+	}
+
+	defaults := NewExponentialBackOff(Retry{}, time.Minute)
+	if defaults.InitialInterval != backoff.DefaultInitialInterval ||
+		defaults.MaxInterval != backoff.DefaultMaxInterval ||
+		defaults.Multiplier != backoff.DefaultMultiplier {
+		t.Fatalf("Expected a zero Retry to keep the backoff defaults; got %+v", defaults)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"net/http"
+)
+
+// BearerTokenTransport is an http.RoundTripper that authenticates all
+// requests with a Jira Data Center personal access token, sent as a Bearer
+// token.
+type BearerTokenTransport struct {
+	Token string
+
+	// Transport is the underlying HTTP transport to use when making
+	// requests. It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// RoundTrip implements the RoundTripper interface. The request is cloned
+// before the Authorization header is set, as a RoundTripper must not modify
+// the request it is given.
+func (t *BearerTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req2 := req.Clone(req.Context())
+	req2.Header.Set("Authorization", "Bearer "+t.Token)
+
+	return t.transport().RoundTrip(req2) //nolint:wrapcheck
+}
+
+func (t *BearerTokenTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package comment
+
+import (
+	"fmt"
+	"regexp"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+// assigneeMarkerFormat is the format of the hidden anchor starting the Jira
+// comments which record the GitHub assignee of an issue. Its argument is the
+// login of the assignee, or an empty string if the issue is unassigned.
+const assigneeMarkerFormat = "{anchor:gh-assignee:%s}"
+
+// assigneeMarkerRegex matches the anchor written by assigneeMarkerFormat. Its
+// matching group is the login of the assignee.
+var assigneeMarkerRegex = regexp.MustCompile(`^\{anchor:gh-assignee:([A-Za-z0-9-]*)\}`)
+
+// LastKnownAssignee returns the login of the GitHub assignee recorded by the
+// most recent assignee comment on the Jira issue. The boolean is false if no
+// assignee was recorded yet.
+func LastKnownAssignee(jIssue *gojira.Issue) (string, bool) {
+	if jIssue.Fields == nil || jIssue.Fields.Comments == nil {
+		return "", false
+	}
+
+	comments := jIssue.Fields.Comments.Comments
+	for i := len(comments) - 1; i >= 0; i-- {
+		if matches := assigneeMarkerRegex.FindStringSubmatch(bodyText(comments[i].Body)); matches != nil {
+			return matches[1], true
+		}
+	}
+
+	return "", false
+}
+
+// CompareAssignee posts a Jira comment recording the GitHub assignee of the
+// issue if it changed since the last assignee comment. An issue which was
+// never assigned does not get a comment.
+func CompareAssignee(cfg *config.Config, ghIssue *gogh.Issue, jIssue *gojira.Issue, jClient jira.Client) error {
+	if !cfg.ShouldCommentAssigneeChanges() {
+		return nil
+	}
+
+	assignee := ghIssue.GetAssignee().GetLogin()
+
+	previous, ok := LastKnownAssignee(jIssue)
+	if previous == assignee && (ok || assignee == "") {
+		return nil
+	}
+
+	var body string
+	switch {
+	case assignee == "":
+		body = fmt.Sprintf("Unassigned from @%s on GitHub", previous)
+	case ok && previous != "":
+		body = fmt.Sprintf(
+			"Reassigned from @%s to [@%s|%s] on GitHub",
+			previous,
+			assignee,
+			ghIssue.GetAssignee().GetHTMLURL(),
+		)
+	default:
+		body = fmt.Sprintf("Assigned to [@%s|%s] on GitHub", assignee, ghIssue.GetAssignee().GetHTMLURL())
+	}
+	body = fmt.Sprintf(assigneeMarkerFormat, assignee) + body
+
+	if _, err := jClient.AddComment(jIssue, body); err != nil {
+		return fmt.Errorf("creating Jira assignee comment: %w", err)
+	}
+
+	log.Debugf("Recorded GitHub assignee %q on Jira issue %s", assignee, jIssue.Key)
+
+	return nil
+}
@@ -19,7 +19,10 @@ package comment
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	gogh "github.com/google/go-github/v56/github"
 	log "github.com/sirupsen/logrus"
@@ -28,6 +31,8 @@ import (
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/markup"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/metrics"
 )
 
 // jCommentRegex matches a generated Jira comment. It has matching groups to retrieve the
@@ -42,6 +47,84 @@ var jCommentRegex = regexp.MustCompile(
 // just their GitHub ID for matching.
 var jCommentIDRegex = regexp.MustCompile(`^Comment \[\(ID (\d+)\)\|`)
 
+// jCommentMarkerRegex matches the hidden anchor starting a generated Jira
+// comment. Its matching group is the GitHub comment ID. The header following
+// it is on a single line, so the body of the comment starts after the first
+// blank line. Comments generated before the anchor was introduced only have
+// the header matched by jCommentRegex.
+var jCommentMarkerRegex = regexp.MustCompile(`^\{anchor:gh-comment:(\d+)\}`)
+
+// jCommentADFIDRegex matches the beginning of the text of a generated Jira
+// comment which is an ADF document, which either starts with the historical
+// header or with the linked ID of the GitHub comment before a templated
+// header. Its matching group is the GitHub comment ID.
+var jCommentADFIDRegex = regexp.MustCompile(`^(?:Comment )?\(ID (\d+)\)`)
+
+// commentID returns the ID of the GitHub comment a generated Jira comment
+// was created from. The boolean is false if the Jira comment was not
+// generated.
+func commentID(body string) (int64, bool) {
+	var matches []string
+	if doc, ok := markup.ParseADF(body); ok {
+		matches = jCommentADFIDRegex.FindStringSubmatch(markup.ADFText(doc))
+	} else {
+		matches = jCommentMarkerRegex.FindStringSubmatch(body)
+	}
+	if matches == nil {
+		matches = jCommentIDRegex.FindStringSubmatch(body)
+	}
+	if matches == nil {
+		return 0, false
+	}
+
+	// matches[0] is the whole string, matches[1] is the ID
+	id, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// bodyText returns the text of the body of a Jira comment: the text of its
+// ADF document, as markup.ADFText returns it, or the body itself otherwise.
+func bodyText(body string) string {
+	if doc, ok := markup.ParseADF(body); ok {
+		return markup.ADFText(doc)
+	}
+	return body
+}
+
+// commentText returns the body of a generated Jira comment, without its
+// header. The boolean is false if the header can't be parsed. The text of an
+// ADF document is returned as markup.ADFText returns it; its header is the
+// first paragraph.
+func commentText(body string) (string, bool) {
+	if doc, ok := markup.ParseADF(body); ok {
+		text := markup.ADFText(doc)
+		if !jCommentADFIDRegex.MatchString(text) {
+			return "", false
+		}
+		// A comment without a body is only its header paragraph.
+		_, text, _ = strings.Cut(text, "\n\n")
+		return text, true
+	}
+
+	if jCommentMarkerRegex.MatchString(body) {
+		_, text, ok := strings.Cut(body, "\n\n")
+		return text, ok
+	}
+
+	// fields[0] is the whole body, 1 is the ID, 2 is the username, 3 is the real name (or "" if none)
+	// 4 is the date, and 5 is the real body
+	fields := jCommentRegex.FindStringSubmatch(body)
+	if len(fields) < 6 {
+		return "", false
+	}
+
+	return fields[5], true
+}
+
 // Compare takes a GitHub issue, and retrieves all of its comments. It then
 // matches each one to a comment in `existing`. If it finds a match, it calls
 // UpdateComment; if it doesn't, it calls CreateComment.
@@ -52,13 +135,21 @@ func Compare(
 	ghClient github.Client,
 	jClient jira.Client,
 ) error {
-	if ghIssue.GetComments() == 0 {
-		log.Debugf("Issue #%d has no comments, skipping.", *ghIssue.Number)
+	logger := cfg.IssueLogger(ghIssue.GetNumber(), jIssue.Key)
+
+	// The comment count reported by GitHub may lag behind right after a
+	// comment is posted, so it is not trusted on a full reconcile.
+	fullReconcile := cfg.IsFullReconcile()
+	if ghIssue.GetComments() == 0 && !fullReconcile {
+		logger.Debugf("Issue #%d has no comments, skipping.", *ghIssue.Number)
 		return nil
 	}
 
 	owner, repo := cfg.GetRepo()
 	since := cfg.GetSinceParam()
+	if fullReconcile {
+		since = time.Time{}
+	}
 	ghComments, err := ghClient.ListComments(
 		owner,
 		repo,
@@ -71,32 +162,31 @@ func Compare(
 
 	var jComments []*gojira.Comment
 	if jIssue.Fields.Comments == nil {
-		log.Debugf("Jira issue %s has no comments.", jIssue.Key)
+		logger.Debugf("Jira issue %s has no comments.", jIssue.Key)
 	} else {
 		jComments = jIssue.Fields.Comments.Comments
-		log.Debugf("Jira issue %s has %d comments", jIssue.Key, len(jComments))
+		logger.Debugf("Jira issue %s has %d comments", jIssue.Key, len(jComments))
 	}
 
+	// Comments are created in the order they were posted on GitHub, whatever
+	// the order they were listed in, so that they read chronologically in
+	// Jira.
+	sort.SliceStable(ghComments, func(i, j int) bool {
+		return ghComments[i].GetCreatedAt().Before(ghComments[j].GetCreatedAt().Time)
+	})
+
 	for _, ghComment := range ghComments {
+		ghComment = trimmed(cfg, ghComment)
+
 		found := false
 		for _, jComment := range jComments {
-			if !jCommentIDRegex.MatchString(jComment.Body) {
-				continue
-			}
-			// matches[0] is the whole string, matches[1] is the ID
-			matches := jCommentIDRegex.FindStringSubmatch(jComment.Body)
-			intID, err := strconv.Atoi(matches[1])
-			if err != nil {
-				return fmt.Errorf("converting comment ID to int: %w", err)
-			}
-
-			id := int64(intID)
-			if *ghComment.ID != id {
+			id, ok := commentID(jComment.Body)
+			if !ok || *ghComment.ID != id {
 				continue
 			}
 			found = true
 
-			err = UpdateComment(cfg, ghComment, jComment, jIssue, ghClient, jClient)
+			err := UpdateComment(cfg, ghComment, jComment, jIssue, ghClient, jClient)
 			if err != nil {
 				return err
 			}
@@ -111,16 +201,34 @@ func Compare(
 		if err != nil {
 			return fmt.Errorf("creating Jira comment: %w", err)
 		}
+		if !cfg.IsDryRun() {
+			metrics.CommentsCreated.Inc()
+		}
 
-		log.Debugf("Created Jira comment %s.", comment.ID)
+		logger.Debugf("Created Jira comment %s.", comment.ID)
 	}
 
-	log.Debugf("Copied comments from GH issue #%d to Jira issue %s.", *ghIssue.Number, jIssue.Key)
+	logger.Debugf("Copied comments from GH issue #%d to Jira issue %s.", *ghIssue.Number, jIssue.Key)
 	return nil
 }
 
+// jiraBody returns the body of the GitHub comment as the Jira client writes
+// it.
+func jiraBody(cfg *config.Config, ghComment *gogh.IssueComment) string {
+	if cfg.ShouldConvertMarkdown() {
+		return markup.ToJira(ghComment.GetBody())
+	}
+	if cfg.ShouldEscapeMarkup() {
+		return markup.Escape(ghComment.GetBody())
+	}
+	return ghComment.GetBody()
+}
+
 // UpdateComment compares the body of a GitHub comment with the body (minus header)
-// of the Jira comment, and updates the Jira comment if necessary.
+// of the Jira comment, and updates the Jira comment if necessary. The body of
+// the GitHub comment is compared as trimmed by TrimBody, and converted to
+// Jira wiki markup if `convert-markdown` is enabled, or escaped if
+// `escape-markup` is.
 func UpdateComment(
 	cfg *config.Config,
 	ghComment *gogh.IssueComment,
@@ -129,11 +237,25 @@ func UpdateComment(
 	ghClient github.Client,
 	jClient jira.Client,
 ) error {
-	// fields[0] is the whole body, 1 is the ID, 2 is the username, 3 is the real name (or "" if none)
-	// 4 is the date, and 5 is the real body
-	fields := jCommentRegex.FindStringSubmatch(jComment.Body)
+	ghComment = trimmed(cfg, ghComment)
 
-	if fields[5] == ghComment.GetBody() {
+	text, ok := commentText(jComment.Body)
+
+	// The comment header may have been edited in Jira, in which case the
+	// comment still carries the GitHub ID but the rest of it can't be
+	// parsed. Rewrite it from the GitHub comment, which restores the header.
+	if !ok {
+		log.Warnf(
+			"Jira comment %s on issue %s could not be parsed; rewriting it from GitHub comment %d",
+			jComment.ID,
+			jIssue.Key,
+			ghComment.GetID(),
+		)
+	} else if _, isADF := markup.ParseADF(jComment.Body); isADF {
+		if text == markup.ADFText(markup.ToADF(ghComment.GetBody())) {
+			return nil
+		}
+	} else if text == jiraBody(cfg, ghComment) {
 		return nil
 	}
 
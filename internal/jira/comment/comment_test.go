@@ -16,7 +16,20 @@
 
 package comment
 
-import "testing"
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
 
 //nolint:lll
 const testComment = `Comment [(ID 484163403)|https://github.com] from GitHub user [bilbo-baggins|https://github.com/bilbo-baggins] (Bilbo Baggins) at 16:27 PM, April 17 2019:
@@ -117,3 +130,478 @@ func TestJiraCommentRegexUnnamed(t *testing.T) {
 		t.Fatalf("Expected field[5] = rawr; Got field[5] = %s", fields[5])
 	}
 }
+
+func TestUpdateCommentUnparseableHeader(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	// The ID prefix is intact, but the header was edited by a human.
+	jComment := &gojira.Comment{
+		ID:   "10100",
+		Body: "Comment [(ID 484163403)|https://github.com] edited by hand",
+	}
+	if !jCommentIDRegex.MatchString(jComment.Body) || jCommentRegex.MatchString(jComment.Body) {
+		t.Fatal("Expected test comment to match only the ID regex")
+	}
+
+	ghComment := &gogh.IssueComment{
+		ID:   gogh.Int64(484163403),
+		Body: gogh.String("Bla blibidy bloo bla"),
+	}
+
+	updated := ""
+	jClient := &jira.JiraClientMock{
+		UpdateCommentFn: func(
+			issue *gojira.Issue, id string, comment *gogh.IssueComment, githubClient github.Client,
+		) (*gojira.Comment, error) {
+			updated = id
+			return &gojira.Comment{ID: id}, nil
+		},
+	}
+
+	jIssue := &gojira.Issue{Key: "TEST-1"}
+	if err := UpdateComment(cfg, ghComment, jComment, jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateComment() returned error: %v", err)
+	}
+
+	if updated != jComment.ID {
+		t.Fatalf("Expected Jira comment %s to be rewritten; got %q", jComment.ID, updated)
+	}
+}
+
+func TestCompareFullReconcileIgnoresCommentCount(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyFullReconcileEvery: 2,
+	})
+
+	// GitHub has not caught up with the comment count yet.
+	ghIssue := &gogh.Issue{Number: gogh.Int(1), Comments: gogh.Int(0)}
+
+	var listed []time.Time
+	ghClient := &github.GitHubClientMock{
+		ListCommentsFn: func(owner, repo string, issue *gogh.Issue, since time.Time) ([]*gogh.IssueComment, error) {
+			listed = append(listed, since)
+			return nil, nil
+		},
+	}
+
+	jIssue := &gojira.Issue{Key: "TEST-1", Fields: &gojira.IssueFields{}}
+	for run := 1; run <= 2; run++ {
+		cfg.StartRun()
+		if err := Compare(cfg, ghIssue, jIssue, ghClient, &jira.JiraClientMock{}); err != nil {
+			t.Fatalf("Compare() returned error: %v", err)
+		}
+	}
+
+	if len(listed) != 1 {
+		t.Fatalf("Expected comments to be listed on the full reconcile only; listed %d times", len(listed))
+	}
+	if !listed[0].IsZero() {
+		t.Fatalf("Expected full reconcile to list all comments; got since %v", listed[0])
+	}
+}
+
+func TestCompareAssigneeCommentsOnChangeOnly(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyAssigneeChangeComments: true,
+	})
+
+	jIssue := &gojira.Issue{
+		Key:    "TEST-1",
+		Fields: &gojira.IssueFields{Comments: &gojira.Comments{}},
+	}
+
+	var posted []string
+	jClient := &jira.JiraClientMock{
+		AddCommentFn: func(issue *gojira.Issue, body string) (*gojira.Comment, error) {
+			posted = append(posted, body)
+			jIssue.Fields.Comments.Comments = append(jIssue.Fields.Comments.Comments, &gojira.Comment{Body: body})
+			return &gojira.Comment{Body: body}, nil
+		},
+	}
+
+	steps := []struct {
+		assignee string
+		posts    int
+	}{
+		{assignee: "", posts: 0},
+		{assignee: "bilbo-baggins", posts: 1},
+		{assignee: "bilbo-baggins", posts: 1},
+		{assignee: "frodo-baggins", posts: 2},
+		{assignee: "", posts: 3},
+		{assignee: "", posts: 3},
+	}
+
+	for i, step := range steps {
+		ghIssue := &gogh.Issue{Number: gogh.Int(1)}
+		if step.assignee != "" {
+			ghIssue.Assignee = &gogh.User{Login: gogh.String(step.assignee)}
+		}
+
+		if err := CompareAssignee(cfg, ghIssue, jIssue, jClient); err != nil {
+			t.Fatalf("CompareAssignee() returned error: %v", err)
+		}
+		if len(posted) != step.posts {
+			t.Fatalf("Step %d: expected %d assignee comments; got %d: %v", i, step.posts, len(posted), posted)
+		}
+	}
+
+	if login, ok := LastKnownAssignee(jIssue); !ok || login != "" {
+		t.Fatalf("Expected the issue to be recorded as unassigned; got %q, %v", login, ok)
+	}
+}
+
+func TestTrimBodyStripsQuotes(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyCommentStripQuotes: true,
+		options.ConfigKeyCommentSeparator:   "-- ",
+	})
+
+	body := "> On Monday, Bilbo wrote:\r\n> Is this fixed?\r\n\r\nYes, in the last release.\r\n\r\n" +
+		"> And the other one?\r\n\r\nNot yet.\r\n\r\n-- \r\nFrodo Baggins\r\nThe Shire"
+
+	expected := "Yes, in the last release.\n\nNot yet."
+	if trimmedBody := TrimBody(cfg, body); trimmedBody != expected {
+		t.Fatalf("Expected trimmed body %q; got %q", expected, trimmedBody)
+	}
+	if again := TrimBody(cfg, expected); again != expected {
+		t.Fatalf("Expected trimming to be stable; got %q", again)
+	}
+
+	// A Jira comment written from the trimmed body is up to date.
+	ghComment := &gogh.IssueComment{ID: gogh.Int64(484163403), Body: gogh.String(body)}
+	jComment := &gojira.Comment{
+		ID: "10000",
+		Body: "Comment [(ID 484163403)|https://github.com] from GitHub user " +
+			"[bilbo-baggins|https://github.com/bilbo-baggins] (Bilbo Baggins) at 16:27 PM, April 17 2019:\n\n" +
+			expected,
+	}
+
+	jClient := &jira.JiraClientMock{
+		UpdateCommentFn: func(
+			issue *gojira.Issue, id string, comment *gogh.IssueComment, githubClient github.Client,
+		) (*gojira.Comment, error) {
+			t.Fatalf("Expected Jira comment not to be rewritten; got body %q", comment.GetBody())
+			return nil, nil
+		},
+	}
+
+	jIssue := &gojira.Issue{Key: "TEST-1", Fields: &gojira.IssueFields{}}
+	if err := UpdateComment(cfg, ghComment, jComment, jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateComment() returned error: %v", err)
+	}
+}
+
+func TestCompareTimelineDeduplicatesEvents(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeySyncTimeline: true,
+	})
+
+	at := &gogh.Timestamp{Time: time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+	actor := &gogh.User{Login: gogh.String("bilbo-baggins"), HTMLURL: gogh.String("https://github.com/bilbo-baggins")}
+	ghClient := &github.GitHubClientMock{
+		ListTimelineFn: func(owner, repo string, number int) ([]*gogh.Timeline, error) {
+			return []*gogh.Timeline{
+				{ID: gogh.Int64(1), Event: gogh.String("labeled"), Actor: actor, CreatedAt: at,
+					Label: &gogh.Label{Name: gogh.String("bug")}},
+				{ID: gogh.Int64(2), Event: gogh.String("subscribed"), Actor: actor, CreatedAt: at},
+				{ID: gogh.Int64(3), Event: gogh.String("closed"), Actor: actor, CreatedAt: at},
+				// Cross-references have no ID, so they can't be deduplicated.
+				{Event: gogh.String("cross-referenced"), Actor: actor, CreatedAt: at},
+			}, nil
+		},
+	}
+
+	jIssue := &gojira.Issue{
+		Key:    "TEST-1",
+		Fields: &gojira.IssueFields{Comments: &gojira.Comments{}},
+	}
+
+	var posted []string
+	jClient := &jira.JiraClientMock{
+		AddCommentFn: func(issue *gojira.Issue, body string) (*gojira.Comment, error) {
+			posted = append(posted, body)
+			jIssue.Fields.Comments.Comments = append(jIssue.Fields.Comments.Comments, &gojira.Comment{Body: body})
+			return &gojira.Comment{Body: body}, nil
+		},
+	}
+
+	ghIssue := &gogh.Issue{Number: gogh.Int(1)}
+	for run := 0; run < 2; run++ {
+		if err := CompareTimeline(cfg, ghIssue, jIssue, ghClient, jClient); err != nil {
+			t.Fatalf("CompareTimeline() returned error: %v", err)
+		}
+	}
+
+	expected := []string{
+		"{anchor:gh-event:1}GitHub user [bilbo-baggins|https://github.com/bilbo-baggins] " +
+			"added the label *bug* at 12:00 PM, June 1 2023",
+		"{anchor:gh-event:3}GitHub user [bilbo-baggins|https://github.com/bilbo-baggins] " +
+			"closed the issue at 12:00 PM, June 1 2023",
+	}
+	if !reflect.DeepEqual(posted, expected) {
+		t.Fatalf("Expected each event to be posted once:\n%q\nGot:\n%q", expected, posted)
+	}
+}
+
+func TestCompareMatchesTemplatedComments(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyCommentTemplate: "{{.Login}} wrote:",
+	})
+
+	ghIssue := &gogh.Issue{Number: gogh.Int(1), Comments: gogh.Int(2)}
+	ghClient := &github.GitHubClientMock{
+		ListCommentsFn: func(owner, repo string, issue *gogh.Issue, since time.Time) ([]*gogh.IssueComment, error) {
+			return []*gogh.IssueComment{
+				{ID: gogh.Int64(1), Body: gogh.String("Unchanged")},
+				{ID: gogh.Int64(2), Body: gogh.String("Edited on GitHub")},
+			}, nil
+		},
+	}
+
+	jIssue := &gojira.Issue{
+		Key: "TEST-1",
+		Fields: &gojira.IssueFields{Comments: &gojira.Comments{Comments: []*gojira.Comment{
+			{ID: "10001", Body: "{anchor:gh-comment:1}bilbo-baggins wrote:\n\nUnchanged"},
+			{ID: "10002", Body: "{anchor:gh-comment:2}bilbo-baggins wrote:\n\nOriginal"},
+		}}},
+	}
+
+	var updated, created []int64
+	jClient := &jira.JiraClientMock{
+		UpdateCommentFn: func(
+			issue *gojira.Issue, id string, comment *gogh.IssueComment, githubClient github.Client,
+		) (*gojira.Comment, error) {
+			updated = append(updated, comment.GetID())
+			return &gojira.Comment{ID: id}, nil
+		},
+		CreateCommentFn: func(
+			issue *gojira.Issue, comment *gogh.IssueComment, githubClient github.Client,
+		) (*gojira.Comment, error) {
+			created = append(created, comment.GetID())
+			return &gojira.Comment{}, nil
+		},
+	}
+
+	if err := Compare(cfg, ghIssue, jIssue, ghClient, jClient); err != nil {
+		t.Fatalf("Compare() returned error: %v", err)
+	}
+
+	if len(created) != 0 {
+		t.Fatalf("Expected templated comments to be matched, created %v", created)
+	}
+	if !reflect.DeepEqual(updated, []int64{2}) {
+		t.Fatalf("Expected only the edited comment to be updated, updated %v", updated)
+	}
+}
+
+func TestCompareMatchesEditedCommentsByMarker(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	ghIssue := &gogh.Issue{Number: gogh.Int(1), Comments: gogh.Int(2)}
+	ghClient := &github.GitHubClientMock{
+		ListCommentsFn: func(owner, repo string, issue *gogh.Issue, since time.Time) ([]*gogh.IssueComment, error) {
+			return []*gogh.IssueComment{
+				{ID: gogh.Int64(484163403), Body: gogh.String("Bla blibidy bloo bla")},
+				{ID: gogh.Int64(123456789), Body: gogh.String("rawr")},
+			}, nil
+		},
+	}
+
+	jIssue := &gojira.Issue{
+		Key: "TEST-1",
+		Fields: &gojira.IssueFields{Comments: &gojira.Comments{Comments: []*gojira.Comment{
+			// The header was edited by hand, but the marker is intact.
+			{ID: "10001", Body: "{anchor:gh-comment:484163403}Copied from GitHub by Bilbo\n\nBla blibidy bloo bla"},
+			// Comments created before the marker are matched by their header.
+			{ID: "10002", Body: testCommentUnnamed},
+		}}},
+	}
+
+	var updated, created []int64
+	jClient := &jira.JiraClientMock{
+		UpdateCommentFn: func(
+			issue *gojira.Issue, id string, comment *gogh.IssueComment, githubClient github.Client,
+		) (*gojira.Comment, error) {
+			updated = append(updated, comment.GetID())
+			return &gojira.Comment{ID: id}, nil
+		},
+		CreateCommentFn: func(
+			issue *gojira.Issue, comment *gogh.IssueComment, githubClient github.Client,
+		) (*gojira.Comment, error) {
+			created = append(created, comment.GetID())
+			return &gojira.Comment{}, nil
+		},
+	}
+
+	if err := Compare(cfg, ghIssue, jIssue, ghClient, jClient); err != nil {
+		t.Fatalf("Compare() returned error: %v", err)
+	}
+
+	if len(created) != 0 || len(updated) != 0 {
+		t.Fatalf("Expected both comments to be matched and up to date; created %v, updated %v", created, updated)
+	}
+}
+
+func TestCompareMatchesEscapedComments(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyEscapeMarkup: true,
+	})
+
+	body := "Use {code} in [brackets] with *stars* and snake_case"
+	ghIssue := &gogh.Issue{Number: gogh.Int(1), Comments: gogh.Int(2)}
+	ghClient := &github.GitHubClientMock{
+		ListCommentsFn: func(owner, repo string, issue *gogh.Issue, since time.Time) ([]*gogh.IssueComment, error) {
+			return []*gogh.IssueComment{
+				{ID: gogh.Int64(484163403), Body: gogh.String(body)},
+				{ID: gogh.Int64(123456789), Body: gogh.String(body)},
+			}, nil
+		},
+	}
+
+	escaped := `Use \{code\} in \[brackets\] with \*stars\* and snake\_case`
+	jIssue := &gojira.Issue{
+		Key: "TEST-1",
+		Fields: &gojira.IssueFields{Comments: &gojira.Comments{Comments: []*gojira.Comment{
+			// Copied with the special characters escaped.
+			{ID: "10001", Body: "{anchor:gh-comment:484163403}Copied from GitHub\n\n" + escaped},
+			// Copied before escaping was enabled.
+			{ID: "10002", Body: "{anchor:gh-comment:123456789}Copied from GitHub\n\n" + body},
+		}}},
+	}
+
+	var updated []int64
+	jClient := &jira.JiraClientMock{
+		UpdateCommentFn: func(
+			issue *gojira.Issue, id string, comment *gogh.IssueComment, githubClient github.Client,
+		) (*gojira.Comment, error) {
+			updated = append(updated, comment.GetID())
+			return &gojira.Comment{ID: id}, nil
+		},
+	}
+
+	if err := Compare(cfg, ghIssue, jIssue, ghClient, jClient); err != nil {
+		t.Fatalf("Compare() returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(updated, []int64{123456789}) {
+		t.Fatalf("Expected only the unescaped comment to be updated; updated %v", updated)
+	}
+}
+
+func TestCompareCreatesCommentsInCreationOrder(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	postedAt := time.Date(2023, time.July, 14, 9, 0, 0, 0, time.UTC)
+	newComment := func(id int64, offset time.Duration) *gogh.IssueComment {
+		return &gogh.IssueComment{
+			ID:        gogh.Int64(id),
+			Body:      gogh.String("rawr"),
+			CreatedAt: &gogh.Timestamp{Time: postedAt.Add(offset)},
+		}
+	}
+
+	ghIssue := &gogh.Issue{Number: gogh.Int(1), Comments: gogh.Int(3)}
+	ghClient := &github.GitHubClientMock{
+		ListCommentsFn: func(owner, repo string, issue *gogh.Issue, since time.Time) ([]*gogh.IssueComment, error) {
+			return []*gogh.IssueComment{
+				newComment(3, 2*time.Hour),
+				newComment(1, 0),
+				newComment(2, time.Hour),
+			}, nil
+		},
+	}
+
+	var created []int64
+	jClient := &jira.JiraClientMock{
+		CreateCommentFn: func(
+			issue *gojira.Issue, comment *gogh.IssueComment, githubClient github.Client,
+		) (*gojira.Comment, error) {
+			created = append(created, comment.GetID())
+			return &gojira.Comment{}, nil
+		},
+	}
+
+	jIssue := &gojira.Issue{Key: "TEST-1", Fields: &gojira.IssueFields{}}
+	if err := Compare(cfg, ghIssue, jIssue, ghClient, jClient); err != nil {
+		t.Fatalf("Compare() returned error: %v", err)
+	}
+
+	if expected := []int64{1, 2, 3}; !reflect.DeepEqual(created, expected) {
+		t.Fatalf("Expected comments to be created in order %v; got %v", expected, created)
+	}
+}
+
+// testADFComment is the body of a generated Jira comment as version 3 of the
+// Jira REST API lists it.
+//
+//nolint:lll
+const testADFComment = `{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"Comment "},{"type":"text","text":"(ID 484163403)","marks":[{"type":"link","attrs":{"href":"https://github.com"}}]},{"type":"text","text":" from GitHub user "},{"type":"text","text":"bilbo-baggins","marks":[{"type":"link","attrs":{"href":"https://github.com/bilbo-baggins"}}]},{"type":"text","text":" at 16:27 PM, April 17 2019:"}]},{"type":"paragraph","content":[{"type":"text","text":"See "},{"type":"text","text":"the docs","marks":[{"type":"link","attrs":{"href":"https://example.com"}}]}]}]}`
+
+// testADFCommentTemplated is the body of a generated Jira comment with a
+// templated header, as version 3 of the Jira REST API lists it.
+//
+//nolint:lll
+const testADFCommentTemplated = `{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"(ID 123456789)","marks":[{"type":"link","attrs":{"href":"https://github.com"}}]},{"type":"text","text":" Copied from GitHub by smaug-bot"}]},{"type":"paragraph","content":[{"type":"text","text":"rawr"}]}]}`
+
+func TestCompareMatchesADFComments(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyJiraAPIVersion: options.JiraAPIVersion3,
+	})
+
+	ghIssue := &gogh.Issue{Number: gogh.Int(1), Comments: gogh.Int(2)}
+	ghClient := &github.GitHubClientMock{
+		ListCommentsFn: func(owner, repo string, issue *gogh.Issue, since time.Time) ([]*gogh.IssueComment, error) {
+			return []*gogh.IssueComment{
+				{ID: gogh.Int64(484163403), Body: gogh.String("See [the docs](https://example.com)")},
+				{ID: gogh.Int64(123456789), Body: gogh.String("rawr, edited")},
+			}, nil
+		},
+	}
+
+	jIssue := &gojira.Issue{
+		Key: "TEST-1",
+		Fields: &gojira.IssueFields{Comments: &gojira.Comments{Comments: []*gojira.Comment{
+			{ID: "10001", Body: testADFComment},
+			{ID: "10002", Body: testADFCommentTemplated},
+		}}},
+	}
+
+	var updated, created []int64
+	jClient := &jira.JiraClientMock{
+		UpdateCommentFn: func(
+			issue *gojira.Issue, id string, comment *gogh.IssueComment, githubClient github.Client,
+		) (*gojira.Comment, error) {
+			updated = append(updated, comment.GetID())
+			return &gojira.Comment{ID: id}, nil
+		},
+		CreateCommentFn: func(
+			issue *gojira.Issue, comment *gogh.IssueComment, githubClient github.Client,
+		) (*gojira.Comment, error) {
+			created = append(created, comment.GetID())
+			return &gojira.Comment{}, nil
+		},
+	}
+
+	if err := Compare(cfg, ghIssue, jIssue, ghClient, jClient); err != nil {
+		t.Fatalf("Compare() returned error: %v", err)
+	}
+
+	if len(created) != 0 {
+		t.Fatalf("Expected both ADF comments to be matched; created %v", created)
+	}
+	if len(updated) != 1 || updated[0] != 123456789 {
+		t.Fatalf("Expected only the edited comment to be updated; updated %v", updated)
+	}
+}
+
+func TestLastKnownAssigneeReadsADFComments(t *testing.T) {
+	//nolint:lll
+	body := `{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"{anchor:gh-assignee:bilbo-baggins}Assigned to @bilbo-baggins on GitHub"}]}]}`
+	jIssue := &gojira.Issue{
+		Fields: &gojira.IssueFields{Comments: &gojira.Comments{Comments: []*gojira.Comment{{ID: "10001", Body: body}}}},
+	}
+
+	if login, ok := LastKnownAssignee(jIssue); !ok || login != "bilbo-baggins" {
+		t.Fatalf("Expected the assignee of the ADF comment; got %q, %v", login, ok)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package comment
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+// eventMarkerFormat is the format of the hidden anchor starting the Jira
+// comments which summarize a GitHub timeline event. Its argument is the ID
+// of the event.
+const eventMarkerFormat = "{anchor:gh-event:%d}"
+
+// eventMarkerRegex matches the anchor written by eventMarkerFormat. Its
+// matching group is the ID of the event.
+var eventMarkerRegex = regexp.MustCompile(`^\{anchor:gh-event:(\d+)\}`)
+
+// timelineDateFormat is the format of the date of a timeline event in its
+// Jira comment.
+const timelineDateFormat = "15:04 PM, January 2 2006"
+
+// syncedEventIDs returns the IDs of the GitHub timeline events which already
+// have a Jira comment on the issue.
+func syncedEventIDs(jIssue *gojira.Issue) map[int64]bool {
+	ids := map[int64]bool{}
+	if jIssue.Fields == nil || jIssue.Fields.Comments == nil {
+		return ids
+	}
+
+	for _, c := range jIssue.Fields.Comments.Comments {
+		matches := eventMarkerRegex.FindStringSubmatch(bodyText(c.Body))
+		if matches == nil {
+			continue
+		}
+		id, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		ids[id] = true
+	}
+
+	return ids
+}
+
+// EventSummary returns a concise summary of a GitHub timeline event, in Jira
+// markup. The boolean is false for events which are not synchronized.
+func EventSummary(event *gogh.Timeline) (string, bool) {
+	var action string
+	switch event.GetEvent() {
+	case "labeled":
+		action = fmt.Sprintf("added the label *%s*", event.GetLabel().GetName())
+	case "closed":
+		action = "closed the issue"
+	case "reopened":
+		action = "reopened the issue"
+	case "referenced":
+		sha := event.GetCommitID()
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		action = fmt.Sprintf("referenced the issue in commit [%s|%s]", sha, event.GetCommitURL())
+	default:
+		return "", false
+	}
+
+	actor := event.GetActor()
+	return fmt.Sprintf(
+		"GitHub user [%s|%s] %s at %s",
+		actor.GetLogin(),
+		actor.GetHTMLURL(),
+		action,
+		event.GetCreatedAt().Format(timelineDateFormat),
+	), true
+}
+
+// CompareTimeline posts a Jira comment summarizing each key timeline event
+// of the GitHub issue which does not have one yet, if `sync-timeline` is
+// enabled. Events are deduplicated by their ID, which is recorded in a hidden
+// anchor on their comment.
+func CompareTimeline(
+	cfg *config.Config,
+	ghIssue *gogh.Issue,
+	jIssue *gojira.Issue,
+	ghClient github.Client,
+	jClient jira.Client,
+) error {
+	if !cfg.ShouldSyncTimeline() {
+		return nil
+	}
+
+	owner, repo := cfg.GetRepo()
+	events, err := ghClient.ListTimeline(owner, repo, ghIssue.GetNumber())
+	if err != nil {
+		return fmt.Errorf("listing GitHub timeline events: %w", err)
+	}
+
+	synced := syncedEventIDs(jIssue)
+	for _, event := range events {
+		// Only events with an ID can be deduplicated.
+		if event.GetID() == 0 || synced[event.GetID()] {
+			continue
+		}
+
+		summary, ok := EventSummary(event)
+		if !ok {
+			continue
+		}
+
+		body := fmt.Sprintf(eventMarkerFormat, event.GetID()) + summary
+		if _, err := jClient.AddComment(jIssue, body); err != nil {
+			return fmt.Errorf("creating Jira comment for GitHub event %d: %w", event.GetID(), err)
+		}
+		synced[event.GetID()] = true
+
+		log.Debugf("Posted GitHub %s event %d to Jira issue %s", event.GetEvent(), event.GetID(), jIssue.Key)
+	}
+
+	return nil
+}
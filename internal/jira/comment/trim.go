@@ -0,0 +1,87 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package comment
+
+import (
+	"strings"
+
+	gogh "github.com/google/go-github/v56/github"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+)
+
+// TrimBody returns the body of a GitHub comment as it is synchronized to
+// Jira: with quote blocks removed if `comment-strip-quotes` is enabled, and
+// everything from the first line equal to `comment-separator` on removed if
+// it is set.
+func TrimBody(cfg *config.Config, body string) string {
+	stripQuotes := cfg.ShouldStripCommentQuotes()
+	separator := cfg.GetCommentSeparator()
+	if !stripQuotes && separator == "" {
+		return body
+	}
+
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if separator != "" && strings.TrimRight(line, " \t") == strings.TrimRight(separator, " \t") {
+			break
+		}
+		if stripQuotes && strings.HasPrefix(strings.TrimLeft(line, " \t"), ">") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	return collapseBlankLines(strings.TrimSpace(strings.Join(kept, "\n")))
+}
+
+// collapseBlankLines replaces runs of blank lines left by removed quote
+// blocks with a single blank line.
+func collapseBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+
+	collapsed := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		collapsed = append(collapsed, line)
+	}
+
+	return strings.Join(collapsed, "\n")
+}
+
+// trimmed returns a copy of the GitHub comment with its body trimmed by
+// TrimBody, so that the Jira client writes the trimmed body.
+func trimmed(cfg *config.Config, ghComment *gogh.IssueComment) *gogh.IssueComment {
+	body := TrimBody(cfg, ghComment.GetBody())
+	if body == ghComment.GetBody() {
+		return ghComment
+	}
+
+	c := *ghComment
+	c.Body = gogh.String(body)
+	return &c
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package jira
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	jira "github.com/uwu-tools/go-jira/v2/cloud"
+)
+
+// ErrFieldAbsent is returned when reading a custom field which is not set on
+// a Jira issue, including when the field is not defined in Jira at all.
+var ErrFieldAbsent = errors.New("custom field is not set")
+
+// FieldValue returns the value of the custom field key of the Jira issue.
+// The boolean is false if the field is absent. Unlike reading the Unknowns
+// of the issue directly, it is safe on issues without fields, as returned
+// for custom fields which are not defined in Jira.
+// ref: https://github.com/andygrunwald/go-jira/issues/322
+//
+// If the key is absent, the available keys are logged, and the key is looked
+// up ignoring case, in case Jira returned it with a different casing.
+func FieldValue(issue *jira.Issue, key string) (interface{}, bool) {
+	if issue == nil || issue.Fields == nil || issue.Fields.Unknowns == nil {
+		return nil, false
+	}
+
+	unknowns := issue.Fields.Unknowns
+	if value, ok := unknowns[key]; ok {
+		return value, true
+	}
+
+	keys := make([]string, 0, len(unknowns))
+	for k := range unknowns {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	log.Debugf(
+		"Custom field %s not found on Jira issue %s; available fields: %s",
+		key,
+		issue.Key,
+		strings.Join(keys, ", "),
+	)
+
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			log.Debugf("Matching custom field %s as %s", key, k)
+			return unknowns[k], true
+		}
+	}
+
+	return nil, false
+}
+
+// FieldString returns the value of the string custom field key of the Jira
+// issue. It returns ErrFieldAbsent if the field is absent.
+func FieldString(issue *jira.Issue, key string) (string, error) {
+	value, ok := FieldValue(issue, key)
+	if !ok || value == nil {
+		return "", fmt.Errorf("%w: %s", ErrFieldAbsent, key)
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("custom field %s is not a string; got %T", key, value) //nolint:goerr113
+	}
+
+	return s, nil
+}
@@ -0,0 +1,608 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/clock"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+func TestCompareStopsWhenDeadlineExceeded(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			return []*gogh.Issue{
+				{ID: gogh.Int64(1001), Number: gogh.Int(1)},
+				{ID: gogh.Int64(1002), Number: gogh.Int(2)},
+			}, nil
+		},
+	}
+
+	created := 0
+	jiraClient := &jira.JiraClientMock{
+		ListIssuesFn: func(ids []int) ([]gojira.Issue, error) {
+			// Simulate a slow Jira search, which uses up the run duration.
+			<-ctx.Done()
+			return nil, nil
+		},
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created++
+			return issue, nil
+		},
+	}
+
+	_, err := Compare(ctx, cfg, ghClient, jiraClient)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected error wrapping %v; got %v", context.DeadlineExceeded, err)
+	}
+	if created != 0 {
+		t.Fatalf("Expected no issues to be created after the deadline; got %d", created)
+	}
+}
+
+func TestCompareAdvancesSinceToLastProcessedIssue(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+	})
+
+	base := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	newIssue := func(number int, updated time.Time) *gogh.Issue {
+		return &gogh.Issue{
+			ID:        gogh.Int64(int64(1000 + number)),
+			Number:    gogh.Int(number),
+			State:     gogh.String("open"),
+			User:      &gogh.User{Login: gogh.String("octocat")},
+			UpdatedAt: &gogh.Timestamp{Time: updated},
+		}
+	}
+
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			return []*gogh.Issue{
+				newIssue(3, base.Add(3*time.Hour)),
+				newIssue(1, base.Add(1*time.Hour)),
+				newIssue(4, base.Add(4*time.Hour)),
+				newIssue(2, base.Add(2*time.Hour)),
+			}, nil
+		},
+	}
+
+	var processed []int64
+	jiraClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			id, _ := issue.Fields.Unknowns.Value(cfg.GetFieldKey(config.GitHubID))
+			processed = append(processed, id.(int64))
+			if id == int64(1003) {
+				return nil, errors.New("field 'summary' cannot be set")
+			}
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+	}
+
+	// The failure of #3 is returned once every issue is processed.
+	result, err := Compare(context.Background(), cfg, ghClient, jiraClient)
+	if err == nil || !strings.Contains(err.Error(), "#3") {
+		t.Fatalf("Expected Compare() to return the error of #3; got %v", err)
+	}
+	if result.Created != 3 || result.Failed != 1 {
+		t.Fatalf("Expected 3 created and 1 failed issues; got %+v", result)
+	}
+
+	if expected := []int64{1001, 1002, 1003, 1004}; !reflect.DeepEqual(processed, expected) {
+		t.Fatalf("Expected issues to be processed in order %v; got %v", expected, processed)
+	}
+
+	// #3 failed, so the next run must start from the last issue before it,
+	// even though #4 was synchronized.
+	if expected := base.Add(2 * time.Hour); !cfg.GetSinceParam().Equal(expected) {
+		t.Fatalf("Expected since to advance to %v; got %v", expected, cfg.GetSinceParam())
+	}
+}
+
+func TestCompareSyncMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		created int
+		updated int
+	}{
+		{mode: options.SyncModeCreateOnly, created: 1},
+		{mode: options.SyncModeUpdateOnly, updated: 1},
+		{mode: options.SyncModeBoth, created: 1, updated: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.mode, func(t *testing.T) {
+			cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+				options.ConfigKeyConfirm:  true,
+				options.ConfigKeySyncMode: tc.mode,
+			})
+
+			ghClient := &github.GitHubClientMock{
+				ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+					return []*gogh.Issue{
+						{
+							ID:     gogh.Int64(1001),
+							Number: gogh.Int(1),
+							Title:  gogh.String("Edited on GitHub"),
+							State:  gogh.String("open"),
+							User:   &gogh.User{Login: gogh.String("octocat")},
+						},
+						{
+							ID:     gogh.Int64(1002),
+							Number: gogh.Int(2),
+							State:  gogh.String("open"),
+							User:   &gogh.User{Login: gogh.String("octocat")},
+						},
+					}, nil
+				},
+			}
+
+			created, updated := 0, 0
+			jiraClient := &jira.JiraClientMock{
+				ListIssuesFn: func(ids []int) ([]gojira.Issue, error) {
+					return []gojira.Issue{newJiraIssue(cfg, "TEST-1", 1001)}, nil
+				},
+				CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+					created++
+					issue.Key = "TEST-2"
+					return issue, nil
+				},
+				UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+					updated++
+					return issue, nil
+				},
+			}
+
+			if _, err := Compare(context.Background(), cfg, ghClient, jiraClient); err != nil {
+				t.Fatalf("Compare() returned error: %v", err)
+			}
+
+			if created != tc.created || updated != tc.updated {
+				t.Fatalf(
+					"Expected %d created and %d updated issues; got %d created and %d updated",
+					tc.created, tc.updated, created, updated,
+				)
+			}
+		})
+	}
+}
+
+func TestCompareRelinksBySummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		relinked bool
+	}{
+		{name: "exact", title: "Fix the login page", relinked: true},
+		{name: "normalized", title: "  fix the  LOGIN page", relinked: true},
+		{name: "no match", title: "Fix the logout page", relinked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+				options.ConfigKeyConfirm:         true,
+				options.ConfigKeyRelinkBySummary: true,
+			})
+
+			ghClient := &github.GitHubClientMock{
+				ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+					return []*gogh.Issue{{
+						ID:     gogh.Int64(1001),
+						Number: gogh.Int(1),
+						Title:  gogh.String(tt.title),
+						State:  gogh.String("open"),
+						User:   &gogh.User{Login: gogh.String("octocat")},
+					}}, nil
+				},
+			}
+
+			var backfilled map[string]interface{}
+			created := 0
+			jiraClient := &jira.JiraClientMock{
+				ListUnlinkedIssuesFn: func() ([]gojira.Issue, error) {
+					return []gojira.Issue{{
+						Key:    "TEST-7",
+						Fields: &gojira.IssueFields{Summary: "Fix the login page"},
+					}}, nil
+				},
+				UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+					if backfilled == nil {
+						backfilled = issue.Fields.Unknowns
+					}
+					return issue, nil
+				},
+				CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+					created++
+					issue.Key = "TEST-8"
+					return issue, nil
+				},
+			}
+
+			result, err := Compare(context.Background(), cfg, ghClient, jiraClient)
+			if err != nil {
+				t.Fatalf("Compare() returned error: %v", err)
+			}
+
+			if !tt.relinked {
+				if created != 1 || backfilled != nil {
+					t.Fatalf("Expected a new Jira issue and no relink; got %d created, backfilled %v", created, backfilled)
+				}
+				return
+			}
+
+			if created != 0 || result.Updated != 1 {
+				t.Fatalf("Expected the unlinked Jira issue to be updated; got %+v", result)
+			}
+			if id := backfilled[cfg.GetFieldKey(config.GitHubID)]; id != int64(1001) {
+				t.Fatalf("Expected the GitHub ID to be backfilled; got %v", id)
+			}
+			if number := backfilled[cfg.GetFieldKey(config.GitHubNumber)]; number != 1 {
+				t.Fatalf("Expected the GitHub number to be backfilled; got %v", number)
+			}
+		})
+	}
+}
+
+func TestCompareThrottlesCreates(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:    true,
+		options.ConfigKeyCreateRate: 2.0,
+	})
+
+	start := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	cfg.SetClock(clk)
+
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			issues := make([]*gogh.Issue, 3)
+			for i := range issues {
+				issues[i] = &gogh.Issue{
+					ID:     gogh.Int64(int64(1001 + i)),
+					Number: gogh.Int(1 + i),
+					State:  gogh.String("open"),
+					User:   &gogh.User{Login: gogh.String("octocat")},
+				}
+			}
+			return issues, nil
+		},
+	}
+
+	var created []time.Duration
+	jiraClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = append(created, clk.Now().Sub(start))
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+	}
+
+	if _, err := Compare(context.Background(), cfg, ghClient, jiraClient); err != nil {
+		t.Fatalf("Compare() returned error: %v", err)
+	}
+
+	expected := []time.Duration{0, 500 * time.Millisecond, time.Second}
+	if !reflect.DeepEqual(created, expected) {
+		t.Fatalf("Expected creates at %v; got %v", expected, created)
+	}
+}
+
+func TestCompareFiltersByLabels(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:       true,
+		options.ConfigKeyIncludeLabels: []string{"jira-sync"},
+		options.ConfigKeyExcludeLabels: []string{"wontfix"},
+	})
+
+	labels := func(names ...string) []*gogh.Label {
+		ls := make([]*gogh.Label, len(names))
+		for i, name := range names {
+			ls[i] = &gogh.Label{Name: gogh.String(name)}
+		}
+		return ls
+	}
+
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			return []*gogh.Issue{
+				{ID: gogh.Int64(1001), Number: gogh.Int(1), State: gogh.String("open"), Labels: labels("Jira-Sync")},
+				{ID: gogh.Int64(1002), Number: gogh.Int(2), State: gogh.String("open"), Labels: labels("jira-sync", "wontfix")},
+				// The include label was removed after the issue was synchronized.
+				{ID: gogh.Int64(1003), Number: gogh.Int(3), State: gogh.String("open"), Labels: labels("bug")},
+			}, nil
+		},
+	}
+
+	var created []int64
+	jiraClient := &jira.JiraClientMock{
+		ListIssuesFn: func(ids []int) ([]gojira.Issue, error) {
+			return []gojira.Issue{newJiraIssue(cfg, "TEST-3", 1003)}, nil
+		},
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			id, _ := issue.Fields.Unknowns.Int(cfg.GetFieldKey(config.GitHubID))
+			created = append(created, id)
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+		UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			t.Fatalf("Expected no Jira issue to be updated; got %s", issue.Key)
+			return issue, nil
+		},
+	}
+
+	result, err := Compare(context.Background(), cfg, ghClient, jiraClient)
+	if err != nil {
+		t.Fatalf("Compare() returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(created, []int64{1001}) || result.Updated != 0 {
+		t.Fatalf("Expected only the included issue to be synchronized; created %v, result %+v", created, result)
+	}
+
+	logged := false
+	for _, entry := range hook.AllEntries() {
+		if strings.Contains(entry.Message, "TEST-3") {
+			logged = true
+		}
+	}
+	if !logged {
+		t.Fatal("Expected the Jira issue of the issue which lost its include label to be logged")
+	}
+}
+
+func TestCompareListsIssuesUpdatedSinceSinceDate(t *testing.T) {
+	tests := []struct {
+		name          string
+		fullReconcile bool
+		expectedSince time.Time
+	}{
+		{
+			name:          "incremental",
+			expectedSince: time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "full reconcile",
+			fullReconcile: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			values := map[string]interface{}{
+				options.ConfigKeySince:          "2023-06-01T12:00:00+0000",
+				options.ConfigKeySyncIssueState: options.IssueStateOpen,
+			}
+			if tc.fullReconcile {
+				values[options.ConfigKeyFullReconcileEvery] = 1
+			}
+			cfg := configtest.NewConfig(context.Background(), values)
+			cfg.StartRun()
+
+			var listed github.ListIssuesOptions
+			ghClient := &github.GitHubClientMock{
+				ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+					listed = opts
+					return nil, nil
+				},
+			}
+
+			if _, err := Compare(context.Background(), cfg, ghClient, &jira.JiraClientMock{}); err != nil {
+				t.Fatalf("Compare() returned error: %v", err)
+			}
+
+			if listed.State != options.IssueStateOpen {
+				t.Fatalf("Expected %s issues to be listed; got %q", options.IssueStateOpen, listed.State)
+			}
+			if !listed.Since.Equal(tc.expectedSince) {
+				t.Fatalf("Expected issues updated since %v to be listed; got %v", tc.expectedSince, listed.Since)
+			}
+		})
+	}
+}
+
+func TestCompareSkipsExcludedJiraStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments bool
+		created  int
+	}{
+		{name: "skipped"},
+		{name: "comments", comments: true, created: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+				options.ConfigKeyConfirm:                true,
+				options.ConfigKeyExcludeJiraStatus:      []string{"released"},
+				options.ConfigKeyExcludedStatusComments: tc.comments,
+			})
+
+			ghClient := &github.GitHubClientMock{
+				ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+					return []*gogh.Issue{{
+						ID:       gogh.Int64(1001),
+						Number:   gogh.Int(1),
+						Title:    gogh.String("Edited on GitHub"),
+						State:    gogh.String("open"),
+						User:     &gogh.User{Login: gogh.String("octocat")},
+						Comments: gogh.Int(1),
+					}}, nil
+				},
+				ListCommentsFn: func(owner, repo string, issue *gogh.Issue, since time.Time) ([]*gogh.IssueComment, error) {
+					return []*gogh.IssueComment{{
+						ID:   gogh.Int64(1),
+						Body: gogh.String("Still broken"),
+						User: &gogh.User{Login: gogh.String("octocat")},
+					}}, nil
+				},
+			}
+
+			updated, created := 0, 0
+			jiraClient := &jira.JiraClientMock{
+				ListIssuesFn: func(ids []int) ([]gojira.Issue, error) {
+					jIssue := newJiraIssue(cfg, "TEST-1", 1001)
+					jIssue.Fields.Status = &gojira.Status{Name: "Released"}
+					return []gojira.Issue{jIssue}, nil
+				},
+				UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+					updated++
+					return issue, nil
+				},
+				CreateCommentFn: func(
+					issue *gojira.Issue, comment *gogh.IssueComment, githubClient github.Client,
+				) (*gojira.Comment, error) {
+					created++
+					return &gojira.Comment{}, nil
+				},
+			}
+
+			result, err := Compare(context.Background(), cfg, ghClient, jiraClient)
+			if err != nil {
+				t.Fatalf("Compare() returned error: %v", err)
+			}
+			if updated != 0 || result.Skipped != 1 {
+				t.Fatalf("Expected the Jira issue in an excluded status to be skipped; got %d updates and %+v", updated, result)
+			}
+			if created != tc.created {
+				t.Fatalf("Expected %d created comments; got %d", tc.created, created)
+			}
+		})
+	}
+}
+
+func TestCompareExplainsDecisions(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:       true,
+		options.ConfigKeyExcludeLabels: []string{"wontfix"},
+		options.ConfigKeyExplain:       true,
+	})
+
+	ghIssues := []*gogh.Issue{
+		{
+			ID:     gogh.Int64(1001),
+			Number: gogh.Int(1),
+			Title:  gogh.String("Login page is broken"),
+			State:  gogh.String("open"),
+			User:   &gogh.User{Login: gogh.String("octocat")},
+		},
+		{
+			ID:     gogh.Int64(1002),
+			Number: gogh.Int(2),
+			Title:  gogh.String("Won't fix"),
+			State:  gogh.String("open"),
+			User:   &gogh.User{Login: gogh.String("octocat")},
+			Labels: []*gogh.Label{{Name: gogh.String("wontfix")}},
+		},
+	}
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			return ghIssues, nil
+		},
+	}
+
+	var jiraIssues []gojira.Issue
+	jiraClient := &jira.JiraClientMock{
+		ListIssuesFn: func(ids []int) ([]gojira.Issue, error) {
+			return jiraIssues, nil
+		},
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			issue.Key = "TEST-1"
+
+			// The issue is read back as Jira returns it, with numbers
+			// decoded as float64.
+			data, err := json.Marshal(issue)
+			if err != nil {
+				return nil, err
+			}
+			var created gojira.Issue
+			if err := json.Unmarshal(data, &created); err != nil {
+				return nil, err
+			}
+			jiraIssues = append(jiraIssues, created)
+			return issue, nil
+		},
+	}
+
+	explanations := func() map[string]string {
+		defer hook.Reset()
+
+		decisions := map[string]string{}
+		for _, entry := range hook.AllEntries() {
+			if decision, ok := entry.Data["decision"]; ok {
+				decisions[entry.Message] = decision.(string)
+			}
+		}
+		return decisions
+	}
+
+	runs := []map[string]string{
+		{
+			"GitHub issue #1: created":                            "created",
+			"GitHub issue #2: skipped (filtered: exclude-labels)": "skipped",
+		},
+		{
+			"GitHub issue #1: skipped (up to date)":               "skipped",
+			"GitHub issue #2: skipped (filtered: exclude-labels)": "skipped",
+		},
+		{
+			"GitHub issue #1: updated (fields: summary)":          "updated",
+			"GitHub issue #2: skipped (filtered: exclude-labels)": "skipped",
+		},
+	}
+	for i, expected := range runs {
+		if i == 2 {
+			ghIssues[0].Title = gogh.String("Login page is still broken")
+		}
+
+		if _, err := Compare(context.Background(), cfg, ghClient, jiraClient); err != nil {
+			t.Fatalf("Compare() returned error: %v", err)
+		}
+
+		if got := explanations(); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("Expected run %d to explain %v; got %v", i+1, expected, got)
+		}
+	}
+}
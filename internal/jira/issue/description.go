@@ -0,0 +1,114 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	gogh "github.com/google/go-github/v56/github"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+)
+
+// truncatedDescriptionFormat is appended to Jira issue descriptions which
+// were truncated to the configured maximum length. Its argument is the URL of
+// the GitHub issue.
+const truncatedDescriptionFormat = "\n\n…(truncated, see GitHub)\n%s"
+
+// jiraDescription returns the Jira issue description for a GitHub issue.
+// Any marker comment is removed from the GitHub issue body, and it is then
+// truncated to the configured maximum description length.
+//
+// The result only depends on the GitHub issue and the configuration, so it
+// can be compared to an existing description to detect changes.
+func jiraDescription(cfg *config.Config, ghIssue *gogh.Issue) string {
+	body := cfg.TransformField("description", stripMarker(ghIssue.GetBody()))
+
+	limit := cfg.GetMaxDescriptionLength()
+	if limit <= 0 {
+		return body
+	}
+
+	runes := []rune(body)
+	if len(runes) <= limit {
+		return body
+	}
+
+	suffix := []rune(fmt.Sprintf(truncatedDescriptionFormat, ghIssue.GetHTMLURL()))
+	keep := limit - len(suffix)
+	if keep < 0 {
+		keep = 0
+	}
+
+	return string(runes[:keep]) + string(suffix)
+}
+
+// markdownHeadingRegex matches a Markdown ATX heading line. Its matching
+// group is the text of the heading.
+var markdownHeadingRegex = regexp.MustCompile(`^ {0,3}#{1,6}(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+
+// ExtractSection returns the content of the section of a Markdown body under
+// the heading, up to the next heading. Headings are matched
+// case-insensitively, at any level. Lines in fenced code blocks, such as
+// shell comments, are not mistaken for headings. The boolean is false if the
+// body has no such section.
+func ExtractSection(body, heading string) (string, bool) {
+	var section []string
+	found := false
+	fenced := false
+
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+		}
+
+		if match := markdownHeadingRegex.FindStringSubmatch(line); match != nil && !fenced {
+			if found {
+				break
+			}
+			found = strings.EqualFold(strings.TrimSpace(match[1]), heading)
+			continue
+		}
+
+		if found {
+			section = append(section, line)
+		}
+	}
+
+	if !found {
+		return "", false
+	}
+
+	return strings.TrimSpace(strings.Join(section, "\n")), true
+}
+
+// jiraEnvironment returns the Jira issue environment for a GitHub issue,
+// extracted from the section of its body under the configured heading. The
+// boolean is false if the environment should not be set, because no heading
+// is configured or the body has no such section.
+func jiraEnvironment(cfg *config.Config, ghIssue *gogh.Issue) (string, bool) {
+	heading := cfg.GetEnvironmentHeading()
+	if heading == "" {
+		return "", false
+	}
+
+	return ExtractSection(stripMarker(ghIssue.GetBody()), heading)
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	gogh "github.com/google/go-github/v56/github"
+	"github.com/trivago/tgo/tcontainer"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+func TestJiraDescriptionTruncation(t *testing.T) {
+	const limit = 200
+
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyMaxDescriptionLength: limit,
+	})
+
+	ghIssue := &gogh.Issue{
+		Number:  gogh.Int(1),
+		Title:   gogh.String("Build fails"),
+		Body:    gogh.String(strings.Repeat("log line ✓\n", 100)),
+		HTMLURL: gogh.String("https://github.com/test-owner/test-repo/issues/1"),
+		State:   gogh.String("open"),
+		User:    &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	description := jiraDescription(cfg, ghIssue)
+	if n := utf8.RuneCountInString(description); n != limit {
+		t.Fatalf("Expected description of %d characters; got %d", limit, n)
+	}
+	if !strings.HasSuffix(description, "…(truncated, see GitHub)\n"+ghIssue.GetHTMLURL()) {
+		t.Fatalf("Expected truncation marker and link at the end of the description; got %q", description)
+	}
+	if !strings.HasPrefix(ghIssue.GetBody(), strings.SplitN(description, "\n\n…", 2)[0]) {
+		t.Fatalf("Expected description to start with the GitHub issue body; got %q", description)
+	}
+
+	// A Jira issue holding the truncated description is up to date.
+	unknowns := tcontainer.NewMarshalMap()
+	unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+	jIssue := &gojira.Issue{
+		Key: "TEST-1",
+		Fields: &gojira.IssueFields{
+			Summary:     ghIssue.GetTitle(),
+			Description: description,
+			Unknowns:    unknowns,
+		},
+	}
+	if DidIssueChange(cfg, ghIssue, jIssue) {
+		t.Fatal("Expected issue with truncated description not to be reported as changed")
+	}
+}
+
+func TestJiraDescriptionWithinLimit(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyMaxDescriptionLength: 200,
+	})
+
+	ghIssue := &gogh.Issue{Body: gogh.String("Steps to reproduce.")}
+	if description := jiraDescription(cfg, ghIssue); description != ghIssue.GetBody() {
+		t.Fatalf("Expected description %q; got %q", ghIssue.GetBody(), description)
+	}
+}
+
+func TestExtractSection(t *testing.T) {
+	const body = "### Description\n\nThe login page is broken.\n\n" +
+		"### Environment\n\n- OS: Linux\n- Browser: Firefox\n\n```console\n# uname -r\n6.1.0\n```\n\n" +
+		"### Additional context\n\nNone."
+
+	tests := []struct {
+		name    string
+		body    string
+		heading string
+		section string
+		ok      bool
+	}{
+		{
+			name:    "present",
+			body:    body,
+			heading: "Environment",
+			section: "- OS: Linux\n- Browser: Firefox\n\n```console\n# uname -r\n6.1.0\n```",
+			ok:      true,
+		},
+		{
+			name:    "case-insensitive heading",
+			body:    "## ENVIRONMENT ##\r\nmacOS 14\r\n",
+			heading: "environment",
+			section: "macOS 14",
+			ok:      true,
+		},
+		{
+			name:    "last section",
+			body:    body,
+			heading: "Additional context",
+			section: "None.",
+			ok:      true,
+		},
+		{
+			name:    "absent",
+			body:    "### Description\n\nThe login page is broken.\n\n#environment",
+			heading: "Environment",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			section, ok := ExtractSection(tc.body, tc.heading)
+			if section != tc.section || ok != tc.ok {
+				t.Fatalf("Expected (%q, %t); got (%q, %t)", tc.section, tc.ok, section, ok)
+			}
+		})
+	}
+}
+
+func TestEnvironmentField(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyEnvironmentHeading: "Environment",
+	})
+
+	ghIssue := &gogh.Issue{
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		Body:   gogh.String("### Description\n\nBroken.\n\n### Environment\n\nFirefox 118"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	unknowns := tcontainer.NewMarshalMap()
+	unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+	jIssue := &gojira.Issue{
+		Key: "TEST-1",
+		Fields: &gojira.IssueFields{
+			Summary:     ghIssue.GetTitle(),
+			Description: ghIssue.GetBody(),
+			Unknowns:    unknowns,
+		},
+	}
+
+	if changed := ChangedFields(cfg, ghIssue, jIssue); len(changed) != 1 || changed[0] != "environment" {
+		t.Fatalf("Expected only the environment to have changed; got %v", changed)
+	}
+
+	jIssue.Fields.Environment = "Firefox 118"
+	if DidIssueChange(cfg, ghIssue, jIssue) {
+		t.Fatal("Expected issue with the environment set not to be reported as changed")
+	}
+
+	// Without an environment section, the Jira field is left alone.
+	ghIssue.Body = gogh.String("Broken.")
+	jIssue.Fields.Description = ghIssue.GetBody()
+	if DidIssueChange(cfg, ghIssue, jIssue) {
+		t.Fatal("Expected issue without an environment section not to be reported as changed")
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+// DiffIssue returns a unified-diff-style description of the changes from
+// the old to the updated version of a Jira issue, for the summary,
+// description, status, reporter and labels. Fields are compared as by
+// ChangedFields, so only the fields which would be written are listed; the
+// result is empty if none of them changed.
+func DiffIssue(cfg *config.Config, old, updated *gojira.Issue) string {
+	var b strings.Builder
+
+	writeDiff(&b, "summary", []string{old.Fields.Summary}, []string{updated.Fields.Summary})
+	writeDiff(&b, "description", splitLines(old.Fields.Description), splitLines(updated.Fields.Description))
+
+	if cfg.HasField(config.GitHubStatus) {
+		key := cfg.GetFieldKey(config.GitHubStatus)
+		writeDiff(&b, config.CustomFieldNameGitHubStatus, unknownString(old, key), unknownString(updated, key))
+	}
+
+	if cfg.HasField(config.GitHubReporter) {
+		key := cfg.GetFieldKey(config.GitHubReporter)
+		writeDiff(&b, config.CustomFieldNameGitHubReporter, unknownString(old, key), unknownString(updated, key))
+	}
+
+	// Labels are compared as sets, as their order is not meaningful.
+	if cfg.HasField(config.GitHubLabels) {
+		key := cfg.GetFieldKey(config.GitHubLabels)
+		oldLabels, _ := jira.FieldValue(old, key)
+		updatedLabels, _ := jira.FieldValue(updated, key)
+		writeSetDiff(&b, config.CustomFieldNameGitHubLabels, toStrSlice(oldLabels), toStrSlice(updatedLabels))
+	}
+
+	return b.String()
+}
+
+// unknownString returns the value of a string custom field of the Jira
+// issue as a single line, or no line if it is not set.
+func unknownString(jIssue *gojira.Issue, key string) []string {
+	value, err := jira.FieldString(jIssue, key)
+	if err != nil {
+		return nil
+	}
+	return []string{value}
+}
+
+// splitLines returns the lines of s, or no line if s is empty.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// writeDiff writes the hunk of the field changing from the old to the
+// updated lines, if they differ. Lines common to both are kept as context.
+func writeDiff(b *strings.Builder, field string, old, updated []string) {
+	if slices.Equal(old, updated) {
+		return
+	}
+
+	fmt.Fprintf(b, "@@ %s @@\n", field)
+
+	// lcs[i][j] is the length of the longest common subsequence of old[i:]
+	// and updated[j:].
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(updated)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(updated) - 1; j >= 0; j-- {
+			if old[i] == updated[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(old) || j < len(updated) {
+		switch {
+		case i < len(old) && j < len(updated) && old[i] == updated[j]:
+			fmt.Fprintf(b, " %s\n", old[i])
+			i++
+			j++
+		case j == len(updated) || (i < len(old) && lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(b, "-%s\n", old[i])
+			i++
+		default:
+			fmt.Fprintf(b, "+%s\n", updated[j])
+			j++
+		}
+	}
+}
+
+// writeSetDiff writes the hunk of the field changing from the old to the
+// updated set of values, if they differ, one removed or added value per
+// line.
+func writeSetDiff(b *strings.Builder, field string, old, updated []string) {
+	if sameStrings(old, updated) {
+		return
+	}
+
+	fmt.Fprintf(b, "@@ %s @@\n", field)
+
+	removed, added := setDifference(old, updated), setDifference(updated, old)
+	for _, value := range removed {
+		fmt.Fprintf(b, "-%s\n", value)
+	}
+	for _, value := range added {
+		fmt.Fprintf(b, "+%s\n", value)
+	}
+}
+
+// setDifference returns the values of a which are not in b, sorted.
+func setDifference(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, value := range b {
+		in[value] = true
+	}
+
+	var diff []string
+	for _, value := range a {
+		if !in[value] {
+			diff = append(diff, value)
+		}
+	}
+	sort.Strings(diff)
+
+	return diff
+}
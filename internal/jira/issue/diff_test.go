@@ -0,0 +1,74 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/trivago/tgo/tcontainer"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+)
+
+func TestDiffIssue(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	newIssue := func(summary, description, status string, labels []string) *gojira.Issue {
+		unknowns := tcontainer.NewMarshalMap()
+		unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), status)
+		unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), "octocat")
+		unknowns.Set(cfg.GetFieldKey(config.GitHubLabels), labels)
+		return &gojira.Issue{
+			Key: "TEST-1",
+			Fields: &gojira.IssueFields{
+				Summary:     summary,
+				Description: description,
+				Unknowns:    unknowns,
+			},
+		}
+	}
+
+	old := newIssue("Login page broken", "Steps:\n1. Open\n2. Log in", "open", []string{"bug", "ui"})
+	updated := newIssue("Login page is broken", "Steps:\n1. Open\n2. Log in\n3. Crash", "closed", []string{"ui", "p1"})
+
+	expected := "@@ summary @@\n" +
+		"-Login page broken\n" +
+		"+Login page is broken\n" +
+		"@@ description @@\n" +
+		" Steps:\n" +
+		" 1. Open\n" +
+		" 2. Log in\n" +
+		"+3. Crash\n" +
+		"@@ github-status @@\n" +
+		"-open\n" +
+		"+closed\n" +
+		"@@ github-labels @@\n" +
+		"-bug\n" +
+		"+p1\n"
+	if diff := DiffIssue(cfg, old, updated); diff != expected {
+		t.Fatalf("Expected diff:\n%s\nGot:\n%s", expected, diff)
+	}
+
+	// Labels are compared as sets, so reordering them is not a change.
+	reordered := newIssue("Login page broken", "Steps:\n1. Open\n2. Log in", "open", []string{"ui", "bug"})
+	if diff := DiffIssue(cfg, old, reordered); diff != "" {
+		t.Fatalf("Expected no diff; got:\n%s", diff)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"strings"
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+)
+
+// dueDateFormat is the format of the date of due date labels, e.g.
+// `due:2024-12-01`.
+const dueDateFormat = "2006-01-02"
+
+// jiraDueDate returns the Jira issue due date for a GitHub issue, parsed from
+// its first label with the configured `due-date-label-prefix`. The boolean
+// is false if the due date should not be set, because no prefix is
+// configured or the issue has no such label with a valid date. Invalid dates
+// are logged and ignored.
+func jiraDueDate(cfg *config.Config, ghIssue *gogh.Issue) (gojira.Date, bool) {
+	prefix := cfg.GetDueDateLabelPrefix()
+	if prefix == "" {
+		return gojira.Date{}, false
+	}
+
+	for _, label := range ghIssue.Labels {
+		value, ok := strings.CutPrefix(label.GetName(), prefix)
+		if !ok {
+			continue
+		}
+
+		date, err := time.Parse(dueDateFormat, strings.TrimSpace(value))
+		if err != nil {
+			log.Warnf(
+				"Ignoring the due date label %q of GitHub issue #%d, as its date is not formatted as %s",
+				label.GetName(),
+				ghIssue.GetNumber(),
+				dueDateFormat,
+			)
+			continue
+		}
+
+		return gojira.Date(date), true
+	}
+
+	return gojira.Date{}, false
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+	"github.com/trivago/tgo/tcontainer"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+func TestJiraDueDate(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyDueDateLabelPrefix: "due:",
+	})
+
+	labels := func(names ...string) []*gogh.Label {
+		l := make([]*gogh.Label, len(names))
+		for i, name := range names {
+			l[i] = &gogh.Label{Name: gogh.String(name)}
+		}
+		return l
+	}
+
+	tests := []struct {
+		name     string
+		labels   []*gogh.Label
+		expected time.Time
+		ok       bool
+	}{
+		{
+			name:     "valid due label",
+			labels:   labels("bug", "due:2024-12-01"),
+			expected: time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:   "invalid due label",
+			labels: labels("due:next-week"),
+		},
+		{
+			name:     "invalid due label before a valid one",
+			labels:   labels("due:2024-13-01", "due:2025-01-15"),
+			expected: time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:   "no due label",
+			labels: labels("bug", "priority:high"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dueDate, ok := jiraDueDate(cfg, &gogh.Issue{Number: gogh.Int(1), Labels: tc.labels})
+			if ok != tc.ok || !time.Time(dueDate).Equal(tc.expected) {
+				t.Fatalf("Expected (%v, %t); Got (%v, %t)", tc.expected, tc.ok, time.Time(dueDate), ok)
+			}
+		})
+	}
+
+	// Without a prefix, the due date is never set.
+	cfg = configtest.NewConfig(context.Background(), nil)
+	if _, ok := jiraDueDate(cfg, &gogh.Issue{Labels: labels("due:2024-12-01")}); ok {
+		t.Fatal("Expected no due date without a due-date-label-prefix")
+	}
+}
+
+func TestDueDateField(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyDueDateLabelPrefix: "due:",
+	})
+
+	ghIssue := &gogh.Issue{
+		Number: gogh.Int(1),
+		Title:  gogh.String("Ship the release"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+		Labels: []*gogh.Label{{Name: gogh.String("due:2024-12-01")}},
+	}
+
+	unknowns := tcontainer.NewMarshalMap()
+	unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+	unknowns.Set(cfg.GetFieldKey(config.GitHubLabels), []string{"due:2024-12-01"})
+	jIssue := &gojira.Issue{
+		Key: "TEST-1",
+		Fields: &gojira.IssueFields{
+			Summary:  ghIssue.GetTitle(),
+			Unknowns: unknowns,
+		},
+	}
+
+	if changed := ChangedFields(cfg, ghIssue, jIssue); len(changed) != 1 || changed[0] != "duedate" {
+		t.Fatalf("Expected only the due date to have changed; got %v", changed)
+	}
+
+	jIssue.Fields.Duedate = gojira.Date(time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC))
+	if DidIssueChange(cfg, ghIssue, jIssue) {
+		t.Fatal("Expected issue with the due date set not to be reported as changed")
+	}
+}
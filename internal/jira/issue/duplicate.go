@@ -0,0 +1,180 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+const (
+	// stateReasonDuplicate is the `state_reason` of GitHub issues which were
+	// closed as a duplicate of another issue.
+	stateReasonDuplicate = "duplicate"
+
+	// duplicateLinkType is the name of the Jira issue link type used to link
+	// a duplicate issue to its canonical issue.
+	duplicateLinkType = "Duplicate"
+)
+
+// duplicateCommentRegex matches the comment conventionally left on a GitHub
+// issue closed as a duplicate, e.g. "Duplicate of #123". Its matching group
+// is the number of the canonical issue.
+var duplicateCommentRegex = regexp.MustCompile(`(?im)^\s*duplicate of #(\d+)`)
+
+// isClosedAsDuplicate returns whether the GitHub issue was closed as a
+// duplicate of another issue.
+func isClosedAsDuplicate(ghIssue *gogh.Issue) bool {
+	return ghIssue.GetState() == "closed" && ghIssue.GetStateReason() == stateReasonDuplicate
+}
+
+// DuplicateOf returns the number of the canonical issue that a GitHub issue
+// closed as a duplicate duplicates, based on its comments. The boolean is
+// false if the issue is not a duplicate or the canonical issue is unknown;
+// a "Duplicate of" comment on an issue closed for another reason does not
+// make it a duplicate.
+func DuplicateOf(ghIssue *gogh.Issue, comments []*gogh.IssueComment) (int, bool) {
+	if !isClosedAsDuplicate(ghIssue) {
+		return 0, false
+	}
+
+	// The most recent reference wins, in case the canonical issue was
+	// corrected after a first attempt.
+	for i := len(comments) - 1; i >= 0; i-- {
+		matches := duplicateCommentRegex.FindStringSubmatch(comments[i].GetBody())
+		if matches == nil {
+			continue
+		}
+
+		number, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+
+		return number, true
+	}
+
+	log.Debugf(
+		"GitHub issue #%d was closed as a duplicate, but does not reference its canonical issue",
+		ghIssue.GetNumber(),
+	)
+
+	return 0, false
+}
+
+// linkDuplicate creates a "Duplicate" issue link from the Jira issue of a
+// GitHub issue closed as a duplicate to the Jira issue of the canonical
+// GitHub issue, if both exist and are not already linked. A canonical issue
+// which was deleted, transferred or never existed is logged and skipped, so
+// that the duplicate is still synchronized.
+func linkDuplicate(
+	cfg *config.Config,
+	ghIssue *gogh.Issue,
+	jIssue *gojira.Issue,
+	ghClient github.Client,
+	jClient jira.Client,
+) error {
+	// The comments are only listed for duplicates, rather than for every
+	// closed issue.
+	if !isClosedAsDuplicate(ghIssue) {
+		return nil
+	}
+
+	owner, repo := cfg.GetRepo()
+	comments, err := ghClient.ListComments(owner, repo, ghIssue, time.Time{})
+	if err != nil {
+		return fmt.Errorf("listing GitHub comments: %w", err)
+	}
+
+	number, ok := DuplicateOf(ghIssue, comments)
+	if !ok {
+		return nil
+	}
+
+	canonical, err := ghClient.GetIssue(owner, repo, number)
+	if errors.Is(err, github.ErrIssueNotFound) {
+		log.Warnf(
+			"GitHub issue #%d duplicates #%d, which does not exist in %s/%s; not linking",
+			ghIssue.GetNumber(),
+			number,
+			owner,
+			repo,
+		)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting canonical GitHub issue #%d: %w", number, err)
+	}
+
+	jiraIssues, err := jClient.ListIssues([]int{int(canonical.GetID())})
+	if err != nil {
+		return fmt.Errorf("listing Jira issues: %w", err)
+	}
+
+	target := FindJiraIssue(cfg, canonical, jiraIssues)
+	if target == nil {
+		log.Infof(
+			"GitHub issue #%d duplicates #%d, which has no Jira issue yet; not linking",
+			ghIssue.GetNumber(),
+			number,
+		)
+		return nil
+	}
+
+	for _, link := range jIssue.Fields.IssueLinks {
+		if link.Type.Name != duplicateLinkType {
+			continue
+		}
+		if link.OutwardIssue != nil && link.OutwardIssue.Key == target.Key {
+			log.Debugf("Jira issue %s is already linked as a duplicate of %s", jIssue.Key, target.Key)
+			return nil
+		}
+	}
+
+	// The duplicate is the inward issue of the link, so that it reads
+	// "<duplicate> duplicates <canonical>".
+	link := &gojira.IssueLink{
+		Type: gojira.IssueLinkType{
+			Name: duplicateLinkType,
+		},
+		InwardIssue: &gojira.Issue{
+			Key: jIssue.Key,
+		},
+		OutwardIssue: &gojira.Issue{
+			Key: target.Key,
+		},
+	}
+
+	if err := jClient.CreateIssueLink(link); err != nil {
+		return fmt.Errorf("linking Jira issue %s as a duplicate of %s: %w", jIssue.Key, target.Key, err)
+	}
+
+	log.Infof("Linked Jira issue %s as a duplicate of %s", jIssue.Key, target.Key)
+
+	return nil
+}
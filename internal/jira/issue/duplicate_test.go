@@ -0,0 +1,219 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+func TestDuplicateOf(t *testing.T) {
+	comment := func(body string) *gogh.IssueComment {
+		return &gogh.IssueComment{Body: gogh.String(body)}
+	}
+
+	tests := []struct {
+		name     string
+		issue    *gogh.Issue
+		comments []*gogh.IssueComment
+		number   int
+		ok       bool
+	}{
+		{
+			name:     "closed as duplicate with reference",
+			issue:    &gogh.Issue{State: gogh.String("closed"), StateReason: gogh.String("duplicate")},
+			comments: []*gogh.IssueComment{comment("Thanks!"), comment("Duplicate of #12")},
+			number:   12,
+			ok:       true,
+		},
+		{
+			name:     "closed as not planned with reference",
+			issue:    &gogh.Issue{State: gogh.String("closed"), StateReason: gogh.String("not_planned")},
+			comments: []*gogh.IssueComment{comment("duplicate of #7\n\nClosing.")},
+		},
+		{
+			name:     "closed as completed with reference",
+			issue:    &gogh.Issue{State: gogh.String("closed"), StateReason: gogh.String("completed")},
+			comments: []*gogh.IssueComment{comment("Duplicate of #7")},
+		},
+		{
+			name:     "lowercase reference",
+			issue:    &gogh.Issue{State: gogh.String("closed"), StateReason: gogh.String("duplicate")},
+			comments: []*gogh.IssueComment{comment("duplicate of #7\n\nClosing.")},
+			number:   7,
+			ok:       true,
+		},
+		{
+			name:  "latest reference wins",
+			issue: &gogh.Issue{State: gogh.String("closed"), StateReason: gogh.String("duplicate")},
+			comments: []*gogh.IssueComment{
+				comment("Duplicate of #1"),
+				comment("Sorry, Duplicate of #2"),
+				comment("Duplicate of #3"),
+			},
+			number: 3,
+			ok:     true,
+		},
+		{
+			name:     "closed as duplicate without reference",
+			issue:    &gogh.Issue{State: gogh.String("closed"), StateReason: gogh.String("duplicate")},
+			comments: []*gogh.IssueComment{comment("Closing.")},
+		},
+		{
+			name:     "open issue",
+			issue:    &gogh.Issue{State: gogh.String("open")},
+			comments: []*gogh.IssueComment{comment("Duplicate of #12")},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			number, ok := DuplicateOf(tc.issue, tc.comments)
+			if number != tc.number || ok != tc.ok {
+				t.Fatalf("Expected (%d, %t); Got (%d, %t)", tc.number, tc.ok, number, ok)
+			}
+		})
+	}
+}
+
+func TestLinkDuplicate(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	duplicate := &gogh.Issue{
+		ID:          gogh.Int64(2002),
+		Number:      gogh.Int(2),
+		State:       gogh.String("closed"),
+		StateReason: gogh.String("duplicate"),
+	}
+	canonical := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		State:  gogh.String("open"),
+	}
+
+	ghClient := &github.GitHubClientMock{
+		ListCommentsFn: func(owner, repo string, issue *gogh.Issue, since time.Time) ([]*gogh.IssueComment, error) {
+			return []*gogh.IssueComment{{Body: gogh.String("Duplicate of #1")}}, nil
+		},
+		GetIssueFn: func(owner, repo string, number int) (*gogh.Issue, error) {
+			if number != canonical.GetNumber() {
+				t.Fatalf("Expected lookup of #%d; Got #%d", canonical.GetNumber(), number)
+			}
+			return canonical, nil
+		},
+	}
+
+	var links []*gojira.IssueLink
+	jClient := &jira.JiraClientMock{
+		ListIssuesFn: func(ids []int) ([]gojira.Issue, error) {
+			return []gojira.Issue{newJiraIssue(cfg, "TEST-1", canonical.GetID())}, nil
+		},
+		CreateIssueLinkFn: func(link *gojira.IssueLink) error {
+			links = append(links, link)
+			return nil
+		},
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-2", duplicate.GetID())
+	if err := linkDuplicate(cfg, duplicate, &jIssue, ghClient, jClient); err != nil {
+		t.Fatalf("linkDuplicate() returned error: %v", err)
+	}
+
+	if len(links) != 1 {
+		t.Fatalf("Expected 1 issue link; Got %d", len(links))
+	}
+	link := links[0]
+	if link.Type.Name != duplicateLinkType ||
+		link.InwardIssue.Key != "TEST-2" ||
+		link.OutwardIssue.Key != "TEST-1" {
+		t.Fatalf("Expected TEST-2 to be linked as a duplicate of TEST-1; Got %+v", link)
+	}
+
+	// Once linked, the link should not be created again.
+	jIssue.Fields.IssueLinks = links
+	if err := linkDuplicate(cfg, duplicate, &jIssue, ghClient, jClient); err != nil {
+		t.Fatalf("linkDuplicate() returned error: %v", err)
+	}
+	if len(links) != 1 {
+		t.Fatalf("Expected the existing link to be reused; Got %d links", len(links))
+	}
+}
+
+func TestLinkDuplicateOfMissingIssue(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	duplicate := &gogh.Issue{
+		ID:          gogh.Int64(2002),
+		Number:      gogh.Int(2),
+		State:       gogh.String("closed"),
+		StateReason: gogh.String("duplicate"),
+	}
+
+	ghClient := &github.GitHubClientMock{
+		ListCommentsFn: func(owner, repo string, issue *gogh.Issue, since time.Time) ([]*gogh.IssueComment, error) {
+			return []*gogh.IssueComment{{Body: gogh.String("Duplicate of #1")}}, nil
+		},
+		GetIssueFn: func(owner, repo string, number int) (*gogh.Issue, error) {
+			return nil, fmt.Errorf("retrieving GitHub issue #%d: %w", number, github.ErrIssueNotFound)
+		},
+	}
+	jClient := &jira.JiraClientMock{
+		CreateIssueLinkFn: func(link *gojira.IssueLink) error {
+			t.Fatalf("Expected no issue link to a missing issue; Got %+v", link)
+			return nil
+		},
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-2", duplicate.GetID())
+	if err := linkDuplicate(cfg, duplicate, &jIssue, ghClient, jClient); err != nil {
+		t.Fatalf("linkDuplicate() returned error: %v", err)
+	}
+}
+
+func TestLinkDuplicateSkipsOtherClosedIssues(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	for _, reason := range []string{"completed", "not_planned", ""} {
+		ghIssue := &gogh.Issue{
+			ID:          gogh.Int64(2002),
+			Number:      gogh.Int(2),
+			State:       gogh.String("closed"),
+			StateReason: gogh.String(reason),
+		}
+
+		ghClient := &github.GitHubClientMock{
+			ListCommentsFn: func(owner, repo string, issue *gogh.Issue, since time.Time) ([]*gogh.IssueComment, error) {
+				t.Fatalf("Expected the comments of an issue closed as %q not to be listed", reason)
+				return nil, nil
+			},
+		}
+
+		jIssue := newJiraIssue(cfg, "TEST-2", ghIssue.GetID())
+		if err := linkDuplicate(cfg, ghIssue, &jIssue, ghClient, &jira.JiraClientMock{}); err != nil {
+			t.Fatalf("linkDuplicate() returned error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+// lastSyncTime returns the `github-last-sync` value of the Jira issue. The
+// boolean is false if it is not set or can't be parsed.
+func lastSyncTime(cfg *config.Config, jIssue *gojira.Issue) (time.Time, bool) {
+	if !cfg.HasField(config.GitHubLastSync) {
+		return time.Time{}, false
+	}
+
+	value, err := jira.FieldString(jIssue, cfg.GetFieldKey(config.GitHubLastSync))
+	if err != nil || value == "" {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(options.DateFormat, value)
+	if err != nil {
+		log.Debugf("Ignoring invalid `github-last-sync` value %q on Jira issue %s", value, jIssue.Key)
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// keepJiraEdits returns whether the fields of the Jira issue should be kept
+// rather than overwritten, because `respect-jira-updates` is enabled and the
+// Jira issue was edited after it was last synchronized, while the GitHub
+// issue was not updated since that edit.
+//
+// Jira updates within `sync-update-window` of the last synchronization are
+// attributed to the synchronization itself.
+func keepJiraEdits(cfg *config.Config, ghIssue *gogh.Issue, jIssue *gojira.Issue) bool {
+	if !cfg.ShouldRespectJiraUpdates() {
+		return false
+	}
+
+	lastSync, ok := lastSyncTime(cfg, jIssue)
+	if !ok {
+		return false
+	}
+
+	updated := time.Time(jIssue.Fields.Updated)
+	if !updated.After(lastSync.Add(cfg.GetSyncUpdateWindow())) {
+		return false
+	}
+
+	if ghIssue.GetUpdatedAt().After(updated) {
+		log.Debugf(
+			"GitHub issue #%d was updated after Jira issue %s was edited; overwriting the Jira issue",
+			ghIssue.GetNumber(),
+			jIssue.Key,
+		)
+		return false
+	}
+
+	log.Infof(
+		"Jira issue %s was edited at %v, after its last synchronization at %v; not overwriting it",
+		jIssue.Key,
+		updated,
+		lastSync,
+	)
+
+	return true
+}
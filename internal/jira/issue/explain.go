@@ -0,0 +1,94 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"fmt"
+	"strings"
+
+	gogh "github.com/google/go-github/v56/github"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+)
+
+// decision is the outcome of synchronizing a GitHub issue, along with the
+// action and explanation logged for it by `explain`, e.g. "updated" and
+// "updated (fields: summary)". The action differs from the outcome for Jira
+// issues left unchanged, which are still counted as updated.
+type decision struct {
+	outcome     outcome
+	action      string
+	explanation string
+}
+
+// String returns the name of the outcome, as used in explanations.
+func (o outcome) String() string {
+	switch o {
+	case outcomeCreated:
+		return "created"
+	case outcomeUpdated:
+		return "updated"
+	case outcomeSkipped:
+		return "skipped"
+	case outcomeFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// decide returns the decision of the outcome with the reason, if any, as in
+// "skipped (up to date)".
+func decide(o outcome, reason string) decision {
+	d := decision{outcome: o, action: o.String(), explanation: o.String()}
+	if reason != "" {
+		d.explanation = fmt.Sprintf("%s (%s)", o, reason)
+	}
+	return d
+}
+
+// unchanged returns the decision of comparing a Jira issue without updating
+// it for the reason, e.g. "skipped (up to date)".
+func unchanged(reason string) decision {
+	d := decide(outcomeSkipped, reason)
+	d.outcome = outcomeUpdated
+	return d
+}
+
+// updatedFields returns the decision of an update of the changed fields.
+func updatedFields(changed []string) decision {
+	return decide(outcomeUpdated, "fields: "+strings.Join(changed, ", "))
+}
+
+// explain logs a single line with the decision for the GitHub issue, or the
+// error which failed it, if `explain` is set.
+func explain(cfg *config.Config, ghIssue *gogh.Issue, d decision, err error) {
+	if !cfg.ShouldExplain() {
+		return
+	}
+
+	explanation := d.explanation
+	action := d.action
+	if err != nil {
+		failed := decide(outcomeFailed, err.Error())
+		action, explanation = failed.action, failed.explanation
+	}
+
+	cfg.IssueLogger(ghIssue.GetNumber(), "").
+		WithField("decision", action).
+		Infof("GitHub issue #%d: %s", ghIssue.GetNumber(), explanation)
+}
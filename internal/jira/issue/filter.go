@@ -0,0 +1,93 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"strings"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+// filterByLabels returns the GitHub issues to synchronize according to
+// `include-labels` and `exclude-labels`. Issues with any exclude label are
+// dropped, and if include labels are set, so are issues without any of them.
+// Jira issues of dropped issues are left as they are.
+func filterByLabels(cfg *config.Config, ghIssues []*gogh.Issue, index *jiraIssueIndex) []*gogh.Issue {
+	include := cfg.GetIncludeLabels()
+	exclude := cfg.GetExcludeLabels()
+	if len(include) == 0 && len(exclude) == 0 {
+		return ghIssues
+	}
+
+	filtered := make([]*gogh.Issue, 0, len(ghIssues))
+	for _, ghIssue := range ghIssues {
+		switch labelFilter(cfg, ghIssue) {
+		case options.ConfigKeyExcludeLabels:
+			log.Debugf("Skipping GitHub issue #%d, which has an excluded label", ghIssue.GetNumber())
+			continue
+		case options.ConfigKeyIncludeLabels:
+			if jIssue := index.GetIssueByGitHubID(ghIssue.GetID()); jIssue != nil {
+				log.Infof(
+					"GitHub issue #%d no longer has any of the included labels; no longer synchronizing Jira issue %s",
+					ghIssue.GetNumber(),
+					jIssue.Key,
+				)
+			} else {
+				log.Debugf("Skipping GitHub issue #%d, which has none of the included labels", ghIssue.GetNumber())
+			}
+			continue
+		}
+
+		filtered = append(filtered, ghIssue)
+	}
+
+	return filtered
+}
+
+// labelFilter returns the option which filters out the GitHub issue:
+// `exclude-labels` if it has any exclude label, `include-labels` if include
+// labels are set and it has none of them, or an empty string if it is
+// synchronized.
+func labelFilter(cfg *config.Config, ghIssue *gogh.Issue) string {
+	if hasAnyLabel(ghIssue, cfg.GetExcludeLabels()) {
+		return options.ConfigKeyExcludeLabels
+	}
+
+	if include := cfg.GetIncludeLabels(); len(include) > 0 && !hasAnyLabel(ghIssue, include) {
+		return options.ConfigKeyIncludeLabels
+	}
+
+	return ""
+}
+
+// hasAnyLabel returns whether the GitHub issue has any of the labels. Label
+// names are compared ignoring case, as GitHub does.
+func hasAnyLabel(ghIssue *gogh.Issue, labels []string) bool {
+	for _, l := range ghIssue.Labels {
+		for _, name := range labels {
+			if strings.EqualFold(l.GetName(), name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
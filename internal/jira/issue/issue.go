@@ -17,19 +17,27 @@
 package issue
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"time"
+	"unicode"
 
 	gogh "github.com/google/go-github/v56/github"
 	log "github.com/sirupsen/logrus"
 	"github.com/trivago/tgo/tcontainer"
 	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+	"sigs.k8s.io/release-utils/version"
 
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira/comment"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/metrics"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
 )
 
 // dateFormat is the format used for the sync time field.
@@ -39,18 +47,48 @@ const dateFormat = "2006-01-02T15:04:05.0-0700"
 // gets the list of Jira issues which have GitHub ID custom fields in that list,
 // then matches each one. If a Jira issue already exists for a given GitHub issue,
 // it calls UpdateIssue; if no Jira issue already exists, it calls CreateIssue.
-func Compare(cfg *config.Config, ghClient github.Client, jiraClient jira.Client) error {
+//
+// Issues are processed from the least to the most recently updated. Unless
+// this is a dry run, the `since` date is then advanced to the update time of
+// the last issue processed, up to the first issue which failed, so that it
+// is retried on the next run.
+//
+// It returns a summary of the issues processed. The errors of the issues
+// which failed to synchronize are logged, and returned joined once every
+// issue is processed. If ctx is done before all issues are processed,
+// Compare stops and returns the context's error, along with the summary of
+// the issues processed so far.
+func Compare(ctx context.Context, cfg *config.Config, ghClient github.Client, jiraClient jira.Client) (Result, error) {
+	var result Result
+
 	log.Debug("Collecting issues")
 
 	owner, repo := cfg.GetRepo()
-	ghIssues, err := ghClient.ListIssues(owner, repo)
+
+	opts := github.ListIssuesOptions{
+		State:     cfg.GetSyncIssueState(),
+		Sort:      "updated",
+		Direction: "asc",
+	}
+	// A full reconcile revisits every issue, not only the recently updated
+	// ones.
+	if !cfg.IsFullReconcile() {
+		opts.Since = cfg.GetSinceParam()
+		log.Debugf("Listing GitHub issues of %s/%s updated since %v", owner, repo, opts.Since)
+	}
+
+	ghIssues, err := ghClient.ListIssues(owner, repo, opts)
 	if err != nil {
-		return fmt.Errorf("listing GitHub issues: %w", err)
+		return result, fmt.Errorf("listing GitHub issues: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return result, fmt.Errorf("aborting synchronization: %w", err)
 	}
 
 	if len(ghIssues) == 0 {
 		log.Info("There are no GitHub issues; exiting")
-		return nil
+		return result, nil
 	}
 
 	ids := make([]int, len(ghIssues))
@@ -61,121 +99,429 @@ func Compare(cfg *config.Config, ghClient github.Client, jiraClient jira.Client)
 
 	jiraIssues, err := jiraClient.ListIssues(ids)
 	if err != nil {
-		return fmt.Errorf("listing Jira issues: %w", err)
+		return result, fmt.Errorf("listing Jira issues: %w", err)
 	}
 
 	log.Debugf("Jira issues found: %v", len(jiraIssues))
 	log.Debug("Collected all Jira issues")
 
+	index := indexJiraIssuesByGitHubID(cfg, jiraIssues)
+
+	if cfg.ShouldExplain() {
+		for _, ghIssue := range ghIssues {
+			if filter := labelFilter(cfg, ghIssue); filter != "" {
+				explain(cfg, ghIssue, decide(outcomeSkipped, "filtered: "+filter), nil)
+			}
+		}
+	}
+
+	ghIssues = filterByLabels(cfg, ghIssues, index)
+
+	var relinker *summaryRelinker
+	if cfg.ShouldRelinkBySummary() {
+		unlinked, err := jiraClient.ListUnlinkedIssues()
+		if err != nil {
+			return result, fmt.Errorf("listing unlinked Jira issues: %w", err)
+		}
+		log.Debugf("Unlinked Jira issues found: %v", len(unlinked))
+		relinker = newSummaryRelinker(unlinked)
+	}
+
 	fieldKey := cfg.GetFieldKey(config.GitHubID)
 	log.Debugf("GitHub ID custom field key: %s", fieldKey)
 
-	// TODO(compare): Consider move ID comparison logic into separate function
-	for _, ghIssue := range ghIssues {
-		found := false
+	sort.SliceStable(ghIssues, func(i, j int) bool {
+		return ghIssues[i].GetUpdatedAt().Before(ghIssues[j].GetUpdatedAt().Time)
+	})
 
-		ghID := *ghIssue.ID
+	throttle := newCreateThrottle(ctx, cfg)
 
-		for i := range jiraIssues {
-			jIssue := jiraIssues[i]
+	w := &watermark{since: cfg.GetSinceParam()}
+	defer func() {
+		if !cfg.IsDryRun() {
+			cfg.SetSince(owner+"/"+repo, w.since)
+		}
+	}()
 
-			// TODO(fields): Getting a field with Unknowns will generate a nil
-			//               pointer exception if the custom field is not defined in
-			//               Jira.
-			//               ref: https://github.com/andygrunwald/go-jira/issues/322
-			unknowns := jIssue.Fields.Unknowns
-			id, exists := unknowns.Value(fieldKey)
-			if !exists {
-				log.Info("GitHub ID custom field is not set for issue")
+	var failures []error
+	for i, ghIssue := range ghIssues {
+		if err := ctx.Err(); err != nil {
+			for _, remaining := range ghIssues[i:] {
+				explain(cfg, remaining, decide(outcomeSkipped, "aborted"), nil)
 			}
+			failures = append(failures, fmt.Errorf("aborting synchronization: %w", err))
+			return result, errors.Join(failures...)
+		}
 
-			jiraID, ok := id.(float64)
-			if !ok {
-				log.Debugf("GitHub ID custom field is not an float64; got %T", id)
-				break
-			}
+		d, err := compareIssue(cfg, ghIssue, index, relinker, throttle, ghClient, jiraClient)
+		if err != nil {
+			cfg.IssueLogger(ghIssue.GetNumber(), "").Error(err)
+			failures = append(failures, err)
+			metrics.IssuesFailed.Inc()
+		}
+		explain(cfg, ghIssue, d, err)
+		result.record(d.outcome)
+		w.advance(ghIssue, d.outcome != outcomeFailed)
+	}
 
-			ghIDFloat64 := float64(ghID)
-			if jiraID == ghIDFloat64 {
-				found = true
+	return result, errors.Join(failures...)
+}
 
-				log.Infof("updating issue %s", jIssue.ID)
-				if err := UpdateIssue(cfg, ghIssue, &jIssue, ghClient, jiraClient); err != nil {
-					log.Errorf("Error updating issue %s. Error: %v", jIssue.Key, err)
-				}
-				break
-			}
+// compareIssue synchronizes a single GitHub issue with its Jira issue,
+// creating the Jira issue if it doesn't exist yet, and returns the outcome.
+// The Jira issue is looked up in index. If relinker is not nil, it is used
+// to find a Jira issue which was never linked to a GitHub issue before
+// creating one. Creations are spaced by throttle. The error is only returned
+// along with outcomeFailed, so that the caller can go on with the remaining
+// issues.
+func compareIssue(
+	cfg *config.Config,
+	ghIssue *gogh.Issue,
+	index *jiraIssueIndex,
+	relinker *summaryRelinker,
+	throttle *createThrottle,
+	ghClient github.Client,
+	jiraClient jira.Client,
+) (decision, error) {
+	jIssue, err := findJiraIssueByStrategy(cfg, ghIssue, index, jiraClient)
+	if err != nil {
+		return decide(outcomeFailed, ""), fmt.Errorf("matching issue #%d: %w", ghIssue.GetNumber(), err)
+	}
+
+	if jIssue == nil && relinker != nil {
+		jIssue, err = relinker.relink(cfg, ghIssue, jiraClient)
+		if err != nil {
+			return decide(outcomeFailed, ""), fmt.Errorf("relinking issue #%d: %w", ghIssue.GetNumber(), err)
 		}
-		if !found {
-			if err := CreateIssue(cfg, ghIssue, ghClient, jiraClient); err != nil {
-				log.Errorf("Error creating issue for #%d. Error: %v", *ghIssue.Number, err)
-			}
+	}
+
+	mode := cfg.GetSyncMode()
+
+	if jIssue != nil {
+		if mode == options.SyncModeCreateOnly {
+			log.Debugf("Jira issue %s already exists; not updating it in %s mode", jIssue.Key, mode)
+			return decide(outcomeSkipped, "sync-mode "+mode), nil
+		}
+
+		if status := jIssue.Fields.Status; status != nil && cfg.IsExcludedJiraStatus(status.Name) {
+			return skipExcludedStatus(cfg, ghIssue, jIssue, ghClient, jiraClient)
+		}
+
+		log.Infof("updating issue %s", jIssue.ID)
+		d, err := updateIssue(cfg, ghIssue, jIssue, ghClient, jiraClient)
+		if err != nil {
+			return decide(outcomeFailed, ""), fmt.Errorf("updating issue %s: %w", jIssue.Key, err)
+		}
+		if err := releaseMilestone(cfg, ghIssue, jiraClient); err != nil {
+			return decide(outcomeFailed, ""), err
+		}
+		return d, nil
+	}
+
+	if mode == options.SyncModeUpdateOnly {
+		log.Debugf("GitHub issue #%d has no Jira issue; not creating it in %s mode", ghIssue.GetNumber(), mode)
+		return decide(outcomeSkipped, "sync-mode "+mode), nil
+	}
+
+	if err := throttle.wait(); err != nil {
+		return decide(outcomeFailed, ""), fmt.Errorf("waiting to create issue for #%d: %w", ghIssue.GetNumber(), err)
+	}
+
+	if err := CreateIssue(cfg, ghIssue, ghClient, jiraClient); err != nil {
+		return decide(outcomeFailed, ""), fmt.Errorf("creating issue for #%d: %w", ghIssue.GetNumber(), err)
+	}
+	if err := releaseMilestone(cfg, ghIssue, jiraClient); err != nil {
+		return decide(outcomeFailed, ""), err
+	}
+
+	return decide(outcomeCreated, ""), nil
+}
+
+// skipExcludedStatus leaves alone a Jira issue in a status listed in
+// `exclude-jira-status`, only copying the GitHub comments to it if
+// `excluded-status-comments` is set.
+func skipExcludedStatus(
+	cfg *config.Config,
+	ghIssue *gogh.Issue,
+	jIssue *gojira.Issue,
+	ghClient github.Client,
+	jiraClient jira.Client,
+) (decision, error) {
+	skipped := decide(outcomeSkipped, "excluded Jira status "+jIssue.Fields.Status.Name)
+	if !cfg.ShouldSyncExcludedStatusComments() {
+		log.Infof("Jira issue %s is in excluded status %s; not updating it", jIssue.Key, jIssue.Fields.Status.Name)
+		return skipped, nil
+	}
+
+	log.Infof(
+		"Jira issue %s is in excluded status %s; only synchronizing comments",
+		jIssue.Key,
+		jIssue.Fields.Status.Name,
+	)
+
+	// The search results lack the comments of the issue.
+	foundIssue, err := jiraClient.GetIssue(jIssue.Key)
+	if err != nil {
+		return decide(outcomeFailed, ""), fmt.Errorf("getting Jira issue %s: %w", jIssue.Key, err)
+	}
+
+	if err := comment.Compare(cfg, ghIssue, foundIssue, ghClient, jiraClient); err != nil {
+		return decide(outcomeFailed, ""), fmt.Errorf("comparing comments for issue %s: %w", jIssue.Key, err)
+	}
+
+	return skipped, nil
+}
+
+// watermark tracks the `since` date up to which all GitHub issues, processed
+// in ascending order of update time, were synchronized successfully.
+type watermark struct {
+	since  time.Time
+	failed bool
+}
+
+// advance records the outcome of synchronizing the GitHub issue. The
+// watermark stops advancing at the first issue which failed.
+func (w *watermark) advance(ghIssue *gogh.Issue, ok bool) {
+	if w.failed {
+		return
+	}
+
+	if !ok {
+		w.failed = true
+		return
+	}
+
+	if updated := ghIssue.GetUpdatedAt().Time; updated.After(w.since) {
+		w.since = updated
+	}
+}
+
+// FindJiraIssue returns the Jira issue in jiraIssues whose GitHub ID custom
+// field matches the ID of the GitHub issue, or nil if there is none. To match
+// many GitHub issues against the same Jira issues, index them once with
+// indexJiraIssuesByGitHubID instead.
+func FindJiraIssue(cfg *config.Config, ghIssue *gogh.Issue, jiraIssues []gojira.Issue) *gojira.Issue {
+	fieldKey := cfg.GetFieldKey(config.GitHubID)
+
+	for i := range jiraIssues {
+		jIssue := &jiraIssues[i]
+		if id, ok := githubIDOf(fieldKey, jIssue); ok && id == ghIssue.GetID() {
+			return jIssue
 		}
 	}
 
 	return nil
 }
 
+// jiraIssueIndex indexes Jira issues by the value of their GitHub ID custom
+// field and by their key, so that GitHub issues are matched in constant
+// time.
+type jiraIssueIndex struct {
+	byGitHubID map[int64]*gojira.Issue
+	byKey      map[string]*gojira.Issue
+}
+
+// indexJiraIssuesByGitHubID returns an index of the Jira issues. If several
+// Jira issues have the same GitHub ID, the first one is indexed, as
+// FindJiraIssue would return it.
+func indexJiraIssuesByGitHubID(cfg *config.Config, jiraIssues []gojira.Issue) *jiraIssueIndex {
+	fieldKey := cfg.GetFieldKey(config.GitHubID)
+
+	index := &jiraIssueIndex{
+		byGitHubID: make(map[int64]*gojira.Issue, len(jiraIssues)),
+		byKey:      make(map[string]*gojira.Issue, len(jiraIssues)),
+	}
+	for i := range jiraIssues {
+		jIssue := &jiraIssues[i]
+
+		if _, ok := index.byKey[jIssue.Key]; !ok {
+			index.byKey[jIssue.Key] = jIssue
+		}
+
+		id, ok := githubIDOf(fieldKey, jIssue)
+		if !ok {
+			continue
+		}
+		if other, ok := index.byGitHubID[id]; ok {
+			log.Warnf(
+				"Jira issues %s and %s have the same GitHub ID %d; matching %s",
+				other.Key,
+				jIssue.Key,
+				id,
+				other.Key,
+			)
+			continue
+		}
+		index.byGitHubID[id] = jIssue
+	}
+
+	return index
+}
+
+// GetIssueByGitHubID returns the Jira issue whose GitHub ID custom field is
+// id, or nil if there is none.
+func (idx *jiraIssueIndex) GetIssueByGitHubID(id int64) *gojira.Issue {
+	return idx.byGitHubID[id]
+}
+
+// GetIssueByKey returns the Jira issue with the key, or nil if there is none.
+func (idx *jiraIssueIndex) GetIssueByKey(key string) *gojira.Issue {
+	return idx.byKey[key]
+}
+
+// githubIDOf returns the value of the GitHub ID custom field of the Jira
+// issue. The boolean is false if the field is not set.
+func githubIDOf(fieldKey string, jIssue *gojira.Issue) (int64, bool) {
+	id, exists := jira.FieldValue(jIssue, fieldKey)
+	if !exists || id == nil {
+		log.Debugf("GitHub ID custom field is not set for Jira issue %s", jIssue.Key)
+		return 0, false
+	}
+
+	jiraID, ok := id.(float64)
+	if !ok {
+		log.Debugf("GitHub ID custom field is not an float64; got %T", id)
+		return 0, false
+	}
+
+	return int64(jiraID), true
+}
+
 // DidIssueChange tests each of the relevant fields on the provided Jira and GitHub issue
 // and returns whether or not they differ.
-//
-//nolint:gocognit // TODO(lint)
 func DidIssueChange(cfg *config.Config, ghIssue *gogh.Issue, jIssue *gojira.Issue) bool {
+	return len(changedFields(cfg, ghIssue, jIssue)) > 0
+}
+
+// changedFields is ChangedFields, which also logs the comparison.
+func changedFields(cfg *config.Config, ghIssue *gogh.Issue, jIssue *gojira.Issue) []string {
 	log.Debugf("Comparing GitHub issue #%d and Jira issue %s", ghIssue.GetNumber(), jIssue.Key)
 
-	anyDifferent := false
+	changed := ChangedFields(cfg, ghIssue, jIssue)
+	anyDifferent := len(changed) > 0
 
-	anyDifferent = anyDifferent || (ghIssue.GetTitle() != jIssue.Fields.Summary)
-	anyDifferent = anyDifferent || (ghIssue.GetBody() != jIssue.Fields.Description)
+	log.Debugf("Issues have any differences: %t", anyDifferent)
+	if anyDifferent {
+		log.Debugf(
+			"Jira issue %s differs from GitHub issue #%d in fields: %s",
+			jIssue.Key,
+			ghIssue.GetNumber(),
+			strings.Join(changed, ", "),
+		)
+	}
+
+	return changed
+}
+
+// ChangedFields tests each of the relevant fields on the provided Jira and
+// GitHub issue and returns the names of the Jira fields which differ.
+//
+//nolint:gocognit // TODO(lint)
+func ChangedFields(cfg *config.Config, ghIssue *gogh.Issue, jIssue *gojira.Issue) []string {
+	var changed []string
+
+	if jiraSummary(cfg, ghIssue) != jIssue.Fields.Summary {
+		changed = append(changed, "summary")
+	}
+	if jiraDescription(cfg, ghIssue) != jIssue.Fields.Description {
+		changed = append(changed, "description")
+	}
+	if environment, ok := jiraEnvironment(cfg, ghIssue); ok && environment != jIssue.Fields.Environment {
+		changed = append(changed, "environment")
+	}
+	if dueDate, ok := jiraDueDate(cfg, ghIssue); ok && !time.Time(dueDate).Equal(time.Time(jIssue.Fields.Duedate)) {
+		changed = append(changed, "duedate")
+	}
 
-	key := cfg.GetFieldKey(config.GitHubStatus)
-	field, err := jIssue.Fields.Unknowns.String(key)
-	if err != nil || *ghIssue.State != field {
-		anyDifferent = true
+	// The fields listed in `optional-fields` which do not exist are not
+	// compared.
+	if cfg.HasField(config.GitHubStatus) {
+		field, err := jira.FieldString(jIssue, cfg.GetFieldKey(config.GitHubStatus))
+		if err != nil || *ghIssue.State != field {
+			changed = append(changed, config.CustomFieldNameGitHubStatus)
+		}
 	}
 
-	key = cfg.GetFieldKey(config.GitHubReporter)
-	field, err = jIssue.Fields.Unknowns.String(key)
-	if err != nil || *ghIssue.User.Login != field {
-		anyDifferent = true
+	if cfg.HasField(config.GitHubReporter) {
+		field, err := jira.FieldString(jIssue, cfg.GetFieldKey(config.GitHubReporter))
+		if err != nil || reporter(cfg, ghIssue) != field {
+			changed = append(changed, config.CustomFieldNameGitHubReporter)
+		}
 	}
 
 	if GetMissingComponents(cfg, jIssue) != nil {
-		anyDifferent = true
+		changed = append(changed, "components")
 	}
 
-	if len(ghIssue.Labels) > 0 { //nolint:nestif // TODO(lint)
-		ghLabels := githubLabelsToStrSlice(ghIssue.Labels)
+	if label := cfg.GetSyncedLabel(); label != "" && !hasLabel(jIssue, label) {
+		changed = append(changed, "labels")
+	}
 
-		key = cfg.GetFieldKey(config.GitHubLabels)
-		labelsField, exists := jIssue.Fields.Unknowns.Value(key)
-		if !exists {
-			log.Debug("`GitHub Labels` field is not populated")
+	if cfg.ShouldSyncMilestones() && fixVersionsChanged(ghIssue, jIssue) {
+		changed = append(changed, "fixVersions")
+	}
+
+	if cfg.ShouldSyncMilestonesAsSprints() && sprintChanged(cfg, ghIssue, jIssue) {
+		changed = append(changed, "sprint")
+	}
+
+	if cfg.HasField(config.GitHubAssignee) {
+		value, _ := jira.FieldValue(jIssue, cfg.GetFieldKey(config.GitHubAssignee))
+		if !sameStrings(githubAssigneesToStrSlice(ghIssue.Assignees), toStrSlice(value)) {
+			changed = append(changed, config.CustomFieldNameGitHubAssignee)
 		}
+	}
 
-		jiraLabels, _ := labelsField.([]string) //nolint:errcheck // TODO(lint)
-
-		for _, label := range ghLabels {
-			if !anyDifferent {
-				found := false
-				for i, jiraLabel := range jiraLabels {
-					if i < len(jiraLabels) && !found {
-						if label == jiraLabel {
-							found = true
-							break
-						}
-					} else {
-						anyDifferent = true
-						break
-					}
-				}
-			}
+	if cfg.HasField(config.GitHubComments) {
+		value, _ := jira.FieldValue(jIssue, cfg.GetFieldKey(config.GitHubComments))
+		if ghIssue.GetComments() != toInt(value) {
+			changed = append(changed, config.CustomFieldNameGitHubComments)
 		}
 	}
 
-	log.Debugf("Issues have any differences: %t", anyDifferent)
+	// The update time changes on any activity, including comments, but only
+	// issues updated since the last synchronization are compared, so this
+	// causes at most one update of the Jira issue per synchronization.
+	if cfg.HasField(config.GitHubUpdated) &&
+		timeFieldChanged(jIssue, cfg.GetFieldKey(config.GitHubUpdated), ghIssue.UpdatedAt) {
+		changed = append(changed, config.CustomFieldNameGitHubUpdated)
+	}
+	if cfg.HasField(config.GitHubCreated) &&
+		timeFieldChanged(jIssue, cfg.GetFieldKey(config.GitHubCreated), ghIssue.CreatedAt) {
+		changed = append(changed, config.CustomFieldNameGitHubCreated)
+	}
+	if cfg.HasField(config.GitHubClosed) &&
+		timeFieldChanged(jIssue, cfg.GetFieldKey(config.GitHubClosed), ghIssue.ClosedAt) {
+		changed = append(changed, config.CustomFieldNameGitHubClosed)
+	}
+
+	if cfg.HasField(config.GitHubAuthorAssociation) {
+		field, _ := jira.FieldString(jIssue, cfg.GetFieldKey(config.GitHubAuthorAssociation)) //nolint:errcheck
+		if ghIssue.GetAuthorAssociation() != field {
+			changed = append(changed, config.CustomFieldNameGitHubAuthorAssociation)
+		}
+	}
+
+	if syncLabelColors(cfg) {
+		field, _ := jira.FieldString(jIssue, cfg.GetFieldKey(config.GitHubLabelColors)) //nolint:errcheck
+		if labelColors(cfg, ghIssue) != field {
+			changed = append(changed, config.CustomFieldNameGitHubLabelColors)
+		}
+	}
 
-	return anyDifferent
+	// Labels are compared as sets, as their order is not meaningful.
+	if cfg.HasField(config.GitHubLabels) {
+		labelsField, exists := jira.FieldValue(jIssue, cfg.GetFieldKey(config.GitHubLabels))
+		if !exists {
+			log.Debug("`GitHub Labels` field is not populated")
+		}
+		labels, _ := syncedLabels(cfg, ghIssue)
+		if !sameStrings(githubLabelsToStrSlice(cfg, labels), toStrSlice(labelsField)) {
+			changed = append(changed, config.CustomFieldNameGitHubLabels)
+		}
+	}
+
+	return changed
 }
 
 // UpdateIssue compares each field of a GitHub issue to a Jira issue; if any of them
@@ -188,24 +534,110 @@ func UpdateIssue(
 	ghClient github.Client,
 	jClient jira.Client,
 ) error {
-	log.Debugf("Updating Jira %s with GitHub #%d", jIssue.Key, *ghIssue.Number)
+	_, err := updateIssue(cfg, ghIssue, jIssue, ghClient, jClient)
+	return err
+}
 
-	if DidIssueChange(cfg, ghIssue, jIssue) {
+// updateIssue is UpdateIssue, which also returns the decision explaining
+// whether and how the Jira issue was updated. Its comments are synchronized
+// whatever the decision, so its outcome is always outcomeUpdated.
+func updateIssue(
+	cfg *config.Config,
+	ghIssue *gogh.Issue,
+	jIssue *gojira.Issue,
+	ghClient github.Client,
+	jClient jira.Client,
+) (decision, error) {
+	logger := cfg.IssueLogger(ghIssue.GetNumber(), jIssue.Key)
+	logger.Debugf("Updating Jira %s with GitHub #%d", jIssue.Key, *ghIssue.Number)
+
+	// Without the `github-status` field, state changes can't be detected, so
+	// the Jira issue is never transitioned.
+	previousState := ghIssue.GetState()
+	if cfg.HasField(config.GitHubStatus) {
+		previousState, _ = jira.FieldString(jIssue, cfg.GetFieldKey(config.GitHubStatus)) //nolint:errcheck
+	}
+
+	if err := resolveReporter(cfg, ghIssue, ghClient); err != nil {
+		return decision{}, err
+	}
+
+	changed := changedFields(cfg, ghIssue, jIssue)
+	d := updatedFields(changed)
+	if len(changed) == 0 {
+		logger.Debugf("Jira issue %s is already up to date!", jIssue.Key)
+		d = unchanged("up to date")
+	} else if keepJiraEdits(cfg, ghIssue, jIssue) {
+		d = unchanged("Jira edits kept")
+	} else {
 		fields := &gojira.IssueFields{}
 		fields.Unknowns = tcontainer.NewMarshalMap()
 
-		fields.Summary = ghIssue.GetTitle()
-		fields.Description = ghIssue.GetBody()
-		fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+		fields.Summary = jiraSummary(cfg, ghIssue)
+		fields.Description = jiraDescription(cfg, ghIssue)
+		if environment, ok := jiraEnvironment(cfg, ghIssue); ok {
+			fields.Environment = environment
+		}
+		if dueDate, ok := jiraDueDate(cfg, ghIssue); ok {
+			fields.Duedate = dueDate
+		}
+		if cfg.HasField(config.GitHubStatus) {
+			fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+		}
 
 		// TODO: Do we actually need to update this? It's not possible to change a
 		//       GitHub issue's reporter.
-		fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+		if cfg.HasField(config.GitHubReporter) {
+			fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), reporter(cfg, ghIssue))
+		}
 
-		labels := githubLabelsToStrSlice(ghIssue.Labels)
-		fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubLabels), labels)
+		if cfg.HasField(config.GitHubLabels) {
+			labels, dropped := syncedLabels(cfg, ghIssue)
+			logDroppedLabels(ghIssue, dropped)
+			fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubLabels), githubLabelsToStrSlice(cfg, labels))
+		}
+
+		// The assignees are always written, so that the field is cleared
+		// once the GitHub issue is unassigned.
+		if cfg.HasField(config.GitHubAssignee) {
+			fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubAssignee), githubAssigneesToStrSlice(ghIssue.Assignees))
+		}
+		if cfg.HasField(config.GitHubComments) {
+			fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubComments), ghIssue.GetComments())
+		}
+		if cfg.HasField(config.GitHubUpdated) && ghIssue.UpdatedAt != nil {
+			fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubUpdated), ghIssue.GetUpdatedAt().Format(dateFormat))
+		}
+		if cfg.HasField(config.GitHubCreated) && ghIssue.CreatedAt != nil {
+			fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubCreated), ghIssue.GetCreatedAt().Format(dateFormat))
+		}
+		// The closing time is cleared once the GitHub issue is reopened. It
+		// is assigned directly, as Set deletes keys set to nil.
+		if cfg.HasField(config.GitHubClosed) {
+			if ghIssue.ClosedAt != nil {
+				fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubClosed), ghIssue.GetClosedAt().Format(dateFormat))
+			} else {
+				fields.Unknowns[cfg.GetFieldKey(config.GitHubClosed)] = nil
+			}
+		}
+		if cfg.HasField(config.GitHubAuthorAssociation) {
+			fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubAuthorAssociation), ghIssue.GetAuthorAssociation())
+		}
+		if cfg.HasField(config.GitHubRepository) {
+			fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubRepository), repository(cfg))
+		}
+		if syncLabelColors(cfg) {
+			fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubLabelColors), labelColors(cfg, ghIssue))
+		}
+		// The version is only written along with other changes, and is not
+		// compared, so that upgrading does not update every issue.
+		if cfg.HasField(config.SyncVersion) {
+			fields.Unknowns.Set(cfg.GetFieldKey(config.SyncVersion), syncVersion())
+		}
 
-		fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubLastSync), time.Now().Format(dateFormat))
+		if cfg.HasField(config.GitHubLastSync) {
+			fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubLastSync), time.Now().Format(dateFormat))
+		}
 
 		fields.Type = jIssue.Fields.Type
 
@@ -215,29 +647,85 @@ func UpdateIssue(
 			ID:     jIssue.ID,
 		}
 
+		if label := cfg.GetSyncedLabel(); label != "" && !hasLabel(jIssue, label) {
+			issue.Fields.Labels = append(append([]string{}, jIssue.Fields.Labels...), label)
+		}
+
 		missingComponents := GetMissingComponents(cfg, jIssue)
 		issue.Fields.Components = append(issue.Fields.Components, missingComponents...)
 
+		if cfg.ShouldSyncMilestones() {
+			versions, err := fixVersions(ghIssue, jClient)
+			if err != nil {
+				return decision{}, err
+			}
+			issue.Fields.FixVersions = versions
+		}
+		if err := setSprint(cfg, ghIssue, issue.Fields, jClient); err != nil {
+			return decision{}, err
+		}
+
+		if cfg.IsDryRun() {
+			logger.Infof("Changes to Jira issue %s:\n%s", jIssue.Key, DiffIssue(cfg, jIssue, issue))
+		}
+
 		_, err := jClient.UpdateIssue(issue)
 		if err != nil {
-			return fmt.Errorf("updating Jira issue: %w", err)
+			return decision{}, fmt.Errorf("updating Jira issue: %w", err)
 		}
 
-		log.Debugf("Successfully updated Jira issue %s!", jIssue.Key)
-	} else {
-		log.Debugf("Jira issue %s is already up to date!", jIssue.Key)
+		// Empty fix versions are omitted from updates, so they are cleared
+		// separately when the milestone of the GitHub issue was removed.
+		if cfg.ShouldSyncMilestones() && ghIssue.Milestone == nil && len(jIssue.Fields.FixVersions) > 0 {
+			if err := jClient.ClearFixVersions(issue); err != nil {
+				return decision{}, fmt.Errorf("clearing fix versions of Jira issue %s: %w", jIssue.Key, err)
+			}
+		}
+
+		if !cfg.IsDryRun() {
+			metrics.IssuesUpdated.Inc()
+		}
+		logger.Debugf("Successfully updated Jira issue %s!", jIssue.Key)
 	}
 
 	foundIssue, err := jClient.GetIssue(jIssue.Key)
 	if err != nil {
-		return fmt.Errorf("getting Jira issue %s: %w", jIssue.Key, err)
+		return decision{}, fmt.Errorf("getting Jira issue %s: %w", jIssue.Key, err)
+	}
+
+	if err := transitionIssue(cfg, ghIssue, previousState, foundIssue, jClient); err != nil {
+		return decision{}, err
 	}
 
 	if err := comment.Compare(cfg, ghIssue, foundIssue, ghClient, jClient); err != nil {
-		return fmt.Errorf("comparing comments for issue %s: %w", jIssue.Key, err)
+		return decision{}, fmt.Errorf("comparing comments for issue %s: %w", jIssue.Key, err)
 	}
 
-	return nil
+	if err := comment.CompareAssignee(cfg, ghIssue, foundIssue, jClient); err != nil {
+		return decision{}, fmt.Errorf("comparing assignee for issue %s: %w", jIssue.Key, err)
+	}
+
+	if err := comment.CompareTimeline(cfg, ghIssue, foundIssue, ghClient, jClient); err != nil {
+		return decision{}, fmt.Errorf("comparing timeline for issue %s: %w", jIssue.Key, err)
+	}
+
+	if err := writeMarker(cfg, ghIssue, jIssue.Key, ghClient); err != nil {
+		return decision{}, err
+	}
+
+	if cfg.ShouldLinkDuplicates() {
+		if err := linkDuplicate(cfg, ghIssue, foundIssue, ghClient, jClient); err != nil {
+			return decision{}, fmt.Errorf("linking duplicate issue %s: %w", jIssue.Key, err)
+		}
+	}
+
+	if cfg.ShouldLinkClosingPullRequests() {
+		if err := linkClosingPullRequest(cfg, ghIssue, foundIssue, ghClient, jClient); err != nil {
+			return decision{}, fmt.Errorf("linking closing pull request to issue %s: %w", jIssue.Key, err)
+		}
+	}
+
+	return d, nil
 }
 
 // GetMissingComponents compares configurated components with the Jira issue
@@ -267,31 +755,105 @@ func GetMissingComponents(cfg *config.Config, jIssue *gojira.Issue) []*gojira.Co
 // CreateIssue generates a Jira issue from the various fields on the given GitHub issue, then
 // sends it to the Jira API.
 func CreateIssue(cfg *config.Config, issue *gogh.Issue, ghClient github.Client, jClient jira.Client) error {
-	log.Debugf("Creating Jira issue based on GitHub issue #%d", *issue.Number)
+	logger := cfg.IssueLogger(issue.GetNumber(), "")
+	logger.Debugf("Creating Jira issue based on GitHub issue #%d", *issue.Number)
+
+	if err := resolveReporter(cfg, issue, ghClient); err != nil {
+		return err
+	}
 
 	unknowns := tcontainer.NewMarshalMap()
 
 	unknowns.Set(cfg.GetFieldKey(config.GitHubID), issue.GetID())
-	unknowns.Set(cfg.GetFieldKey(config.GitHubNumber), issue.GetNumber())
-	unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), issue.GetState())
-	unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), issue.User.GetLogin())
+	if cfg.HasField(config.GitHubNumber) {
+		unknowns.Set(cfg.GetFieldKey(config.GitHubNumber), issue.GetNumber())
+	}
+	if cfg.HasField(config.GitHubStatus) {
+		unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), issue.GetState())
+	}
+	if cfg.HasField(config.GitHubReporter) {
+		unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), reporter(cfg, issue))
+	}
 
-	labels := githubLabelsToStrSlice(issue.Labels)
-	unknowns.Set(cfg.GetFieldKey(config.GitHubLabels), labels)
+	// The issue type is chosen from all of the labels, including those
+	// dropped beyond `max-labels`.
+	labels := transformedLabels(cfg, issue.Labels)
+	if cfg.HasField(config.GitHubLabels) {
+		synced, dropped := syncedLabels(cfg, issue)
+		logDroppedLabels(issue, dropped)
+		unknowns.Set(cfg.GetFieldKey(config.GitHubLabels), githubLabelsToStrSlice(cfg, synced))
+	}
 
-	unknowns.Set(cfg.GetFieldKey(config.GitHubLastSync), time.Now().Format(dateFormat))
+	if cfg.HasField(config.GitHubAssignee) {
+		unknowns.Set(cfg.GetFieldKey(config.GitHubAssignee), githubAssigneesToStrSlice(issue.Assignees))
+	}
+	if cfg.HasField(config.GitHubComments) {
+		unknowns.Set(cfg.GetFieldKey(config.GitHubComments), issue.GetComments())
+	}
+	if cfg.HasField(config.GitHubUpdated) && issue.UpdatedAt != nil {
+		unknowns.Set(cfg.GetFieldKey(config.GitHubUpdated), issue.GetUpdatedAt().Format(dateFormat))
+	}
+	if cfg.HasField(config.GitHubCreated) && issue.CreatedAt != nil {
+		unknowns.Set(cfg.GetFieldKey(config.GitHubCreated), issue.GetCreatedAt().Format(dateFormat))
+	}
+	if cfg.HasField(config.GitHubClosed) && issue.ClosedAt != nil {
+		unknowns.Set(cfg.GetFieldKey(config.GitHubClosed), issue.GetClosedAt().Format(dateFormat))
+	}
+	if cfg.HasField(config.GitHubAuthorAssociation) {
+		unknowns.Set(cfg.GetFieldKey(config.GitHubAuthorAssociation), issue.GetAuthorAssociation())
+	}
+	if cfg.HasField(config.GitHubRepository) {
+		unknowns.Set(cfg.GetFieldKey(config.GitHubRepository), repository(cfg))
+	}
+	if syncLabelColors(cfg) {
+		unknowns.Set(cfg.GetFieldKey(config.GitHubLabelColors), labelColors(cfg, issue))
+	}
+	if cfg.HasField(config.SyncVersion) {
+		unknowns.Set(cfg.GetFieldKey(config.SyncVersion), syncVersion())
+	}
+
+	if cfg.HasField(config.GitHubLastSync) {
+		unknowns.Set(cfg.GetFieldKey(config.GitHubLastSync), time.Now().Format(dateFormat))
+	}
 
 	fields := &gojira.IssueFields{
 		Type: gojira.IssueType{
-			Name: "Task", // TODO: Determine issue type
+			Name: cfg.GetIssueTypeForLabels(labels),
 		},
 		Project:     *cfg.GetProject(),
-		Summary:     issue.GetTitle(),
-		Description: issue.GetBody(),
+		Summary:     jiraSummary(cfg, issue),
+		Description: jiraDescription(cfg, issue),
 		Unknowns:    unknowns,
 		Components:  cfg.GetJiraComponents(),
 	}
 
+	if label := cfg.GetSyncedLabel(); label != "" {
+		fields.Labels = []string{label}
+	}
+
+	if accountID := cfg.GetJiraReporter(); accountID != "" {
+		fields.Reporter = &gojira.User{AccountID: accountID}
+	}
+
+	if environment, ok := jiraEnvironment(cfg, issue); ok {
+		fields.Environment = environment
+	}
+
+	if dueDate, ok := jiraDueDate(cfg, issue); ok {
+		fields.Duedate = dueDate
+	}
+
+	if cfg.ShouldSyncMilestones() {
+		versions, err := fixVersions(issue, jClient)
+		if err != nil {
+			return err
+		}
+		fields.FixVersions = versions
+	}
+	if err := setSprint(cfg, issue, fields, jClient); err != nil {
+		return err
+	}
+
 	jIssue := &gojira.Issue{
 		Fields: fields,
 	}
@@ -305,18 +867,32 @@ func CreateIssue(cfg *config.Config, issue *gogh.Issue, ghClient github.Client,
 	if cfg.IsDryRun() {
 		return nil
 	}
+	metrics.IssuesCreated.Inc()
 
 	foundIssue, err := jClient.GetIssue(newIssue.Key)
 	if err != nil {
 		return fmt.Errorf("getting Jira issue %s: %w", newIssue.Key, err)
 	}
 
-	log.Debugf("Created Jira issue %s!", newIssue.Key)
+	logger = cfg.IssueLogger(issue.GetNumber(), newIssue.Key)
+	logger.Debugf("Created Jira issue %s!", newIssue.Key)
+
+	if err := writeMarker(cfg, issue, newIssue.Key, ghClient); err != nil {
+		return err
+	}
 
 	if err := comment.Compare(cfg, issue, foundIssue, ghClient, jClient); err != nil {
 		return fmt.Errorf("comparing comments for issue %s: %w", jIssue.Key, err)
 	}
 
+	if err := comment.CompareAssignee(cfg, issue, foundIssue, jClient); err != nil {
+		return fmt.Errorf("comparing assignee for issue %s: %w", jIssue.Key, err)
+	}
+
+	if err := comment.CompareTimeline(cfg, issue, foundIssue, ghClient, jClient); err != nil {
+		return fmt.Errorf("comparing timeline for issue %s: %w", jIssue.Key, err)
+	}
+
 	return nil
 }
 
@@ -324,22 +900,289 @@ func CreateIssue(cfg *config.Config, issue *gogh.Issue, ghClient github.Client,
 // which is returned by the GitHub API) to a slice of strings, which can be
 // supplied as a value for the `GitHub Labels` custom field.
 //
-// It also converts spaces (' ') to hyphens ('-'), as the Jira `labels` custom
-// field type does not support spaces.
+// The names are normalized by normalizeLabel, which replaces whitespace with
+// the configured replacement (hyphens ('-') by default), as the Jira `labels`
+// custom field type does not support spaces, then transformed and prefixed
+// with the `label-prefix`. The same labels are written by CreateIssue and
+// UpdateIssue and compared by ChangedFields.
 //
 // TODO(github): Consider github.IssueRequest.GetLabels() here.
-func githubLabelsToStrSlice(ghLabels []*gogh.Label) []string {
+func githubLabelsToStrSlice(cfg *config.Config, ghLabels []*gogh.Label) []string {
+	prefix := cfg.GetLabelPrefix()
+
+	labels := transformedLabels(cfg, ghLabels)
+	for i := range labels {
+		labels[i] = prefix + labels[i]
+	}
+
+	return labels
+}
+
+// transformedLabels returns the names of GitHub labels as matched by the
+// `label-type-map`: normalized and transformed, but without the
+// `label-prefix`.
+func transformedLabels(cfg *config.Config, ghLabels []*gogh.Label) []string {
+	replacement := cfg.GetLabelSpaceReplacement()
+
 	labels := make([]string, len(ghLabels))
 	for i, l := range ghLabels {
-		jiraLabel := l.GetName()
-
-		// Converts spaces (' ') to hyphens ('-'), as the Jira `labels` custom
-		// field type does not support spaces.
-		// TODO(labels): Consider a normalization function for all values not
-		//               supported.
-		jiraLabel = strings.ReplaceAll(jiraLabel, " ", "-")
-		labels[i] = jiraLabel
+		jiraLabel := normalizeLabel(l.GetName(), replacement)
+		labels[i] = cfg.TransformField(config.CustomFieldNameGitHubLabels, jiraLabel)
 	}
 
 	return labels
 }
+
+// normalizeLabel returns the name of a GitHub label as a Jira label, which
+// may not contain whitespace: each whitespace character is replaced with
+// replacement, and control and invisible formatting characters, such as
+// zero-width spaces, are removed. Other characters, including punctuation
+// and non-ASCII letters, are kept as they are.
+func normalizeLabel(s, replacement string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteString(replacement)
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// syncedLabels returns the labels of the GitHub issue written to its Jira
+// issue, and those dropped. If the issue has more than `max-labels`, the
+// first of them in `label-order` are kept, as Jira limits the number of
+// labels of an issue.
+func syncedLabels(cfg *config.Config, ghIssue *gogh.Issue) ([]*gogh.Label, []*gogh.Label) {
+	labels := ghIssue.Labels
+
+	limit := cfg.GetMaxLabels()
+	if limit == 0 || len(labels) <= limit {
+		return labels, nil
+	}
+
+	if cfg.GetLabelOrder() == options.LabelOrderName {
+		labels = slices.Clone(labels)
+		sort.SliceStable(labels, func(i, j int) bool {
+			return labels[i].GetName() < labels[j].GetName()
+		})
+	}
+
+	return labels[:limit], labels[limit:]
+}
+
+// logDroppedLabels warns about the labels of the GitHub issue which are not
+// written to its Jira issue, as it has more than `max-labels`.
+func logDroppedLabels(ghIssue *gogh.Issue, dropped []*gogh.Label) {
+	if len(dropped) == 0 {
+		return
+	}
+
+	names := make([]string, len(dropped))
+	for i, l := range dropped {
+		names[i] = l.GetName()
+	}
+	log.Warnf(
+		"GitHub issue #%d has more than %d labels; not synchronizing %s",
+		ghIssue.GetNumber(),
+		len(ghIssue.Labels)-len(dropped),
+		strings.Join(names, ", "),
+	)
+}
+
+// syncLabelColors returns whether label colors are synchronized: if
+// `sync-label-colors` is set and the `github-label-colors` field exists.
+func syncLabelColors(cfg *config.Config) bool {
+	return cfg.ShouldSyncLabelColors() && cfg.HasField(config.GitHubLabelColors)
+}
+
+// labelColors returns the value of the `github-label-colors` field of the
+// Jira issue of a GitHub issue: the name of each of its labels, as written
+// to the `github-labels` field, with its color, e.g.
+// "bug=#d73a4a, good-first-issue=#7057ff".
+func labelColors(cfg *config.Config, ghIssue *gogh.Issue) string {
+	labels, _ := syncedLabels(cfg, ghIssue)
+	names := githubLabelsToStrSlice(cfg, labels)
+
+	colors := make([]string, len(names))
+	for i, name := range names {
+		colors[i] = fmt.Sprintf("%s=#%s", name, labels[i].GetColor())
+	}
+
+	return strings.Join(colors, ", ")
+}
+
+// repository returns the full name of the GitHub repository being
+// synchronized, e.g. `owner/repo`, which is recorded in the
+// `github-repository` field.
+func repository(cfg *config.Config) string {
+	owner, repo := cfg.GetRepo()
+	return owner + "/" + repo
+}
+
+// reporter returns the login of the user who opened the GitHub issue, or
+// the `default-reporter` if the issue has no user, as is the case for
+// issues of deleted accounts, rendered by the `reporter-format` if it is set,
+// after the `field-transforms` of the reporter.
+func reporter(cfg *config.Config, ghIssue *gogh.Issue) string {
+	user := ghIssue.GetUser()
+	login := user.GetLogin()
+	if login == "" {
+		login = cfg.GetDefaultReporter()
+	}
+
+	value := login
+	if tmpl := cfg.GetReporterFormat(); tmpl != nil {
+		var b strings.Builder
+		err := tmpl.Execute(&b, config.ReporterFields{
+			Login: login,
+			Name:  user.GetName(),
+			URL:   user.GetHTMLURL(),
+		})
+		if err != nil {
+			log.Warnf("Rendering the reporter of GitHub issue #%d: %v; using the login", ghIssue.GetNumber(), err)
+		} else {
+			value = strings.TrimSpace(b.String())
+		}
+	}
+
+	return cfg.TransformField(config.CustomFieldNameGitHubReporter, value)
+}
+
+// resolveReporter replaces the author of the GitHub issue with the full
+// GitHub user if the `reporter-format` renders their name, which the issues
+// listed by GitHub do not include.
+func resolveReporter(cfg *config.Config, ghIssue *gogh.Issue, ghClient github.Client) error {
+	if !cfg.HasField(config.GitHubReporter) || !cfg.ReporterFormatUsesName() {
+		return nil
+	}
+
+	login := ghIssue.GetUser().GetLogin()
+	if login == "" || ghIssue.GetUser().Name != nil {
+		return nil
+	}
+
+	user, err := ghClient.GetUser(login)
+	if err != nil {
+		return fmt.Errorf("getting GitHub user %s: %w", login, err)
+	}
+	ghIssue.User = user
+
+	return nil
+}
+
+// jiraSummary returns the summary of the Jira issue of a GitHub issue: its
+// title, after the `field-transforms` of the summary.
+func jiraSummary(cfg *config.Config, ghIssue *gogh.Issue) string {
+	return cfg.TransformField("summary", ghIssue.GetTitle())
+}
+
+// githubAssigneesToStrSlice converts a slice of GitHub users to a slice of
+// their logins, which can be supplied as a value for the `GitHub Assignee`
+// custom field.
+func githubAssigneesToStrSlice(ghAssignees []*gogh.User) []string {
+	logins := make([]string, len(ghAssignees))
+	for i, u := range ghAssignees {
+		logins[i] = u.GetLogin()
+	}
+
+	return logins
+}
+
+// toInt converts the value of a Jira number custom field, which is a float64
+// when decoded from the Jira API, to an int. A missing value is 0.
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+// toStrSlice converts the value of a Jira labels custom field, which is a
+// []interface{} when decoded from the Jira API, to a slice of strings. Values
+// written by older versions as a comma-separated string are split.
+func toStrSlice(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+		return strings.Split(v, ",")
+	case []interface{}:
+		s := make([]string, 0, len(v))
+		for _, e := range v {
+			if str, ok := e.(string); ok {
+				s = append(s, str)
+			}
+		}
+		return s
+	default:
+		return nil
+	}
+}
+
+// syncVersion returns the version of this tool, as recorded in the
+// `sync-version` field of the Jira issues it writes.
+func syncVersion() string {
+	return version.GetVersionInfo().GitVersion
+}
+
+// timeFieldChanged returns whether the time custom field of the Jira issue
+// with the given key differs from a time of the GitHub issue, e.g. its
+// update time. Jira stores the time with a different precision and time
+// zone, so it is compared as a time.
+func timeFieldChanged(jIssue *gojira.Issue, key string, ghTime *gogh.Timestamp) bool {
+	value, _ := jira.FieldString(jIssue, key) //nolint:errcheck
+	if ghTime == nil {
+		return value != ""
+	}
+
+	jiraTime, err := time.Parse(options.DateFormat, value)
+	if err != nil {
+		return true
+	}
+
+	return !jiraTime.Equal(ghTime.Time.Truncate(time.Second))
+}
+
+// sameStrings returns whether a and b hold the same strings, in any order.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	counts := make(map[string]int, len(a))
+	for _, s := range a {
+		counts[s]++
+	}
+	for _, s := range b {
+		if counts[s] == 0 {
+			return false
+		}
+		counts[s]--
+	}
+
+	return true
+}
+
+// hasLabel returns whether the Jira issue has the label.
+func hasLabel(jIssue *gojira.Issue, label string) bool {
+	for _, l := range jIssue.Fields.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
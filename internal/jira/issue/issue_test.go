@@ -0,0 +1,1553 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/trivago/tgo/tcontainer"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+// newJiraIssue returns a Jira issue with the given key whose GitHub ID
+// custom field is set to ghID, as it would be returned by the Jira API.
+func newJiraIssue(cfg *config.Config, key string, ghID int64) gojira.Issue {
+	unknowns := tcontainer.NewMarshalMap()
+	unknowns.Set(cfg.GetFieldKey(config.GitHubID), float64(ghID))
+
+	return gojira.Issue{
+		Key: key,
+		Fields: &gojira.IssueFields{
+			Unknowns: unknowns,
+		},
+	}
+}
+
+func TestSyncedLabel(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:     true,
+		options.ConfigKeySyncedLabel: "gh-synced",
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	var created, updated *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+		UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			updated = issue
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(created.Fields.Labels, []string{"gh-synced"}) {
+		t.Fatalf("Expected created issue to have label gh-synced; got %v", created.Fields.Labels)
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+
+	// The label was removed in Jira, along with a label added by a human.
+	jIssue.Fields.Labels = []string{"triaged"}
+	if !DidIssueChange(cfg, ghIssue, &jIssue) {
+		t.Fatal("Expected issue without the synced label to be reported as changed")
+	}
+
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+	if updated == nil || !reflect.DeepEqual(updated.Fields.Labels, []string{"triaged", "gh-synced"}) {
+		t.Fatalf("Expected updated issue to keep its labels and regain gh-synced; got %v", updated)
+	}
+
+	jIssue.Fields.Labels = updated.Fields.Labels
+	if DidIssueChange(cfg, ghIssue, &jIssue) {
+		t.Fatal("Expected issue with the synced label not to be reported as changed")
+	}
+}
+
+func TestGithubLabelsToStrSlice(t *testing.T) {
+	ghLabels := []*gogh.Label{
+		{Name: gogh.String("good first issue")},
+		{Name: gogh.String("bug")},
+	}
+
+	tests := []struct {
+		name     string
+		values   map[string]interface{}
+		expected []string
+	}{
+		{
+			name:     "default",
+			expected: []string{"good-first-issue", "bug"},
+		},
+		{
+			name:     "hyphen",
+			values:   map[string]interface{}{options.ConfigKeyLabelSpaceReplacement: "-"},
+			expected: []string{"good-first-issue", "bug"},
+		},
+		{
+			name:     "underscore",
+			values:   map[string]interface{}{options.ConfigKeyLabelSpaceReplacement: "_"},
+			expected: []string{"good_first_issue", "bug"},
+		},
+		{
+			name:     "empty",
+			values:   map[string]interface{}{options.ConfigKeyLabelSpaceReplacement: ""},
+			expected: []string{"goodfirstissue", "bug"},
+		},
+		{
+			name:     "prefix",
+			values:   map[string]interface{}{options.ConfigKeyLabelPrefix: "gh/"},
+			expected: []string{"gh/good-first-issue", "gh/bug"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := configtest.NewConfig(context.Background(), tc.values)
+			if labels := githubLabelsToStrSlice(cfg, ghLabels); !reflect.DeepEqual(labels, tc.expected) {
+				t.Fatalf("Expected labels %v; got %v", tc.expected, labels)
+			}
+		})
+	}
+}
+
+func TestDidIssueChangeLogsChangedFields(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	level := log.GetLevel()
+	log.SetLevel(log.DebugLevel)
+	defer log.SetLevel(level)
+
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken on mobile"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = "Login page is broken"
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); !reflect.DeepEqual(changed, []string{"summary"}) {
+		t.Fatalf("Expected only the summary to have changed; got %v", changed)
+	}
+
+	if !DidIssueChange(cfg, ghIssue, &jIssue) {
+		t.Fatal("Expected issue with a new title to be reported as changed")
+	}
+
+	expected := "Jira issue TEST-1 differs from GitHub issue #1 in fields: summary"
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == log.DebugLevel && entry.Message == expected {
+			return
+		}
+	}
+	t.Fatalf("Expected debug log %q", expected)
+}
+
+func TestUpdateIssueRespectsJiraUpdates(t *testing.T) {
+	lastSync := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	jiraEdit := lastSync.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		ghUpdated time.Time
+		updated   bool
+	}{
+		{name: "Jira edited after sync", ghUpdated: lastSync.Add(-time.Hour), updated: false},
+		{name: "GitHub changed after Jira edit", ghUpdated: jiraEdit.Add(time.Hour), updated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+				options.ConfigKeyConfirm:            true,
+				options.ConfigKeyRespectJiraUpdates: true,
+				options.ConfigKeySyncUpdateWindow:   time.Minute,
+			})
+
+			ghIssue := &gogh.Issue{
+				ID:        gogh.Int64(1001),
+				Number:    gogh.Int(1),
+				Title:     gogh.String("Login page is broken"),
+				State:     gogh.String("open"),
+				User:      &gogh.User{Login: gogh.String("octocat")},
+				UpdatedAt: &gogh.Timestamp{Time: tt.ghUpdated},
+			}
+
+			// A human renamed the Jira issue an hour after it was synchronized.
+			jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+			jIssue.Fields.Summary = "Login page is broken on Safari"
+			jIssue.Fields.Updated = gojira.Time(jiraEdit)
+			jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubLastSync), lastSync.Format(dateFormat))
+
+			updated := false
+			jClient := &jira.JiraClientMock{
+				UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+					updated = true
+					return issue, nil
+				},
+			}
+
+			if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+				t.Fatalf("UpdateIssue() returned error: %v", err)
+			}
+			if updated != tt.updated {
+				t.Fatalf("Expected Jira issue updated = %v; got %v", tt.updated, updated)
+			}
+		})
+	}
+}
+
+func TestAssigneeChangesAreSynced(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+		Assignees: []*gogh.User{
+			{Login: gogh.String("bilbo-baggins")},
+			{Login: gogh.String("frodo-baggins")},
+		},
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+
+	// Assignees decoded from the Jira API, in another order.
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubAssignee), []interface{}{"frodo-baggins", "bilbo-baggins"})
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubAssignee), []interface{}{"bilbo-baggins"})
+	changed := ChangedFields(cfg, ghIssue, &jIssue)
+	if !reflect.DeepEqual(changed, []string{config.CustomFieldNameGitHubAssignee}) {
+		t.Fatalf("Expected only the assignee to have changed; got %v", changed)
+	}
+
+	var updated *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			updated = issue
+			return issue, nil
+		},
+	}
+
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+
+	assignees := updated.Fields.Unknowns[cfg.GetFieldKey(config.GitHubAssignee)]
+	if expected := []string{"bilbo-baggins", "frodo-baggins"}; !reflect.DeepEqual(assignees, expected) {
+		t.Fatalf("Expected assignees %v; got %v", expected, assignees)
+	}
+}
+
+func TestUnassignmentIsSynced(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+	})
+
+	// The GitHub issue was unassigned since the last synchronization.
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubAssignee), []interface{}{"bilbo-baggins"})
+
+	changed := ChangedFields(cfg, ghIssue, &jIssue)
+	if !reflect.DeepEqual(changed, []string{config.CustomFieldNameGitHubAssignee}) {
+		t.Fatalf("Expected only the assignee to have changed; got %v", changed)
+	}
+
+	var updated *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			updated = issue
+			return issue, nil
+		},
+	}
+
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+
+	// The field is cleared with an empty list, rather than left out.
+	assignees, ok := updated.Fields.Unknowns[cfg.GetFieldKey(config.GitHubAssignee)]
+	if !ok || !reflect.DeepEqual(assignees, []string{}) {
+		t.Fatalf("Expected the assignees to be cleared; got %#v", assignees)
+	}
+
+	// Once cleared in Jira, the unassigned issue is up to date.
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubAssignee), nil)
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+}
+
+func TestChangedFieldsComparesLabels(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	ghLabels := func(names ...string) []*gogh.Label {
+		labels := make([]*gogh.Label, len(names))
+		for i, name := range names {
+			labels[i] = &gogh.Label{Name: gogh.String(name)}
+		}
+		return labels
+	}
+
+	tests := []struct {
+		name       string
+		ghLabels   []*gogh.Label
+		jiraLabels interface{}
+		changed    bool
+	}{
+		{name: "unchanged", ghLabels: ghLabels("bug", "ui"), jiraLabels: []interface{}{"bug", "ui"}, changed: false},
+		{name: "reordered", ghLabels: ghLabels("ui", "bug"), jiraLabels: []interface{}{"bug", "ui"}, changed: false},
+		{name: "added", ghLabels: ghLabels("bug", "ui", "p1"), jiraLabels: []interface{}{"bug", "ui"}, changed: true},
+		{name: "removed", ghLabels: ghLabels("bug"), jiraLabels: []interface{}{"bug", "ui"}, changed: true},
+		{name: "all removed", ghLabels: nil, jiraLabels: []interface{}{"bug"}, changed: true},
+		{name: "replaced", ghLabels: ghLabels("bug", "p1"), jiraLabels: []interface{}{"bug", "ui"}, changed: true},
+		{name: "written by this tool", ghLabels: ghLabels("bug", "ui"), jiraLabels: []string{"ui", "bug"}, changed: false},
+		{name: "legacy comma-separated", ghLabels: ghLabels("bug", "ui"), jiraLabels: "bug,ui", changed: false},
+		{name: "never set", ghLabels: nil, jiraLabels: nil, changed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ghIssue := &gogh.Issue{
+				ID:     gogh.Int64(1001),
+				Number: gogh.Int(1),
+				Title:  gogh.String("Login page is broken"),
+				State:  gogh.String("open"),
+				User:   &gogh.User{Login: gogh.String("octocat")},
+				Labels: tt.ghLabels,
+			}
+
+			jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+			jIssue.Fields.Summary = ghIssue.GetTitle()
+			jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+			jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+			if tt.jiraLabels != nil {
+				jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubLabels), tt.jiraLabels)
+			}
+
+			var expected []string
+			if tt.changed {
+				expected = []string{config.CustomFieldNameGitHubLabels}
+			}
+			if changed := ChangedFields(cfg, ghIssue, &jIssue); !reflect.DeepEqual(changed, expected) {
+				t.Fatalf("Expected changed fields %v; got %v", expected, changed)
+			}
+		})
+	}
+}
+
+func TestUpdateIssueTransitionsOnStateChange(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+		options.ConfigKeyStatusTransitionMap: map[string]string{
+			"closed": "Done",
+			"open":   "Reopen",
+		},
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("closed"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	var transitions []string
+	jClient := &jira.JiraClientMock{
+		GetTransitionsFn: func(issueKey string) ([]gojira.Transition, error) {
+			return []gojira.Transition{
+				{ID: "11", Name: "Start Progress"},
+				{ID: "31", Name: "done"},
+			}, nil
+		},
+		DoTransitionFn: func(issue *gojira.Issue, transitionID string) error {
+			transitions = append(transitions, transitionID)
+			return nil
+		},
+	}
+
+	for _, previousState := range []string{"open", "closed"} {
+		jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+		jIssue.Fields.Summary = ghIssue.GetTitle()
+		jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), previousState)
+		jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+
+		if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+			t.Fatalf("UpdateIssue() returned error: %v", err)
+		}
+	}
+
+	// Only closing the issue transitions it; the issue being closed already does not.
+	if expected := []string{"31"}; !reflect.DeepEqual(transitions, expected) {
+		t.Fatalf("Expected transitions %v; got %v", expected, transitions)
+	}
+
+	// Reopening is not available from the current status.
+	ghIssue.State = gogh.String("open")
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), "closed")
+
+	err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient)
+	if err == nil || !strings.Contains(err.Error(), `transition "Reopen" is not available`) {
+		t.Fatalf("Expected an error naming the unavailable transition; got %v", err)
+	}
+}
+
+func TestSameStrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     []string
+		expected bool
+	}{
+		{name: "no change", a: []string{"bug", "ui"}, b: []string{"bug", "ui"}, expected: true},
+		{name: "reorder", a: []string{"ui", "bug"}, b: []string{"bug", "ui"}, expected: true},
+		{name: "add", a: []string{"bug", "ui"}, b: []string{"bug"}, expected: false},
+		{name: "remove", a: []string{"bug"}, b: []string{"bug", "ui"}, expected: false},
+		{name: "same length, different labels", a: []string{"bug", "bug"}, b: []string{"bug", "ui"}, expected: false},
+		{name: "both empty", a: nil, b: []string{}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := sameStrings(tt.a, tt.b); same != tt.expected {
+				t.Fatalf("Expected sameStrings(%v, %v) = %v; got %v", tt.a, tt.b, tt.expected, same)
+			}
+		})
+	}
+}
+
+func TestIssueWithoutUserUsesDefaultReporter(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:         true,
+		options.ConfigKeyDefaultReporter: "ghost",
+	})
+
+	// GitHub returns no user for issues of deleted accounts.
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+	}
+
+	var created *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+
+	key := cfg.GetFieldKey(config.GitHubReporter)
+	if reporter, _ := created.Fields.Unknowns.String(key); reporter != "ghost" {
+		t.Fatalf("Expected created issue to have the default reporter; got %q", reporter)
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(key, "ghost")
+
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected issue with the default reporter not to be changed; got %v", changed)
+	}
+}
+
+func TestCreateIssueSetsJiraReporter(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:      true,
+		options.ConfigKeyJiraReporter: "service-account",
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	var created *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+
+	if created.Fields.Reporter == nil || created.Fields.Reporter.AccountID != "service-account" {
+		t.Fatalf("Expected the created issue to be reported by the jira-reporter; got %+v", created.Fields.Reporter)
+	}
+	key := cfg.GetFieldKey(config.GitHubReporter)
+	if reporter, _ := created.Fields.Unknowns.String(key); reporter != "octocat" {
+		t.Fatalf("Expected the created issue to keep the GitHub reporter; got %q", reporter)
+	}
+}
+
+func TestCommentCountIsSynced(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:       gogh.Int64(1001),
+		Number:   gogh.Int(1),
+		Title:    gogh.String("Login page is broken"),
+		State:    gogh.String("open"),
+		User:     &gogh.User{Login: gogh.String("octocat")},
+		Comments: gogh.Int(3),
+	}
+
+	var created, updated *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+		UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			updated = issue
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	key := cfg.GetFieldKey(config.GitHubComments)
+	if count := created.Fields.Unknowns[key]; count != 3 {
+		t.Fatalf("Expected created issue to have 3 comments; got %v", count)
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+
+	// Counts decoded from the Jira API are float64.
+	jIssue.Fields.Unknowns.Set(key, float64(3))
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+
+	ghIssue.Comments = gogh.Int(4)
+	changed := ChangedFields(cfg, ghIssue, &jIssue)
+	if !reflect.DeepEqual(changed, []string{config.CustomFieldNameGitHubComments}) {
+		t.Fatalf("Expected only the comment count to have changed; got %v", changed)
+	}
+
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+	if count := updated.Fields.Unknowns[key]; count != 4 {
+		t.Fatalf("Expected updated issue to have 4 comments; got %v", count)
+	}
+}
+
+func TestUpdatedAtIsSynced(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+	})
+
+	updatedAt := time.Date(2023, time.July, 14, 9, 0, 0, 0, time.UTC)
+	ghIssue := &gogh.Issue{
+		ID:        gogh.Int64(1001),
+		Number:    gogh.Int(1),
+		Title:     gogh.String("Login page is broken"),
+		State:     gogh.String("open"),
+		User:      &gogh.User{Login: gogh.String("octocat")},
+		UpdatedAt: &gogh.Timestamp{Time: updatedAt},
+	}
+
+	var created, updated *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+		UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			updated = issue
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	key := cfg.GetFieldKey(config.GitHubUpdated)
+	if value := created.Fields.Unknowns[key]; value != "2023-07-14T09:00:00.0+0000" {
+		t.Fatalf("Expected created issue to be updated at 2023-07-14T09:00:00.0+0000; got %v", value)
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+
+	// The time as returned by the Jira API, in another time zone.
+	jIssue.Fields.Unknowns.Set(key, "2023-07-14T11:00:00.000+0200")
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+
+	ghIssue.UpdatedAt = &gogh.Timestamp{Time: updatedAt.Add(time.Hour)}
+	changed := ChangedFields(cfg, ghIssue, &jIssue)
+	if !reflect.DeepEqual(changed, []string{config.CustomFieldNameGitHubUpdated}) {
+		t.Fatalf("Expected only the update time to have changed; got %v", changed)
+	}
+
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+	if value := updated.Fields.Unknowns[key]; value != "2023-07-14T10:00:00.0+0000" {
+		t.Fatalf("Expected updated issue to be updated at 2023-07-14T10:00:00.0+0000; got %v", value)
+	}
+}
+
+func TestCreatedAndClosedAtAreSynced(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+	})
+
+	createdAt := time.Date(2023, time.July, 14, 9, 0, 0, 0, time.UTC)
+	ghIssue := &gogh.Issue{
+		ID:        gogh.Int64(1001),
+		Number:    gogh.Int(1),
+		Title:     gogh.String("Login page is broken"),
+		State:     gogh.String("open"),
+		User:      &gogh.User{Login: gogh.String("octocat")},
+		CreatedAt: &gogh.Timestamp{Time: createdAt},
+	}
+
+	var created, updated *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+		UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			updated = issue
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	createdKey, closedKey := cfg.GetFieldKey(config.GitHubCreated), cfg.GetFieldKey(config.GitHubClosed)
+	if value := created.Fields.Unknowns[createdKey]; value != "2023-07-14T09:00:00.0+0000" {
+		t.Fatalf("Expected created issue to be created at 2023-07-14T09:00:00.0+0000; got %v", value)
+	}
+	if value, ok := created.Fields.Unknowns[closedKey]; ok {
+		t.Fatalf("Expected created issue not to have a closing time; got %v", value)
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), "closed")
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+	jIssue.Fields.Unknowns.Set(createdKey, "2023-07-14T11:00:00.000+0200")
+
+	ghIssue.State = gogh.String("closed")
+	ghIssue.ClosedAt = &gogh.Timestamp{Time: createdAt.Add(time.Hour)}
+	changed := ChangedFields(cfg, ghIssue, &jIssue)
+	if !reflect.DeepEqual(changed, []string{config.CustomFieldNameGitHubClosed}) {
+		t.Fatalf("Expected only the closing time to have changed; got %v", changed)
+	}
+
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+	if value := updated.Fields.Unknowns[closedKey]; value != "2023-07-14T10:00:00.0+0000" {
+		t.Fatalf("Expected updated issue to be closed at 2023-07-14T10:00:00.0+0000; got %v", value)
+	}
+
+	// Reopening the GitHub issue clears the closing time.
+	jIssue.Fields.Unknowns.Set(closedKey, "2023-07-14T10:00:00.000+0000")
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+	ghIssue.State = gogh.String("open")
+	ghIssue.ClosedAt = nil
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), "open")
+	changed = ChangedFields(cfg, ghIssue, &jIssue)
+	if !reflect.DeepEqual(changed, []string{config.CustomFieldNameGitHubClosed}) {
+		t.Fatalf("Expected only the closing time to have changed; got %v", changed)
+	}
+
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+	if value, ok := updated.Fields.Unknowns[closedKey]; !ok || value != nil {
+		t.Fatalf("Expected the closing time of the updated issue to be cleared; got %v", value)
+	}
+}
+
+func TestSyncVersionIsWritten(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	var created, updated *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+		UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			updated = issue
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	key := cfg.GetFieldKey(config.SyncVersion)
+	if value := created.Fields.Unknowns[key]; value != syncVersion() {
+		t.Fatalf("Expected created issue to record version %q; got %v", syncVersion(), value)
+	}
+
+	// An issue last written by another version is not updated for that alone.
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+	jIssue.Fields.Unknowns.Set(key, "v0.0.1")
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+	if updated != nil {
+		t.Fatalf("Expected no update for a version change alone; got %+v", updated.Fields.Unknowns)
+	}
+
+	ghIssue.Title = gogh.String("Login page is broken on Safari")
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+	if updated == nil {
+		t.Fatal("Expected the changed issue to be updated")
+	}
+	if value := updated.Fields.Unknowns[key]; value != syncVersion() {
+		t.Fatalf("Expected updated issue to record version %q; got %v", syncVersion(), value)
+	}
+}
+
+func TestRepositoryIsRecorded(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	var created, updated *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+		UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			updated = issue
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	key := cfg.GetFieldKey(config.GitHubRepository)
+	if value := created.Fields.Unknowns[key]; value != "test-owner/test-repo" {
+		t.Fatalf("Expected created issue to record repository test-owner/test-repo; got %v", value)
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = "Login page"
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+	if value := updated.Fields.Unknowns[key]; value != "test-owner/test-repo" {
+		t.Fatalf("Expected updated issue to record repository test-owner/test-repo; got %v", value)
+	}
+}
+
+func TestMissingOptionalFieldsAreSkipped(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:        true,
+		options.ConfigKeyOptionalFields: []string{config.CustomFieldNameGitHubReporter, config.CustomFieldNameGitHubLastSync},
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	var created *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	for _, key := range []string{
+		"customfield_",
+		"customfield_" + configtest.FieldIDGitHubReporter,
+		"customfield_" + configtest.FieldIDGitHubLastSync,
+	} {
+		if value, ok := created.Fields.Unknowns[key]; ok {
+			t.Fatalf("Expected %s not to be set on the created issue; got %v", key, value)
+		}
+	}
+	if value := created.Fields.Unknowns[cfg.GetFieldKey(config.GitHubStatus)]; value != "open" {
+		t.Fatalf("Expected the created issue to have status open; got %v", value)
+	}
+
+	// The missing reporter field is not compared.
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+}
+
+func TestAuthorAssociationIsSynced(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:                gogh.Int64(1001),
+		Number:            gogh.Int(1),
+		Title:             gogh.String("Login page is broken"),
+		State:             gogh.String("open"),
+		User:              &gogh.User{Login: gogh.String("octocat")},
+		AuthorAssociation: gogh.String("CONTRIBUTOR"),
+	}
+
+	var created, updated *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+		UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			updated = issue
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	key := cfg.GetFieldKey(config.GitHubAuthorAssociation)
+	if value := created.Fields.Unknowns[key]; value != "CONTRIBUTOR" {
+		t.Fatalf("Expected created issue to have author association CONTRIBUTOR; got %v", value)
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+	jIssue.Fields.Unknowns.Set(key, "CONTRIBUTOR")
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+
+	// The author became a member of the organization.
+	ghIssue.AuthorAssociation = gogh.String("MEMBER")
+	changed := ChangedFields(cfg, ghIssue, &jIssue)
+	if !reflect.DeepEqual(changed, []string{config.CustomFieldNameGitHubAuthorAssociation}) {
+		t.Fatalf("Expected only the author association to have changed; got %v", changed)
+	}
+
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+	if value := updated.Fields.Unknowns[key]; value != "MEMBER" {
+		t.Fatalf("Expected updated issue to have author association MEMBER; got %v", value)
+	}
+}
+
+func TestLabelColorsAreSynced(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:         true,
+		options.ConfigKeySyncLabelColors: true,
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+		Labels: []*gogh.Label{
+			{Name: gogh.String("bug"), Color: gogh.String("d73a4a")},
+			{Name: gogh.String("good first issue"), Color: gogh.String("7057ff")},
+		},
+	}
+
+	var created *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	key := cfg.GetFieldKey(config.GitHubLabelColors)
+	expected := "bug=#d73a4a, good-first-issue=#7057ff"
+	if value := created.Fields.Unknowns[key]; value != expected {
+		t.Fatalf("Expected created issue to have label colors %q; got %v", expected, value)
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubLabels), githubLabelsToStrSlice(cfg, ghIssue.Labels))
+	jIssue.Fields.Unknowns.Set(key, expected)
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+
+	ghIssue.Labels[0].Color = gogh.String("b60205")
+	changed := ChangedFields(cfg, ghIssue, &jIssue)
+	if !reflect.DeepEqual(changed, []string{config.CustomFieldNameGitHubLabelColors}) {
+		t.Fatalf("Expected only the label colors to have changed; got %v", changed)
+	}
+}
+
+func TestLabelPrefix(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:     true,
+		options.ConfigKeyLabelPrefix: "gh/",
+		options.ConfigKeyLabelTypeMap: []map[string]interface{}{
+			{"label": "bug", "type": "Bug"},
+		},
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+		Labels: []*gogh.Label{{Name: gogh.String("bug")}},
+	}
+
+	var created *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	key := cfg.GetFieldKey(config.GitHubLabels)
+	if labels := created.Fields.Unknowns[key]; !reflect.DeepEqual(labels, []string{"gh/bug"}) {
+		t.Fatalf("Expected created issue to have labels [gh/bug]; got %v", labels)
+	}
+	// The `label-type-map` matches the labels without the prefix.
+	if created.Fields.Type.Name != "Bug" {
+		t.Fatalf("Expected created issue to have type Bug; got %q", created.Fields.Type.Name)
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+	jIssue.Fields.Unknowns.Set(key, []interface{}{"gh/bug"})
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+
+	jIssue.Fields.Unknowns.Set(key, []interface{}{"bug"})
+	changed := ChangedFields(cfg, ghIssue, &jIssue)
+	if !reflect.DeepEqual(changed, []string{config.CustomFieldNameGitHubLabels}) {
+		t.Fatalf("Expected only the labels to have changed; got %v", changed)
+	}
+}
+
+func TestNormalizeLabel(t *testing.T) {
+	tests := []struct {
+		name        string
+		label       string
+		replacement string
+		expected    string
+	}{
+		{name: "plain", label: "bug", replacement: "-", expected: "bug"},
+		{name: "spaces", label: "good first issue", replacement: "-", expected: "good-first-issue"},
+		{name: "other whitespace", label: "needs\ttriage\u00a0now\n", replacement: "_", expected: "needs_triage_now_"},
+		{name: "removed spaces", label: "good first issue", replacement: "", expected: "goodfirstissue"},
+		{name: "control characters", label: "p1\x00\x1b", replacement: "-", expected: "p1"},
+		{name: "zero-width characters", label: "wont\u200bfix\ufeff", replacement: "-", expected: "wontfix"},
+		{name: "punctuation", label: "area/ui: v1.2 (beta)!", replacement: "-", expected: "area/ui:-v1.2-(beta)!"},
+		{name: "unicode", label: "größe überprüfen 🐛", replacement: "-", expected: "größe-überprüfen-🐛"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := normalizeLabel(tc.label, tc.replacement); got != tc.expected {
+				t.Fatalf("normalizeLabel(%q, %q) = %q; expected %q", tc.label, tc.replacement, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestChangedFieldsComparesNormalizedLabels(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+		Labels: []*gogh.Label{{Name: gogh.String("needs\u00a0triage\u200b")}},
+	}
+
+	var created *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+	}
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+
+	key := cfg.GetFieldKey(config.GitHubLabels)
+	if labels := created.Fields.Unknowns[key]; !reflect.DeepEqual(labels, []string{"needs-triage"}) {
+		t.Fatalf("Expected the created labels to be normalized; got %q", labels)
+	}
+
+	// Jira returns the labels it was written as a list of strings.
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+	jIssue.Fields.Unknowns.Set(key, []interface{}{"needs-triage"})
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+}
+
+func TestReporterFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+		lookups  int
+	}{
+		{name: "default", format: "", expected: "octocat"},
+		{name: "login only", format: "@{{.Login}}", expected: "@octocat"},
+		{name: "profile link", format: "[{{.Login}}|{{.URL}}]", expected: "[octocat|https://github.com/octocat]"},
+		{name: "name and login", format: "{{.Name}} (@{{.Login}})", expected: "The Octocat (@octocat)", lookups: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+				options.ConfigKeyConfirm:        true,
+				options.ConfigKeyReporterFormat: tc.format,
+			})
+
+			// Listed GitHub issues do not include the name of their author.
+			ghIssue := &gogh.Issue{
+				ID:     gogh.Int64(1001),
+				Number: gogh.Int(1),
+				Title:  gogh.String("Login page is broken"),
+				State:  gogh.String("open"),
+				User: &gogh.User{
+					Login:   gogh.String("octocat"),
+					HTMLURL: gogh.String("https://github.com/octocat"),
+				},
+			}
+
+			var lookups int
+			ghClient := &github.GitHubClientMock{
+				GetUserFn: func(login string) (*gogh.User, error) {
+					lookups++
+					return &gogh.User{
+						Login:   gogh.String(login),
+						Name:    gogh.String("The Octocat"),
+						HTMLURL: gogh.String("https://github.com/" + login),
+					}, nil
+				},
+			}
+
+			var created *gojira.Issue
+			jClient := &jira.JiraClientMock{
+				CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+					created = issue
+					issue.Key = "TEST-1"
+					return issue, nil
+				},
+			}
+
+			if err := CreateIssue(cfg, ghIssue, ghClient, jClient); err != nil {
+				t.Fatalf("CreateIssue() returned error: %v", err)
+			}
+
+			key := cfg.GetFieldKey(config.GitHubReporter)
+			if value := created.Fields.Unknowns[key]; value != tc.expected {
+				t.Fatalf("Expected created issue to have reporter %q; got %v", tc.expected, value)
+			}
+			if lookups != tc.lookups {
+				t.Fatalf("Expected %d GitHub user lookups; got %d", tc.lookups, lookups)
+			}
+
+			// The formatted reporter is up to date.
+			jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+			jIssue.Fields.Summary = ghIssue.GetTitle()
+			jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+			jIssue.Fields.Unknowns.Set(key, tc.expected)
+			if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+				t.Fatalf("Expected no changed fields; got %v", changed)
+			}
+
+			// The login alone, as written before the format was set, is not.
+			if tc.format != "" {
+				jIssue.Fields.Unknowns.Set(key, "octocat")
+				changed := ChangedFields(cfg, ghIssue, &jIssue)
+				if !reflect.DeepEqual(changed, []string{config.CustomFieldNameGitHubReporter}) {
+					t.Fatalf("Expected only the reporter to have changed; got %v", changed)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxLabelsDropsExtraLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    string
+		expected []string
+		dropped  string
+	}{
+		{name: "github order", order: options.LabelOrderGitHub, expected: []string{"ui", "bug"}, dropped: "p1, api"},
+		{name: "name order", order: options.LabelOrderName, expected: []string{"api", "bug"}, dropped: "p1, ui"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hook := logtest.NewGlobal()
+			defer hook.Reset()
+
+			cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+				options.ConfigKeyConfirm:    true,
+				options.ConfigKeyMaxLabels:  2,
+				options.ConfigKeyLabelOrder: tc.order,
+			})
+
+			ghIssue := &gogh.Issue{
+				ID:     gogh.Int64(1001),
+				Number: gogh.Int(1),
+				Title:  gogh.String("Login page is broken"),
+				State:  gogh.String("open"),
+				User:   &gogh.User{Login: gogh.String("octocat")},
+				Labels: []*gogh.Label{
+					{Name: gogh.String("ui")},
+					{Name: gogh.String("bug")},
+					{Name: gogh.String("p1")},
+					{Name: gogh.String("api")},
+				},
+			}
+
+			var created *gojira.Issue
+			jClient := &jira.JiraClientMock{
+				CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+					created = issue
+					issue.Key = "TEST-1"
+					return issue, nil
+				},
+			}
+
+			if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+				t.Fatalf("CreateIssue() returned error: %v", err)
+			}
+
+			key := cfg.GetFieldKey(config.GitHubLabels)
+			if labels := created.Fields.Unknowns[key]; !reflect.DeepEqual(labels, tc.expected) {
+				t.Fatalf("Expected created issue to have labels %v; got %v", tc.expected, labels)
+			}
+
+			var warned bool
+			for _, entry := range hook.AllEntries() {
+				if entry.Level == log.WarnLevel && strings.HasSuffix(entry.Message, "not synchronizing "+tc.dropped) {
+					warned = true
+				}
+			}
+			if !warned {
+				t.Fatalf("Expected a warning about the dropped labels %s", tc.dropped)
+			}
+
+			// The truncated labels are up to date, rather than changed on
+			// every synchronization.
+			jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+			jIssue.Fields.Summary = ghIssue.GetTitle()
+			jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+			jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+			jIssue.Fields.Unknowns.Set(key, created.Fields.Unknowns[key])
+			if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+				t.Fatalf("Expected no changed fields; got %v", changed)
+			}
+		})
+	}
+}
+
+func TestFieldTransformsAreApplied(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+		options.ConfigKeyFieldTransforms: map[string]interface{}{
+			"summary":                          `[GH] {{.}}`,
+			config.CustomFieldNameGitHubLabels: `{{upper .}}`,
+		},
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+		Labels: []*gogh.Label{{Name: gogh.String("bug")}},
+	}
+
+	var created *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	if created.Fields.Summary != "[GH] Login page is broken" {
+		t.Fatalf("Expected transformed summary; got %q", created.Fields.Summary)
+	}
+	labels := created.Fields.Unknowns[cfg.GetFieldKey(config.GitHubLabels)]
+	if !reflect.DeepEqual(labels, []string{"BUG"}) {
+		t.Fatalf("Expected transformed labels; got %v", labels)
+	}
+
+	// The transformed values are compared, so they don't cause updates.
+	if changed := ChangedFields(cfg, ghIssue, created); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+}
+
+func TestUpdateIssueFetchesJiraCommentsOnce(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:      true,
+		options.ConfigKeySyncTimeline: true,
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:       gogh.Int64(1001),
+		Number:   gogh.Int(1),
+		Title:    gogh.String("Login page is broken"),
+		State:    gogh.String("open"),
+		User:     &gogh.User{Login: gogh.String("octocat")},
+		Comments: gogh.Int(2),
+	}
+
+	ghClient := &github.GitHubClientMock{
+		ListCommentsFn: func(owner, repo string, issue *gogh.Issue, since time.Time) ([]*gogh.IssueComment, error) {
+			return []*gogh.IssueComment{
+				{ID: gogh.Int64(1), Body: gogh.String("First"), User: &gogh.User{Login: gogh.String("octocat")}},
+				{ID: gogh.Int64(2), Body: gogh.String("Second"), User: &gogh.User{Login: gogh.String("octocat")}},
+			}, nil
+		},
+	}
+
+	fetched := 0
+	created := 0
+	jClient := &jira.JiraClientMock{
+		GetIssueFn: func(key string) (*gojira.Issue, error) {
+			fetched++
+			return &gojira.Issue{
+				Key: key,
+				Fields: &gojira.IssueFields{
+					Comments: &gojira.Comments{},
+				},
+			}, nil
+		},
+		CreateCommentFn: func(
+			issue *gojira.Issue, comment *gogh.IssueComment, githubClient github.Client,
+		) (*gojira.Comment, error) {
+			created++
+			return &gojira.Comment{}, nil
+		},
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, ghClient, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+
+	if fetched != 1 {
+		t.Fatalf("Expected the Jira comments to be fetched once; got %d fetches", fetched)
+	}
+	if created != 2 {
+		t.Fatalf("Expected 2 comments to be created; got %d", created)
+	}
+}
+
+func TestIndexJiraIssuesByGitHubID(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	jiraIssues := []gojira.Issue{
+		newJiraIssue(cfg, "TEST-1", 1001),
+		// The custom field is not defined in Jira, so Unknowns is nil.
+		{Key: "TEST-2", Fields: &gojira.IssueFields{}},
+		{Key: "TEST-3"},
+		newJiraIssue(cfg, "TEST-4", 1004),
+		// A duplicate of TEST-1, which FindJiraIssue would not return.
+		newJiraIssue(cfg, "TEST-5", 1001),
+	}
+
+	index := indexJiraIssuesByGitHubID(cfg, jiraIssues)
+
+	tests := []struct {
+		name string
+		id   int64
+		key  string
+	}{
+		{name: "first issue", id: 1001, key: "TEST-1"},
+		{name: "last issue", id: 1004, key: "TEST-4"},
+		{name: "no issue", id: 1002, key: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ghIssue := &gogh.Issue{ID: gogh.Int64(tc.id)}
+
+			var key string
+			if jIssue := index.GetIssueByGitHubID(tc.id); jIssue != nil {
+				key = jIssue.Key
+			}
+			if key != tc.key {
+				t.Fatalf("Expected Jira issue %q; got %q", tc.key, key)
+			}
+
+			var found string
+			if jIssue := FindJiraIssue(cfg, ghIssue, jiraIssues); jIssue != nil {
+				found = jIssue.Key
+			}
+			if found != key {
+				t.Fatalf("Expected the index to match FindJiraIssue %q; got %q", found, key)
+			}
+		})
+	}
+
+	if jIssue := index.GetIssueByKey("TEST-2"); jIssue == nil || jIssue.Key != "TEST-2" {
+		t.Fatalf("Expected the issue without custom fields to be indexed by key; got %v", jIssue)
+	}
+}
+
+func TestFindJiraIssueIgnoresFieldKeyCase(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	unknowns := tcontainer.NewMarshalMap()
+	unknowns.Set(strings.ToUpper(cfg.GetFieldKey(config.GitHubID)), float64(1001))
+	jiraIssues := []gojira.Issue{
+		newJiraIssue(cfg, "TEST-1", 1002),
+		{Key: "TEST-2", Fields: &gojira.IssueFields{Unknowns: unknowns}},
+	}
+	ghIssue := &gogh.Issue{ID: gogh.Int64(1001)}
+
+	if jIssue := FindJiraIssue(cfg, ghIssue, jiraIssues); jIssue == nil || jIssue.Key != "TEST-2" {
+		t.Fatalf("Expected Jira issue TEST-2 to be found; got %v", jIssue)
+	}
+
+	index := indexJiraIssuesByGitHubID(cfg, jiraIssues)
+	if jIssue := index.GetIssueByGitHubID(ghIssue.GetID()); jIssue == nil || jIssue.Key != "TEST-2" {
+		t.Fatalf("Expected Jira issue TEST-2 to be indexed; got %v", jIssue)
+	}
+}
+
+func TestChangedFieldsWithoutCustomFields(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	// Jira omits the custom fields, e.g. after they were deleted, so that
+	// the Unknowns of the issue are nil.
+	jIssue := &gojira.Issue{
+		Key:    "TEST-1",
+		Fields: &gojira.IssueFields{Summary: ghIssue.GetTitle()},
+	}
+
+	changed := ChangedFields(cfg, ghIssue, jIssue)
+	expected := []string{config.CustomFieldNameGitHubStatus, config.CustomFieldNameGitHubReporter}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Fatalf("Expected the unset fields %v to have changed; got %v", expected, changed)
+	}
+	if jIssue := FindJiraIssue(cfg, ghIssue, []gojira.Issue{*jIssue}); jIssue != nil {
+		t.Fatalf("Expected no Jira issue to be found; got %s", jIssue.Key)
+	}
+}
+
+func BenchmarkMatchJiraIssues(b *testing.B) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	const n = 1000
+	ghIssues := make([]*gogh.Issue, n)
+	jiraIssues := make([]gojira.Issue, n)
+	for i := range ghIssues {
+		id := int64(1001 + i)
+		ghIssues[i] = &gogh.Issue{ID: gogh.Int64(id)}
+		jiraIssues[i] = newJiraIssue(cfg, fmt.Sprintf("TEST-%d", i+1), id)
+	}
+
+	b.Run("FindJiraIssue", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, ghIssue := range ghIssues {
+				FindJiraIssue(cfg, ghIssue, jiraIssues)
+			}
+		}
+	})
+
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			index := indexJiraIssuesByGitHubID(cfg, jiraIssues)
+			for _, ghIssue := range ghIssues {
+				index.GetIssueByGitHubID(ghIssue.GetID())
+			}
+		}
+	})
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+// markerFormat is the format of the hidden HTML comment written to the body
+// of a GitHub issue to record the key of its Jira issue.
+const markerFormat = "<!-- gh-jira-issue-sync: %s -->"
+
+var (
+	// markerRegex matches the hidden HTML comment written by markerFormat,
+	// including the whitespace separating it from the rest of the body. Its
+	// matching group is the Jira issue key.
+	markerRegex = regexp.MustCompile(`\s*<!--\s*gh-jira-issue-sync:\s*([A-Z][A-Z0-9_]*-\d+)\s*-->`)
+
+	// titleKeyRegex matches a Jira issue key in square brackets at the end of
+	// a GitHub issue title, e.g. "Fix the login page [SYNC-123]". Its
+	// matching group is the Jira issue key.
+	titleKeyRegex = regexp.MustCompile(`\[([A-Z][A-Z0-9_]*-\d+)\]\s*$`)
+)
+
+// JiraKeyFromMarker returns the key of the Jira issue recorded on a GitHub
+// issue, either as a hidden marker comment in its body or as a suffix of its
+// title. Only keys of issues in the given Jira project are returned; the
+// boolean is false if the GitHub issue records no such key.
+func JiraKeyFromMarker(projectKey string, ghIssue *gogh.Issue) (string, bool) {
+	for _, match := range markerRegex.FindAllStringSubmatch(ghIssue.GetBody(), -1) {
+		if isProjectKey(projectKey, match[1]) {
+			return match[1], true
+		}
+	}
+
+	if match := titleKeyRegex.FindStringSubmatch(ghIssue.GetTitle()); match != nil {
+		if isProjectKey(projectKey, match[1]) {
+			return match[1], true
+		}
+	}
+
+	return "", false
+}
+
+// isProjectKey returns whether the Jira issue key belongs to the project.
+func isProjectKey(projectKey, key string) bool {
+	return strings.HasPrefix(key, projectKey+"-")
+}
+
+// withMarker returns the body of a GitHub issue with a hidden marker comment
+// recording the Jira issue key appended, replacing any existing marker.
+func withMarker(body, key string) string {
+	body = stripMarker(body)
+	marker := fmt.Sprintf(markerFormat, key)
+	if body == "" {
+		return marker
+	}
+	return body + "\n\n" + marker
+}
+
+// stripMarker removes any hidden marker comment from the body of a GitHub
+// issue, so that it is not mirrored to the Jira issue description.
+func stripMarker(body string) string {
+	if !markerRegex.MatchString(body) {
+		return body
+	}
+	return strings.TrimRight(markerRegex.ReplaceAllString(body, ""), " \t\r\n")
+}
+
+// findJiraIssueByStrategy returns the Jira issue in index matching the GitHub
+// issue according to the configured match strategy, or nil if there is none.
+//
+// With the github-marker strategy, GitHub issues which do not record a Jira
+// issue key yet are matched using the GitHub ID custom field, so that issues
+// synchronized before the strategy was enabled are not duplicated.
+func findJiraIssueByStrategy(
+	cfg *config.Config,
+	ghIssue *gogh.Issue,
+	index *jiraIssueIndex,
+	jClient jira.Client,
+) (*gojira.Issue, error) {
+	if cfg.GetMatchStrategy() != options.MatchStrategyGitHubMarker {
+		return index.GetIssueByGitHubID(ghIssue.GetID()), nil
+	}
+
+	key, ok := JiraKeyFromMarker(cfg.GetProjectKey(), ghIssue)
+	if !ok {
+		return index.GetIssueByGitHubID(ghIssue.GetID()), nil
+	}
+
+	if jIssue := index.GetIssueByKey(key); jIssue != nil {
+		return jIssue, nil
+	}
+
+	jIssue, err := jClient.GetIssue(key)
+	if err != nil {
+		return nil, fmt.Errorf("getting Jira issue %s recorded on GitHub issue #%d: %w", key, ghIssue.GetNumber(), err)
+	}
+
+	return jIssue, nil
+}
+
+// writeMarker records the Jira issue key on the GitHub issue as a hidden
+// marker comment, if the github-marker match strategy is enabled and the
+// GitHub issue does not already record it.
+func writeMarker(cfg *config.Config, ghIssue *gogh.Issue, key string, ghClient github.Client) error {
+	if cfg.GetMatchStrategy() != options.MatchStrategyGitHubMarker || cfg.IsDryRun() {
+		return nil
+	}
+
+	if current, ok := JiraKeyFromMarker(cfg.GetProjectKey(), ghIssue); ok && current == key {
+		return nil
+	}
+
+	owner, repo := cfg.GetRepo()
+	req := &gogh.IssueRequest{
+		Body: gogh.String(withMarker(ghIssue.GetBody(), key)),
+	}
+
+	if _, err := ghClient.EditIssue(owner, repo, ghIssue.GetNumber(), req); err != nil {
+		return fmt.Errorf("writing Jira issue key %s to GitHub issue #%d: %w", key, ghIssue.GetNumber(), err)
+	}
+
+	log.Debugf("Recorded Jira issue %s on GitHub issue #%d", key, ghIssue.GetNumber())
+
+	return nil
+}
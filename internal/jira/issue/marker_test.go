@@ -0,0 +1,193 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"testing"
+
+	gogh "github.com/google/go-github/v56/github"
+	"github.com/trivago/tgo/tcontainer"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+func TestJiraKeyFromMarker(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		body  string
+		key   string
+		ok    bool
+	}{
+		{
+			name: "marker in body",
+			body: "Steps to reproduce.\n\n<!-- gh-jira-issue-sync: TEST-12 -->",
+			key:  "TEST-12",
+			ok:   true,
+		},
+		{
+			name:  "key in title",
+			title: "Login page is broken [TEST-3]",
+			key:   "TEST-3",
+			ok:    true,
+		},
+		{
+			name:  "marker takes precedence over title",
+			title: "Login page is broken [TEST-3]",
+			body:  "<!--gh-jira-issue-sync:TEST-4-->",
+			key:   "TEST-4",
+			ok:    true,
+		},
+		{
+			name: "key of another project",
+			body: "<!-- gh-jira-issue-sync: OTHER-12 -->",
+		},
+		{
+			name:  "key not at the end of the title",
+			title: "[TEST-3] Login page is broken",
+		},
+		{
+			name: "no marker",
+			body: "Steps to reproduce.",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ghIssue := &gogh.Issue{Title: gogh.String(tc.title), Body: gogh.String(tc.body)}
+			key, ok := JiraKeyFromMarker(configtest.ProjectKey, ghIssue)
+			if key != tc.key || ok != tc.ok {
+				t.Fatalf("Expected (%q, %t); got (%q, %t)", tc.key, tc.ok, key, ok)
+			}
+		})
+	}
+}
+
+func TestWithMarker(t *testing.T) {
+	body := withMarker("Steps to reproduce.", "TEST-1")
+	if expected := "Steps to reproduce.\n\n<!-- gh-jira-issue-sync: TEST-1 -->"; body != expected {
+		t.Fatalf("Expected body %q; got %q", expected, body)
+	}
+
+	body = withMarker(body, "TEST-2")
+	if expected := "Steps to reproduce.\n\n<!-- gh-jira-issue-sync: TEST-2 -->"; body != expected {
+		t.Fatalf("Expected existing marker to be replaced; got %q", body)
+	}
+
+	if stripped := stripMarker(body); stripped != "Steps to reproduce." {
+		t.Fatalf("Expected marker to be stripped; got %q", stripped)
+	}
+}
+
+func TestCompareMatchesByMarker(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:       true,
+		options.ConfigKeyMatchStrategy: options.MatchStrategyGitHubMarker,
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Title:  gogh.String("Login page is broken"),
+		Body:   gogh.String("Steps to reproduce.\n\n<!-- gh-jira-issue-sync: TEST-7 -->"),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			return []*gogh.Issue{ghIssue}, nil
+		},
+		EditIssueFn: func(owner, repo string, number int, req *gogh.IssueRequest) (*gogh.Issue, error) {
+			t.Fatalf("Expected GitHub issue #%d not to be edited", number)
+			return nil, nil
+		},
+	}
+
+	var updated *gojira.Issue
+	jiraClient := &jira.JiraClientMock{
+		GetIssueFn: func(key string) (*gojira.Issue, error) {
+			return &gojira.Issue{
+				Key: key,
+				Fields: &gojira.IssueFields{
+					Unknowns: tcontainer.NewMarshalMap(),
+				},
+			}, nil
+		},
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			t.Fatal("Expected no Jira issue to be created")
+			return nil, nil
+		},
+		UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			updated = issue
+			return issue, nil
+		},
+	}
+
+	if _, err := Compare(context.Background(), cfg, ghClient, jiraClient); err != nil {
+		t.Fatalf("Compare() returned error: %v", err)
+	}
+
+	if updated == nil || updated.Key != "TEST-7" {
+		t.Fatalf("Expected Jira issue TEST-7 to be updated; got %v", updated)
+	}
+	if updated.Fields.Description != "Steps to reproduce." {
+		t.Fatalf("Expected marker to be stripped from description; got %q", updated.Fields.Description)
+	}
+}
+
+func TestCreateIssueWritesMarker(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:       true,
+		options.ConfigKeyMatchStrategy: options.MatchStrategyGitHubMarker,
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:     gogh.Int64(1001),
+		Number: gogh.Int(1),
+		Body:   gogh.String("Steps to reproduce."),
+		State:  gogh.String("open"),
+		User:   &gogh.User{Login: gogh.String("octocat")},
+	}
+
+	var body string
+	ghClient := &github.GitHubClientMock{
+		EditIssueFn: func(owner, repo string, number int, req *gogh.IssueRequest) (*gogh.Issue, error) {
+			body = req.GetBody()
+			return &gogh.Issue{Number: gogh.Int(number), Body: req.Body}, nil
+		},
+	}
+	jiraClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			issue.Key = "TEST-9"
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, ghClient, jiraClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+
+	if expected := "Steps to reproduce.\n\n<!-- gh-jira-issue-sync: TEST-9 -->"; body != expected {
+		t.Fatalf("Expected GitHub issue body %q; got %q", expected, body)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"fmt"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+// versionReleaseDateFormat is the format of the release date of Jira versions.
+const versionReleaseDateFormat = "2006-01-02"
+
+// releaseMilestone releases the Jira version named after the milestone of
+// the GitHub issue if it is closed, and the release of closed milestones is
+// enabled.
+func releaseMilestone(cfg *config.Config, ghIssue *gogh.Issue, jClient jira.Client) error {
+	if !cfg.ShouldReleaseClosedMilestones() {
+		return nil
+	}
+
+	if err := ReleaseMilestoneVersion(cfg, ghIssue.GetMilestone(), jClient); err != nil {
+		return fmt.Errorf("releasing version for issue #%d: %w", ghIssue.GetNumber(), err)
+	}
+
+	return nil
+}
+
+// ReleaseMilestoneVersion marks the Jira version of the configured project
+// whose name is the title of the GitHub milestone as released, if the
+// milestone is closed and the version is not released yet. Milestones
+// without a matching version are ignored.
+func ReleaseMilestoneVersion(cfg *config.Config, milestone *gogh.Milestone, jClient jira.Client) error {
+	if milestone == nil || milestone.GetState() != "closed" {
+		return nil
+	}
+
+	project := cfg.GetProject()
+
+	var version *gojira.Version
+	for i := range project.Versions {
+		if project.Versions[i].Name == milestone.GetTitle() {
+			version = &project.Versions[i]
+			break
+		}
+	}
+
+	if version == nil {
+		log.Debugf("Jira project %s has no version named after milestone %q", project.Key, milestone.GetTitle())
+		return nil
+	}
+
+	if version.Released != nil && *version.Released {
+		return nil
+	}
+
+	released := true
+	update := &gojira.Version{
+		ID:          version.ID,
+		Name:        version.Name,
+		Released:    &released,
+		ReleaseDate: milestone.GetClosedAt().Format(versionReleaseDateFormat),
+	}
+
+	if _, err := jClient.UpdateVersion(update); err != nil {
+		return fmt.Errorf("releasing Jira version %s: %w", version.Name, err)
+	}
+
+	log.Infof("Released Jira version %s, as milestone %q is closed", version.Name, milestone.GetTitle())
+
+	// The project versions are only loaded once, so record the release to
+	// avoid releasing the version again for the other issues of the milestone.
+	version.Released = &released
+
+	return nil
+}
+
+// fixVersions returns the Jira fix versions of the GitHub issue: the version
+// named after its milestone, which is created if it doesn't exist yet, or
+// none if the issue has no milestone.
+func fixVersions(ghIssue *gogh.Issue, jClient jira.Client) ([]*gojira.FixVersion, error) {
+	if ghIssue.Milestone == nil {
+		return nil, nil
+	}
+
+	title := ghIssue.Milestone.GetTitle()
+	version, err := jClient.EnsureVersion(title)
+	if err != nil {
+		return nil, fmt.Errorf("getting Jira version for milestone %q: %w", title, err)
+	}
+
+	return []*gojira.FixVersion{
+		{
+			ID:   version.ID,
+			Name: version.Name,
+		},
+	}, nil
+}
+
+// fixVersionsChanged returns whether the fix versions of the Jira issue differ
+// from the version named after the milestone of the GitHub issue.
+func fixVersionsChanged(ghIssue *gogh.Issue, jIssue *gojira.Issue) bool {
+	var expected []string
+	if ghIssue.Milestone != nil {
+		expected = []string{ghIssue.Milestone.GetTitle()}
+	}
+
+	actual := make([]string, 0, len(jIssue.Fields.FixVersions))
+	for _, version := range jIssue.Fields.FixVersions {
+		actual = append(actual, version.Name)
+	}
+
+	return !sameStrings(expected, actual)
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+func TestCompareReleasesClosedMilestone(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:                 true,
+		options.ConfigKeyReleaseClosedMilestones: true,
+	})
+
+	unreleased := false
+	cfg.GetProject().Versions = []gojira.Version{
+		{ID: "200", Name: "v1.0", Released: &unreleased},
+		{ID: "201", Name: "v1.1", Released: &unreleased},
+	}
+
+	closed := &gogh.Milestone{
+		Title:    gogh.String("v1.0"),
+		State:    gogh.String("closed"),
+		ClosedAt: &gogh.Timestamp{Time: time.Date(2023, time.July, 14, 9, 0, 0, 0, time.UTC)},
+	}
+	open := &gogh.Milestone{
+		Title: gogh.String("v1.1"),
+		State: gogh.String("open"),
+	}
+
+	newIssue := func(number int, milestone *gogh.Milestone) *gogh.Issue {
+		return &gogh.Issue{
+			ID:        gogh.Int64(int64(1000 + number)),
+			Number:    gogh.Int(number),
+			State:     gogh.String("closed"),
+			User:      &gogh.User{Login: gogh.String("octocat")},
+			Milestone: milestone,
+		}
+	}
+
+	ghClient := &github.GitHubClientMock{
+		ListIssuesFn: func(owner, repo string, opts github.ListIssuesOptions) ([]*gogh.Issue, error) {
+			return []*gogh.Issue{newIssue(1, closed), newIssue(2, closed), newIssue(3, open)}, nil
+		},
+	}
+
+	var released []*gojira.Version
+	jiraClient := &jira.JiraClientMock{
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+		UpdateVersionFn: func(version *gojira.Version) (*gojira.Version, error) {
+			released = append(released, version)
+			return version, nil
+		},
+	}
+
+	if _, err := Compare(context.Background(), cfg, ghClient, jiraClient); err != nil {
+		t.Fatalf("Compare() returned error: %v", err)
+	}
+
+	if len(released) != 1 {
+		t.Fatalf("Expected exactly one version to be released; got %d", len(released))
+	}
+	if v := released[0]; v.ID != "200" || v.Released == nil || !*v.Released || v.ReleaseDate != "2023-07-14" {
+		t.Fatalf("Expected version 200 to be released on 2023-07-14; got %+v", v)
+	}
+}
+
+func TestMilestonesAreSyncedToFixVersions(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:        true,
+		options.ConfigKeySyncMilestones: true,
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:        gogh.Int64(1001),
+		Number:    gogh.Int(1),
+		Title:     gogh.String("Login page is broken"),
+		State:     gogh.String("open"),
+		User:      &gogh.User{Login: gogh.String("octocat")},
+		Milestone: &gogh.Milestone{Title: gogh.String("v1.0")},
+	}
+
+	var ensured []string
+	var created, updated, cleared *gojira.Issue
+	jClient := &jira.JiraClientMock{
+		EnsureVersionFn: func(name string) (*gojira.Version, error) {
+			ensured = append(ensured, name)
+			return &gojira.Version{ID: "200", Name: name}, nil
+		},
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+		UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			updated = issue
+			return issue, nil
+		},
+		ClearFixVersionsFn: func(issue *gojira.Issue) error {
+			cleared = issue
+			return nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+
+	expected := []*gojira.FixVersion{{ID: "200", Name: "v1.0"}}
+	if !reflect.DeepEqual(created.Fields.FixVersions, expected) {
+		t.Fatalf("Expected created issue to have fix versions %+v; got %+v", expected, created.Fields.FixVersions)
+	}
+	if !reflect.DeepEqual(ensured, []string{"v1.0"}) {
+		t.Fatalf("Expected version v1.0 to be ensured; got %v", ensured)
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+	jIssue.Fields.FixVersions = []*gojira.FixVersion{{ID: "200", Name: "v1.0"}}
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+
+	ghIssue.Milestone = &gogh.Milestone{Title: gogh.String("v1.1")}
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); !reflect.DeepEqual(changed, []string{"fixVersions"}) {
+		t.Fatalf("Expected only the fix versions to have changed; got %v", changed)
+	}
+
+	ghIssue.Milestone = nil
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); !reflect.DeepEqual(changed, []string{"fixVersions"}) {
+		t.Fatalf("Expected only the fix versions to have changed; got %v", changed)
+	}
+
+	if err := UpdateIssue(cfg, ghIssue, &jIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+
+	if updated == nil || len(updated.Fields.FixVersions) != 0 {
+		t.Fatalf("Expected issue to be updated without fix versions; got %+v", updated)
+	}
+	if cleared == nil || cleared.Key != "TEST-1" {
+		t.Fatalf("Expected fix versions of TEST-1 to be cleared; got %+v", cleared)
+	}
+}
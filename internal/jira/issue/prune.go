@@ -0,0 +1,177 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+// Prune closes the Jira issues which are not done yet and whose GitHub issue
+// was deleted, with the `prune-transition`, and labels them with
+// `prune-label`. It returns the number of pruned issues. Errors pruning a
+// single issue are logged rather than returned, so that the remaining issues
+// are still pruned.
+//
+// Nothing is pruned unless every configured repository can be read, as
+// GitHub answers 404 Not Found for every issue of a repository the token
+// lost access to.
+func Prune(ctx context.Context, cfg *config.Config, ghClient github.Client, jClient jira.Client) (int, error) {
+	if !cfg.HasField(config.GitHubRepository) {
+		log.Warnf(
+			"Optional custom field %s not found; not pruning Jira issues, as their GitHub repository is unknown",
+			config.CustomFieldNameGitHubRepository,
+		)
+		return 0, nil
+	}
+
+	for _, repo := range cfg.GetRepos() {
+		if _, err := ghClient.GetRepository(repo[0], repo[1]); err != nil {
+			return 0, fmt.Errorf("checking GitHub repository %s/%s before pruning: %w", repo[0], repo[1], err)
+		}
+	}
+
+	jiraIssues, err := jClient.ListLinkedIssues()
+	if err != nil {
+		return 0, fmt.Errorf("listing linked Jira issues: %w", err)
+	}
+
+	log.Debugf("Checking %d Jira issues for deleted GitHub issues", len(jiraIssues))
+
+	pruned := 0
+	for i := range jiraIssues {
+		if err := ctx.Err(); err != nil {
+			return pruned, fmt.Errorf("aborting pruning: %w", err)
+		}
+
+		jIssue := &jiraIssues[i]
+
+		deleted, err := isDeleted(cfg, jIssue, ghClient)
+		if err != nil {
+			log.Errorf("Error looking up the GitHub issue of Jira issue %s. Error: %v", jIssue.Key, err)
+			continue
+		}
+		if !deleted {
+			continue
+		}
+
+		log.Infof("The GitHub issue of Jira issue %s was deleted; pruning it", jIssue.Key)
+		if err := pruneIssue(cfg, jIssue, jClient); err != nil {
+			log.Errorf("Error pruning Jira issue %s. Error: %v", jIssue.Key, err)
+			continue
+		}
+		pruned++
+	}
+
+	return pruned, nil
+}
+
+// isDeleted returns whether the GitHub issue of the Jira issue no longer
+// exists in the repository recorded in its `github-repository` field. As
+// GitHub issue numbers are only unique within a repository, an issue with
+// the same number but another ID does not count as existing. Issues of
+// repositories which are not configured, or which were synchronized before
+// the field was added, are never known to be deleted.
+func isDeleted(cfg *config.Config, jIssue *gojira.Issue, ghClient github.Client) (bool, error) {
+	unknowns := jIssue.Fields.Unknowns
+
+	id, _ := unknowns.Value(cfg.GetFieldKey(config.GitHubID))
+	number, _ := unknowns.Value(cfg.GetFieldKey(config.GitHubNumber))
+	ghID, ghNumber := int64(toInt(id)), toInt(number)
+	if ghID == 0 || ghNumber == 0 {
+		// The issue can't be looked up, so it can't be known to be deleted.
+		return false, nil
+	}
+
+	name, _ := jira.FieldString(jIssue, cfg.GetFieldKey(config.GitHubRepository)) //nolint:errcheck
+	repo, ok := configuredRepo(cfg, name)
+	if !ok {
+		log.Debugf("Jira issue %s is not linked to a configured GitHub repo (%q); not pruning it", jIssue.Key, name)
+		return false, nil
+	}
+
+	ghIssue, err := ghClient.GetIssue(repo[0], repo[1], ghNumber)
+	if errors.Is(err, github.ErrIssueNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("getting GitHub issue #%d: %w", ghNumber, err)
+	}
+
+	return ghIssue.GetID() != ghID, nil
+}
+
+// configuredRepo returns the configured repository with the full name, e.g.
+// `owner/repo`, compared ignoring case like GitHub does. The boolean is false
+// if no such repository is configured.
+func configuredRepo(cfg *config.Config, name string) ([2]string, bool) {
+	for _, repo := range cfg.GetRepos() {
+		if strings.EqualFold(repo[0]+"/"+repo[1], name) {
+			return repo, true
+		}
+	}
+
+	return [2]string{}, false
+}
+
+// pruneIssue labels the Jira issue with `prune-label` and performs the
+// `prune-transition` on it, if they are set.
+func pruneIssue(cfg *config.Config, jIssue *gojira.Issue, jClient jira.Client) error {
+	if label := cfg.GetPruneLabel(); label != "" && !hasLabel(jIssue, label) {
+		issue := &gojira.Issue{
+			Key: jIssue.Key,
+			ID:  jIssue.ID,
+			Fields: &gojira.IssueFields{
+				Labels: append(append([]string{}, jIssue.Fields.Labels...), label),
+			},
+		}
+
+		if _, err := jClient.UpdateIssue(issue); err != nil {
+			return fmt.Errorf("labeling Jira issue %s: %w", jIssue.Key, err)
+		}
+	}
+
+	name := cfg.GetPruneTransition()
+	if name == "" {
+		return nil
+	}
+
+	transitions, err := jClient.GetTransitions(jIssue.Key)
+	if err != nil {
+		return fmt.Errorf("getting transitions of Jira issue %s: %w", jIssue.Key, err)
+	}
+
+	transition, ok := findTransition(transitions, name)
+	if !ok {
+		return errTransitionUnavailable(jIssue, name, transitions)
+	}
+
+	if err := jClient.DoTransition(jIssue, transition.ID); err != nil {
+		return fmt.Errorf("transitioning Jira issue %s with %q: %w", jIssue.Key, transition.Name, err)
+	}
+
+	return nil
+}
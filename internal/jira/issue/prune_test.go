@@ -0,0 +1,159 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	gogh "github.com/google/go-github/v56/github"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+func TestPrune(t *testing.T) {
+	notFound := func(owner, repo string, number int) (*gogh.Issue, error) {
+		return nil, fmt.Errorf("retrieving GitHub issue #%d: %w", number, github.ErrIssueNotFound)
+	}
+
+	tests := []struct {
+		name          string
+		repository    string
+		unrecorded    bool
+		getRepository func(owner, repo string) (*gogh.Repository, error)
+		getIssue      func(owner, repo string, number int) (*gogh.Issue, error)
+		pruned        bool
+		wantErr       bool
+	}{
+		{
+			name:     "deleted",
+			getIssue: notFound,
+			pruned:   true,
+		},
+		{
+			name:       "repository recorded with another case",
+			repository: "Test-Owner/Test-Repo",
+			getIssue:   notFound,
+			pruned:     true,
+		},
+		{
+			name: "repository inaccessible",
+			getRepository: func(owner, repo string) (*gogh.Repository, error) {
+				return nil, fmt.Errorf("retrieving GitHub repository %s/%s: 404 Not Found", owner, repo) //nolint:goerr113
+			},
+			getIssue: notFound,
+			wantErr:  true,
+		},
+		{
+			name:       "repository not configured",
+			repository: "test-owner/old-repo",
+			getIssue:   notFound,
+		},
+		{
+			name:       "synchronized before the repository was recorded",
+			unrecorded: true,
+			getIssue:   notFound,
+		},
+		{
+			name: "number reused by another issue",
+			getIssue: func(owner, repo string, number int) (*gogh.Issue, error) {
+				return &gogh.Issue{ID: gogh.Int64(2002), Number: gogh.Int(number)}, nil
+			},
+			pruned: true,
+		},
+		{
+			name: "still exists",
+			getIssue: func(owner, repo string, number int) (*gogh.Issue, error) {
+				return &gogh.Issue{ID: gogh.Int64(1001), Number: gogh.Int(number)}, nil
+			},
+		},
+		{
+			name: "lookup failed",
+			getIssue: func(owner, repo string, number int) (*gogh.Issue, error) {
+				return nil, fmt.Errorf("retrieving GitHub issue #%d: rate limited", number) //nolint:goerr113
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+				options.ConfigKeyConfirm:         true,
+				options.ConfigKeyPruneTransition: "Done",
+				options.ConfigKeyPruneLabel:      "gh-deleted",
+			})
+
+			jIssue := newJiraIssue(cfg, "TEST-1", 1001)
+			jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubNumber), float64(1))
+			if !tc.unrecorded {
+				repository := tc.repository
+				if repository == "" {
+					repository = "test-owner/test-repo"
+				}
+				jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubRepository), repository)
+			}
+
+			var labeled *gojira.Issue
+			var transitioned string
+			jClient := &jira.JiraClientMock{
+				ListLinkedIssuesFn: func() ([]gojira.Issue, error) {
+					return []gojira.Issue{jIssue}, nil
+				},
+				UpdateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+					labeled = issue
+					return issue, nil
+				},
+				GetTransitionsFn: func(issueKey string) ([]gojira.Transition, error) {
+					return []gojira.Transition{{ID: "11", Name: "Start Progress"}, {ID: "31", Name: "Done"}}, nil
+				},
+				DoTransitionFn: func(issue *gojira.Issue, transitionID string) error {
+					transitioned = transitionID
+					return nil
+				},
+			}
+			ghClient := &github.GitHubClientMock{GetRepositoryFn: tc.getRepository, GetIssueFn: tc.getIssue}
+
+			pruned, err := Prune(context.Background(), cfg, ghClient, jClient)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("Expected Prune() to return an error: %v; got %v", tc.wantErr, err)
+			}
+
+			if !tc.pruned {
+				if pruned != 0 || labeled != nil || transitioned != "" {
+					t.Fatalf("Expected Jira issue not to be pruned; got label %v and transition %q", labeled, transitioned)
+				}
+				return
+			}
+
+			if pruned != 1 {
+				t.Fatalf("Expected 1 pruned issue; got %d", pruned)
+			}
+			if labeled == nil || !hasLabel(labeled, "gh-deleted") {
+				t.Fatalf("Expected pruned issue to be labeled gh-deleted; got %v", labeled)
+			}
+			if transitioned != "31" {
+				t.Fatalf("Expected pruned issue to be transitioned to Done; got transition %q", transitioned)
+			}
+		})
+	}
+}
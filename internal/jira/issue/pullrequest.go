@@ -0,0 +1,77 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"fmt"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+// closingPullRequestLink returns the remote link from a Jira issue to the
+// pull request which closed its GitHub issue. The global ID of the link is
+// the URL of the pull request, so that Jira updates the link on later
+// synchronizations instead of adding it again.
+func closingPullRequestLink(pr *gogh.PullRequest) *gojira.RemoteLink {
+	return &gojira.RemoteLink{
+		GlobalID:     pr.GetHTMLURL(),
+		Relationship: "closed by",
+		Object: &gojira.RemoteLinkObject{
+			URL:   pr.GetHTMLURL(),
+			Title: fmt.Sprintf("Pull request #%d: %s", pr.GetNumber(), pr.GetTitle()),
+		},
+	}
+}
+
+// linkClosingPullRequest adds a remote link to the pull request which closed
+// a GitHub issue to its Jira issue, if the issue was closed by a pull
+// request.
+func linkClosingPullRequest(
+	cfg *config.Config,
+	ghIssue *gogh.Issue,
+	jIssue *gojira.Issue,
+	ghClient github.Client,
+	jClient jira.Client,
+) error {
+	if ghIssue.GetState() != "closed" {
+		return nil
+	}
+
+	owner, repo := cfg.GetRepo()
+	pr, err := ghClient.GetClosingPullRequest(owner, repo, ghIssue.GetNumber())
+	if err != nil {
+		return fmt.Errorf("getting closing pull request of GitHub issue #%d: %w", ghIssue.GetNumber(), err)
+	}
+	if pr == nil {
+		log.Debugf("GitHub issue #%d was not closed by a pull request", ghIssue.GetNumber())
+		return nil
+	}
+
+	if err := jClient.AddRemoteLink(jIssue, closingPullRequestLink(pr)); err != nil {
+		return fmt.Errorf("linking Jira issue %s to pull request #%d: %w", jIssue.Key, pr.GetNumber(), err)
+	}
+
+	log.Debugf("Linked Jira issue %s to pull request #%d", jIssue.Key, pr.GetNumber())
+
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"testing"
+
+	gogh "github.com/google/go-github/v56/github"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+func TestLinkClosingPullRequest(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), nil)
+
+	pr := &gogh.PullRequest{
+		Number:  gogh.Int(5),
+		Title:   gogh.String("Fix the bug"),
+		HTMLURL: gogh.String("https://github.com/test-owner/test-repo/pull/5"),
+	}
+
+	tests := []struct {
+		name  string
+		issue *gogh.Issue
+		pr    *gogh.PullRequest
+		links int
+	}{
+		{
+			name:  "closed by a pull request",
+			issue: &gogh.Issue{ID: gogh.Int64(1001), Number: gogh.Int(1), State: gogh.String("closed")},
+			pr:    pr,
+			links: 1,
+		},
+		{
+			name:  "closed without a pull request",
+			issue: &gogh.Issue{ID: gogh.Int64(1001), Number: gogh.Int(1), State: gogh.String("closed")},
+		},
+		{
+			name:  "open issue",
+			issue: &gogh.Issue{ID: gogh.Int64(1001), Number: gogh.Int(1), State: gogh.String("open")},
+			pr:    pr,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ghClient := &github.GitHubClientMock{
+				GetClosingPullRequestFn: func(owner, repo string, number int) (*gogh.PullRequest, error) {
+					if number != tc.issue.GetNumber() {
+						t.Fatalf("Expected lookup of #%d; Got #%d", tc.issue.GetNumber(), number)
+					}
+					return tc.pr, nil
+				},
+			}
+
+			var links []*gojira.RemoteLink
+			jClient := &jira.JiraClientMock{
+				AddRemoteLinkFn: func(issue *gojira.Issue, link *gojira.RemoteLink) error {
+					if issue.Key != "TEST-1" {
+						t.Fatalf("Expected the link to be added to TEST-1; Got %s", issue.Key)
+					}
+					links = append(links, link)
+					return nil
+				},
+			}
+
+			jIssue := newJiraIssue(cfg, "TEST-1", tc.issue.GetID())
+			if err := linkClosingPullRequest(cfg, tc.issue, &jIssue, ghClient, jClient); err != nil {
+				t.Fatalf("linkClosingPullRequest() returned error: %v", err)
+			}
+
+			if len(links) != tc.links {
+				t.Fatalf("Expected %d remote links; Got %d", tc.links, len(links))
+			}
+			if tc.links == 0 {
+				return
+			}
+
+			link := links[0]
+			if link.GlobalID != pr.GetHTMLURL() ||
+				link.Object.URL != pr.GetHTMLURL() ||
+				link.Object.Title != "Pull request #5: Fix the bug" {
+				t.Fatalf("Expected a link to pull request #5; Got %+v", link.Object)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"fmt"
+	"strings"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	"github.com/trivago/tgo/tcontainer"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+// summaryRelinker matches GitHub issues without a Jira issue to the Jira
+// issues which were never linked to a GitHub issue, by their summary.
+type summaryRelinker struct {
+	unlinked []gojira.Issue
+}
+
+// newSummaryRelinker returns a summaryRelinker over the unlinked Jira issues.
+func newSummaryRelinker(unlinked []gojira.Issue) *summaryRelinker {
+	return &summaryRelinker{unlinked: unlinked}
+}
+
+// normalizeSummary returns the summary with its case and whitespace
+// normalized, so that e.g. "Fix  the Login page " matches "fix the login page".
+func normalizeSummary(summary string) string {
+	return strings.ToLower(strings.Join(strings.Fields(summary), " "))
+}
+
+// match returns the index of the single unlinked Jira issue whose summary
+// matches the title of the GitHub issue. The boolean is false if there is no
+// such issue, or if the match is ambiguous.
+func (r *summaryRelinker) match(ghIssue *gogh.Issue) (int, bool) {
+	title := normalizeSummary(ghIssue.GetTitle())
+	if title == "" {
+		return 0, false
+	}
+
+	found := -1
+	for i := range r.unlinked {
+		if normalizeSummary(r.unlinked[i].Fields.Summary) != title {
+			continue
+		}
+		if found >= 0 {
+			log.Warnf(
+				"GitHub issue #%d matches several unlinked Jira issues by summary (%s, %s); not relinking",
+				ghIssue.GetNumber(),
+				r.unlinked[found].Key,
+				r.unlinked[i].Key,
+			)
+			return 0, false
+		}
+		found = i
+	}
+
+	return found, found >= 0
+}
+
+// relink returns the unlinked Jira issue matching the GitHub issue by its
+// summary, after backfilling its GitHub ID and number, or nil if there is
+// none. A relinked issue is not matched again.
+func (r *summaryRelinker) relink(cfg *config.Config, ghIssue *gogh.Issue, jClient jira.Client) (*gojira.Issue, error) {
+	i, ok := r.match(ghIssue)
+	if !ok {
+		return nil, nil
+	}
+
+	jIssue := r.unlinked[i]
+	r.unlinked = append(r.unlinked[:i], r.unlinked[i+1:]...)
+
+	log.Infof(
+		"Relinking Jira issue %s to GitHub issue #%d, as their summaries match: %q",
+		jIssue.Key,
+		ghIssue.GetNumber(),
+		jIssue.Fields.Summary,
+	)
+
+	fields := &gojira.IssueFields{
+		Type:     jIssue.Fields.Type,
+		Summary:  jIssue.Fields.Summary,
+		Unknowns: tcontainer.NewMarshalMap(),
+	}
+	fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubID), ghIssue.GetID())
+	if cfg.HasField(config.GitHubNumber) {
+		fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubNumber), ghIssue.GetNumber())
+	}
+
+	if _, err := jClient.UpdateIssue(&gojira.Issue{Key: jIssue.Key, ID: jIssue.ID, Fields: fields}); err != nil {
+		return nil, fmt.Errorf("backfilling GitHub fields of Jira issue %s: %w", jIssue.Key, err)
+	}
+
+	if jIssue.Fields.Unknowns == nil {
+		jIssue.Fields.Unknowns = tcontainer.NewMarshalMap()
+	}
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubID), float64(ghIssue.GetID()))
+	if cfg.HasField(config.GitHubNumber) {
+		jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubNumber), float64(ghIssue.GetNumber()))
+	}
+
+	return &jIssue, nil
+}
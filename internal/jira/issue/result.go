@@ -0,0 +1,64 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+// Result summarizes the GitHub issues processed by a synchronization.
+type Result struct {
+	// Created is the number of Jira issues created.
+	Created int `json:"created"`
+	// Updated is the number of existing Jira issues compared, and updated
+	// if they differed from their GitHub issue.
+	Updated int `json:"updated"`
+	// Skipped is the number of GitHub issues not synchronized because of
+	// the configured sync mode.
+	Skipped int `json:"skipped"`
+	// Failed is the number of GitHub issues which failed to synchronize.
+	Failed int `json:"failed"`
+}
+
+// Add adds the counts of other to the result, e.g. to sum up the results of
+// several repositories.
+func (r *Result) Add(other Result) {
+	r.Created += other.Created
+	r.Updated += other.Updated
+	r.Skipped += other.Skipped
+	r.Failed += other.Failed
+}
+
+// outcome is the outcome of synchronizing a single GitHub issue.
+type outcome int
+
+const (
+	outcomeCreated outcome = iota
+	outcomeUpdated
+	outcomeSkipped
+	outcomeFailed
+)
+
+// record counts the outcome of synchronizing a GitHub issue.
+func (r *Result) record(o outcome) {
+	switch o {
+	case outcomeCreated:
+		r.Created++
+	case outcomeUpdated:
+		r.Updated++
+	case outcomeSkipped:
+		r.Skipped++
+	case outcomeFailed:
+		r.Failed++
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+// sprintNameRegex matches the name of a sprint in the string representation
+// of sprints returned by Jira Server, e.g.
+// "com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=1,state=ACTIVE,name=Sprint 1,...]".
+var sprintNameRegex = regexp.MustCompile(`[\[,]name=([^,\]]*)`)
+
+// setSprint sets the Sprint field of the Jira issue fields to the sprint
+// named after the milestone of the GitHub issue, if there is one. Issues
+// without a milestone, or whose milestone has no sprint, are left in their
+// sprint.
+func setSprint(cfg *config.Config, ghIssue *gogh.Issue, fields *gojira.IssueFields, jClient jira.Client) error {
+	if !cfg.ShouldSyncMilestonesAsSprints() || ghIssue.Milestone == nil {
+		return nil
+	}
+
+	title := ghIssue.Milestone.GetTitle()
+	sprint, err := jClient.FindSprint(title)
+	if err != nil {
+		return fmt.Errorf("getting Jira sprint for milestone %q: %w", title, err)
+	}
+	if sprint == nil {
+		log.Debugf("Jira board %d has no open sprint named after milestone %q", cfg.GetJiraBoardID(), title)
+		return nil
+	}
+
+	// The Sprint field is written as the ID of the sprint, although it is
+	// read as a list of sprints.
+	fields.Unknowns.Set(cfg.GetFieldKey(config.Sprint), sprint.ID)
+
+	return nil
+}
+
+// sprintChanged returns whether the Jira issue is not in the sprint named
+// after the milestone of the GitHub issue.
+func sprintChanged(cfg *config.Config, ghIssue *gogh.Issue, jIssue *gojira.Issue) bool {
+	if ghIssue.Milestone == nil {
+		return false
+	}
+
+	value, _ := jira.FieldValue(jIssue, cfg.GetFieldKey(config.Sprint))
+	return !slices.Contains(sprintNames(value), ghIssue.Milestone.GetTitle())
+}
+
+// sprintNames returns the names of the sprints of a Jira issue, from the
+// value of its Sprint field: a list of sprint objects on Jira Cloud, and a
+// list of their string representations on Jira Server.
+func sprintNames(value interface{}) []string {
+	sprints, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(sprints))
+	for _, sprint := range sprints {
+		switch s := sprint.(type) {
+		case map[string]interface{}:
+			if name, ok := s["name"].(string); ok {
+				names = append(names, name)
+			}
+		case string:
+			if matches := sprintNameRegex.FindStringSubmatch(s); matches != nil {
+				names = append(names, matches[1])
+			}
+		}
+	}
+
+	return names
+}
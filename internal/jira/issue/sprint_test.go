@@ -0,0 +1,134 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	gogh "github.com/google/go-github/v56/github"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+func TestMilestoneAsSprint(t *testing.T) {
+	cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+		options.ConfigKeyConfirm:           true,
+		options.ConfigKeyMilestoneAsSprint: true,
+		options.ConfigKeyJiraBoardID:       42,
+	})
+
+	ghIssue := &gogh.Issue{
+		ID:        gogh.Int64(1001),
+		Number:    gogh.Int(1),
+		Title:     gogh.String("Login page is broken"),
+		State:     gogh.String("open"),
+		User:      &gogh.User{Login: gogh.String("octocat")},
+		Milestone: &gogh.Milestone{Title: gogh.String("Sprint 7")},
+	}
+
+	var created *gojira.Issue
+	var searched []string
+	jClient := &jira.JiraClientMock{
+		FindSprintFn: func(name string) (*gojira.Sprint, error) {
+			searched = append(searched, name)
+			return &gojira.Sprint{ID: 7, Name: name, State: "active"}, nil
+		},
+		CreateIssueFn: func(issue *gojira.Issue) (*gojira.Issue, error) {
+			created = issue
+			issue.Key = "TEST-1"
+			return issue, nil
+		},
+	}
+
+	if err := CreateIssue(cfg, ghIssue, &github.GitHubClientMock{}, jClient); err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(searched, []string{"Sprint 7"}) {
+		t.Fatalf("Expected the sprint named after the milestone to be searched; got %v", searched)
+	}
+	// The Sprint field is written as the bare ID of the sprint.
+	key := cfg.GetFieldKey(config.Sprint)
+	if value := created.Fields.Unknowns[key]; value != 7 {
+		t.Fatalf("Expected created issue to have sprint ID 7; got %#v", value)
+	}
+
+	jIssue := newJiraIssue(cfg, "TEST-1", ghIssue.GetID())
+	jIssue.Fields.Summary = ghIssue.GetTitle()
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubStatus), ghIssue.GetState())
+	jIssue.Fields.Unknowns.Set(cfg.GetFieldKey(config.GitHubReporter), ghIssue.User.GetLogin())
+	jIssue.Fields.Unknowns.Set(key, []interface{}{
+		map[string]interface{}{"id": float64(6), "name": "Sprint 6", "state": "closed"},
+		map[string]interface{}{"id": float64(7), "name": "Sprint 7", "state": "active"},
+	})
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+
+	ghIssue.Milestone = &gogh.Milestone{Title: gogh.String("Sprint 8")}
+	changed := ChangedFields(cfg, ghIssue, &jIssue)
+	if !reflect.DeepEqual(changed, []string{"sprint"}) {
+		t.Fatalf("Expected only the sprint to have changed; got %v", changed)
+	}
+
+	// Removing the milestone leaves the issue in its sprint.
+	ghIssue.Milestone = nil
+	if changed := ChangedFields(cfg, ghIssue, &jIssue); len(changed) != 0 {
+		t.Fatalf("Expected no changed fields; got %v", changed)
+	}
+}
+
+func TestSprintNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected []string
+	}{
+		{name: "none", value: nil, expected: nil},
+		{
+			name: "cloud",
+			value: []interface{}{
+				map[string]interface{}{"id": float64(6), "name": "Sprint 6", "state": "closed"},
+				map[string]interface{}{"id": float64(7), "name": "Sprint 7", "state": "active"},
+			},
+			expected: []string{"Sprint 6", "Sprint 7"},
+		},
+		{
+			name: "server",
+			value: []interface{}{
+				"com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=7,rapidViewId=42,state=ACTIVE," +
+					"name=Sprint 7,startDate=2023-07-10T09:00:00.000Z,endDate=<null>,sequence=7]",
+			},
+			expected: []string{"Sprint 7"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if names := sprintNames(tc.value); len(names) != len(tc.expected) ||
+				(len(names) > 0 && !reflect.DeepEqual(names, tc.expected)) {
+				t.Fatalf("Expected sprint names %v; got %v", tc.expected, names)
+			}
+		})
+	}
+}
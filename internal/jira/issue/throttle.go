@@ -0,0 +1,72 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/clock"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+)
+
+// createThrottle spaces the creation of Jira issues according to the
+// configured `create-rate`.
+type createThrottle struct {
+	ctx      context.Context
+	clock    clock.Clock
+	interval time.Duration
+
+	// next is the earliest time the next Jira issue may be created.
+	next time.Time
+}
+
+// newCreateThrottle returns a createThrottle for the configured rate. Waiting
+// stops early with an error if ctx is done.
+func newCreateThrottle(ctx context.Context, cfg *config.Config) *createThrottle {
+	t := &createThrottle{
+		ctx:   ctx,
+		clock: cfg.Clock(),
+	}
+	if rate := cfg.GetCreateRate(); rate > 0 {
+		t.interval = time.Duration(float64(time.Second) / rate)
+	}
+	return t
+}
+
+// wait blocks until the next Jira issue may be created.
+func (t *createThrottle) wait() error {
+	if t.interval == 0 {
+		return nil
+	}
+
+	now := t.clock.Now()
+	if d := t.next.Sub(now); d > 0 {
+		log.Debugf("Waiting %v before creating the next Jira issue", d)
+		select {
+		case now = <-t.clock.After(d):
+		case <-t.ctx.Done():
+			return fmt.Errorf("waiting to create a Jira issue: %w", t.ctx.Err())
+		}
+	}
+
+	t.next = now.Add(t.interval)
+	return nil
+}
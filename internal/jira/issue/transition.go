@@ -0,0 +1,106 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"fmt"
+	"strings"
+
+	gogh "github.com/google/go-github/v56/github"
+	log "github.com/sirupsen/logrus"
+	gojira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+// transitionIssue performs the Jira workflow transition configured in
+// `status-transition-map` for the state of the GitHub issue, if that state
+// differs from previousState, the GitHub state recorded on the Jira issue
+// before it was updated.
+func transitionIssue(
+	cfg *config.Config,
+	ghIssue *gogh.Issue,
+	previousState string,
+	jIssue *gojira.Issue,
+	jClient jira.Client,
+) error {
+	state := ghIssue.GetState()
+	if state == previousState {
+		return nil
+	}
+
+	name, ok := cfg.GetStatusTransitionMap()[state]
+	if !ok || name == "" {
+		return nil
+	}
+
+	log.Debugf(
+		"GitHub issue #%d is now %s; transitioning Jira issue %s with %q",
+		ghIssue.GetNumber(),
+		state,
+		jIssue.Key,
+		name,
+	)
+
+	transitions, err := jClient.GetTransitions(jIssue.Key)
+	if err != nil {
+		return fmt.Errorf("getting transitions of Jira issue %s: %w", jIssue.Key, err)
+	}
+
+	transition, ok := findTransition(transitions, name)
+	if !ok {
+		return errTransitionUnavailable(jIssue, name, transitions)
+	}
+
+	if err := jClient.DoTransition(jIssue, transition.ID); err != nil {
+		return fmt.Errorf("transitioning Jira issue %s with %q: %w", jIssue.Key, transition.Name, err)
+	}
+
+	return nil
+}
+
+// findTransition returns the transition whose name matches name, ignoring
+// case.
+func findTransition(transitions []gojira.Transition, name string) (gojira.Transition, bool) {
+	for _, t := range transitions {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return gojira.Transition{}, false
+}
+
+func errTransitionUnavailable(jIssue *gojira.Issue, name string, transitions []gojira.Transition) error {
+	status := "unknown"
+	if jIssue.Fields != nil && jIssue.Fields.Status != nil {
+		status = jIssue.Fields.Status.Name
+	}
+
+	names := make([]string, len(transitions))
+	for i := range transitions {
+		names[i] = transitions[i].Name
+	}
+
+	return fmt.Errorf( //nolint:goerr113
+		"transition %q is not available from status %q of Jira issue %s; available transitions: %s",
+		name,
+		status,
+		jIssue.Key,
+		strings.Join(names, ", "),
+	)
+}
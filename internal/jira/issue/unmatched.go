@@ -0,0 +1,68 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package issue
+
+import (
+	"fmt"
+
+	gogh "github.com/google/go-github/v56/github"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira"
+)
+
+// Unmatched returns the GitHub issues of the configured repository which
+// Compare would create a Jira issue for: those of the synchronized state
+// and labels, which match no Jira issue with the configured match strategy.
+// Unlike Compare, it does not filter the issues by the `since` date, and does
+// not look for Jira issues to relink by summary.
+func Unmatched(cfg *config.Config, ghClient github.Client, jiraClient jira.Client) ([]*gogh.Issue, error) {
+	owner, repo := cfg.GetRepo()
+	ghIssues, err := ghClient.ListIssues(owner, repo, github.ListIssuesOptions{State: cfg.GetSyncIssueState()})
+	if err != nil {
+		return nil, fmt.Errorf("listing GitHub issues: %w", err)
+	}
+	if len(ghIssues) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int, len(ghIssues))
+	for i, v := range ghIssues {
+		ids[i] = int(v.GetID())
+	}
+
+	jiraIssues, err := jiraClient.ListIssues(ids)
+	if err != nil {
+		return nil, fmt.Errorf("listing Jira issues: %w", err)
+	}
+
+	index := indexJiraIssuesByGitHubID(cfg, jiraIssues)
+
+	var unmatched []*gogh.Issue
+	for _, ghIssue := range filterByLabels(cfg, ghIssues, index) {
+		jIssue, err := findJiraIssueByStrategy(cfg, ghIssue, index, jiraClient)
+		if err != nil {
+			return nil, fmt.Errorf("matching issue #%d: %w", ghIssue.GetNumber(), err)
+		}
+		if jIssue == nil {
+			unmatched = append(unmatched, ghIssue)
+		}
+	}
+
+	return unmatched, nil
+}
@@ -17,20 +17,27 @@
 package jira
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	gogh "github.com/google/go-github/v56/github"
 	log "github.com/sirupsen/logrus"
+	"github.com/trivago/tgo/tcontainer"
 	jira "github.com/uwu-tools/go-jira/v2/cloud"
 
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/config"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
 	synchttp "github.com/uwu-tools/gh-jira-issue-sync/internal/http"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/jira/auth"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/markup"
 	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
 )
 
@@ -38,8 +45,9 @@ const (
 	// commentDateFormat is the format used in the headers of Jira comments.
 	commentDateFormat = "15:04 PM, January 2 2006"
 
-	// maxJQLIssueLength is the maximum number of GitHub issues we can
-	// use before we need to stop using JQL and filter issues ourself.
+	// maxJQLIssueLength is the maximum number of GitHub IDs listed in a
+	// single JQL query; longer lists are split across several queries, as
+	// they would get a 414 Request-URI Too Large.
 	maxJQLIssueLength = 100
 
 	// maxIssueSearchResults is the maximum number of items that a page can
@@ -51,6 +59,10 @@ const (
 	//
 	// ref: https://developer.atlassian.com/cloud/jira/platform/rest/v2/intro/#pagination
 	maxIssueSearchResults = 1000
+
+	// lastSyncSearchResults is the number of most recently synchronized
+	// issues inspected to find the latest `github-last-sync` value.
+	lastSyncSearchResults = 10
 )
 
 // Client is a wrapper around the Jira API clients library we
@@ -59,6 +71,10 @@ const (
 // or test mocking.
 type Client interface {
 	ListIssues(ids []int) ([]jira.Issue, error)
+	// ListUnlinkedIssues returns the issues of the configured project which
+	// have neither a GitHub ID nor a GitHub number.
+	ListUnlinkedIssues() ([]jira.Issue, error)
+	ListLinkedIssues() ([]jira.Issue, error)
 	GetIssue(key string) (*jira.Issue, error)
 	// TODO: Remove unnecessary return values; consider only returning error
 	CreateIssue(issue *jira.Issue) (*jira.Issue, error)
@@ -73,6 +89,33 @@ type Client interface {
 	UpdateComment(
 		issue *jira.Issue, id string, comment *gogh.IssueComment, githubClient github.Client,
 	) (*jira.Comment, error)
+	// AddComment adds a comment with the provided body to the Jira issue.
+	AddComment(issue *jira.Issue, body string) (*jira.Comment, error)
+	CreateIssueLink(link *jira.IssueLink) error
+	// AddRemoteLink adds a remote link to the Jira issue. Jira updates the
+	// existing link of the issue with the same global ID, if any, instead of
+	// adding another one.
+	AddRemoteLink(issue *jira.Issue, link *jira.RemoteLink) error
+	// GetLastSyncTime returns the latest `github-last-sync` value across the
+	// issues of the configured project, or the zero time if no issue has
+	// been synchronized yet.
+	GetLastSyncTime() (time.Time, error)
+	// TODO: Remove unnecessary return values; consider only returning error
+	UpdateVersion(version *jira.Version) (*jira.Version, error)
+	EnsureVersion(name string) (*jira.Version, error)
+	ClearFixVersions(issue *jira.Issue) error
+	// FindSprint returns the active or future sprint of the `jira-board-id`
+	// board with the given name, or nil if there is none.
+	FindSprint(name string) (*jira.Sprint, error)
+	// GetTransitions returns the workflow transitions available from the
+	// current status of the Jira issue.
+	GetTransitions(issueKey string) ([]jira.Transition, error)
+	// DoTransition moves the Jira issue through the workflow transition with
+	// the given ID, which must be available from its current status.
+	DoTransition(issue *jira.Issue, transitionID string) error
+	// RefreshFieldIDs pulls the IDs of the custom fields from Jira again if
+	// the `field-refresh-interval` elapsed, and returns whether it did.
+	RefreshFieldIDs() (bool, error)
 }
 
 // jiraClient is a standard Jira clients, which actually makes
@@ -90,10 +133,74 @@ type jiraClient struct {
 // on the configuration; currently, it creates either a standard
 // clients, or a dry-run clients.
 func New(cfg *config.Config) (Client, error) {
+	client, err := newAPIClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.LoadJiraConfig(client)
+	if err != nil {
+		return nil, fmt.Errorf("loading Jira configuration: %w", err)
+	}
+
+	j := &jiraClient{
+		cfg:    cfg,
+		client: client,
+
+		// TODO(dry-run): Check logic here
+		dryRun: cfg.IsDryRun(),
+	}
+
+	return j, nil
+}
+
+// ErrAuthenticationFailed is returned by CheckAuth if Jira rejects the
+// configured credentials.
+var ErrAuthenticationFailed = errors.New("jira rejected the configured credentials")
+
+// CheckAuth verifies that the configured Jira credentials authenticate by
+// requesting the current user. It needs neither a project nor any of the
+// custom fields, so it can be used to check credentials on their own, e.g.
+// after rotating a token.
+func CheckAuth(cfg *config.Config) (*jira.User, error) {
+	client, err := newAPIClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(cfg.Context(), http.MethodGet, cfg.JiraAPIPath("myself"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating Jira request: %w", err)
+	}
+
+	// The request is not retried: an authentication failure will not go
+	// away by itself.
+	user := &jira.User{}
+	res, err := client.Do(req, user)
+	if err != nil {
+		if res == nil {
+			return nil, fmt.Errorf("getting current Jira user: %w", err)
+		}
+		if res.StatusCode == http.StatusUnauthorized {
+			res.Body.Close()
+			return nil, fmt.Errorf("%w: %s", ErrAuthenticationFailed, res.Status)
+		}
+		return nil, fmt.Errorf("getting current Jira user: %w", getErrorBody(res, err))
+	}
+
+	return user, nil
+}
+
+// newAPIClient returns a go-jira client for the configured Jira URI, which
+// authenticates with the configured credentials.
+func newAPIClient(cfg *config.Config) (*jira.Client, error) {
 	var tp http.Client
-	var err error
 
-	if !cfg.IsBasicAuth() {
+	if cfg.IsBearerAuth() {
+		tp.Transport = &auth.BearerTokenTransport{
+			Token: strings.TrimSpace(cfg.GetConfigString(options.ConfigKeyJiraBearerToken)),
+		}
+	} else if !cfg.IsBasicAuth() {
 		oauth, err := auth.NewJiraHTTPClient(cfg)
 		if err != nil {
 			log.Errorf("Error getting OAuth config: %+v", err)
@@ -118,77 +225,127 @@ func New(cfg *config.Config) (Client, error) {
 
 	log.Debug("Jira clients initialized")
 
-	err = cfg.LoadJiraConfig(client)
-	if err != nil {
-		return nil, fmt.Errorf("loading Jira configuration: %w", err)
-	}
-
-	j := &jiraClient{
-		cfg:    cfg,
-		client: client,
-
-		// TODO(dry-run): Check logic here
-		dryRun: cfg.IsDryRun(),
-	}
-
-	return j, nil
+	return client, nil
 }
 
 // ListIssues returns a list of Jira issues on the configured project which
 // have GitHub IDs in the provided list. `ids` should be a comma-separated
 // list of GitHub IDs.
-func (j *jiraClient) ListIssues(ids []int) ([]jira.Issue, error) { //nolint:gocognit // TODO(lint): gocognit
-	jql := getJQLQuery(
+func (j *jiraClient) ListIssues(ids []int) ([]jira.Issue, error) {
+	queries := getJQLQueries(
 		j.cfg.GetProjectKey(),
 		j.cfg.GetFieldID(config.GitHubID),
 		ids,
 	)
 
 	var issues []jira.Issue
-	// TODO(backoff): Consider restoring backoff logic here
 	// TODO(j-v2): Parameterize all query options
 	searchOpts := &jira.SearchOptions{
 		MaxResults: maxIssueSearchResults,
 	}
 
-	var jiraIssues []jira.Issue
-	err := j.client.Issue.SearchPages(j.cfg.Context(), jql, searchOpts, func(i jira.Issue) error {
-		jiraIssues = append(jiraIssues, i)
-		return nil
-	})
+	// Each Jira issue has a single GitHub ID, so it is matched by at most
+	// one of the queries.
+	for _, jql := range queries {
+		jiraIssues, err := j.searchPages(jql, searchOpts)
+		if err != nil {
+			log.Errorf("Error retrieving Jira issues: %+v", err)
+			return nil, fmt.Errorf("error retrieving Jira issues: %w", err)
+		}
+		issues = append(issues, jiraIssues...)
+	}
+
+	return issues, nil
+}
+
+// ListUnlinkedIssues returns the Jira issues on the configured project which
+// have neither a GitHub ID nor a GitHub number, e.g. because they were
+// created by hand before the project was synchronized.
+func (j *jiraClient) ListUnlinkedIssues() ([]jira.Issue, error) {
+	jql := fmt.Sprintf(
+		"project='%s' AND cf[%s] is EMPTY",
+		j.cfg.GetProjectKey(),
+		j.cfg.GetFieldID(config.GitHubID),
+	)
+	if j.cfg.HasField(config.GitHubNumber) {
+		jql += fmt.Sprintf(" AND cf[%s] is EMPTY", j.cfg.GetFieldID(config.GitHubNumber))
+	}
+	log.Debugf("JQL query used: %s", jql)
+
+	searchOpts := &jira.SearchOptions{
+		MaxResults: maxIssueSearchResults,
+	}
+
+	issues, err := j.searchPages(jql, searchOpts)
 	if err != nil {
-		log.Errorf("Error retrieving Jira issues: %+v", err)
-		return nil, fmt.Errorf("error retrieving Jira issues: %w", err)
+		log.Errorf("Error retrieving unlinked Jira issues: %+v", err)
+		return nil, fmt.Errorf("error retrieving unlinked Jira issues: %w", err)
 	}
-	if len(ids) < maxJQLIssueLength {
-		// The issues were already filtered by our JQL, so use as is
-		issues = jiraIssues
-	} else {
-		// Filter only issues which have a defined GitHub ID in the list of IDs
-		for _, v := range jiraIssues {
-			if id, err := v.Fields.Unknowns.Int(j.cfg.GetFieldKey(config.GitHubID)); err == nil {
-				for _, idOpt := range ids {
-					if id == int64(idOpt) {
-						issues = append(issues, v)
-						break
-					}
-				}
-			}
-		}
+
+	return issues, nil
+}
+
+// ListLinkedIssues returns the Jira issues on the configured project which
+// have a GitHub ID and are not done yet.
+func (j *jiraClient) ListLinkedIssues() ([]jira.Issue, error) {
+	jql := fmt.Sprintf(
+		"project='%s' AND cf[%s] is not EMPTY AND statusCategory != Done",
+		j.cfg.GetProjectKey(),
+		j.cfg.GetFieldID(config.GitHubID),
+	)
+	log.Debugf("JQL query used: %s", jql)
+
+	searchOpts := &jira.SearchOptions{
+		MaxResults: maxIssueSearchResults,
 	}
+
+	issues, err := j.searchPages(jql, searchOpts)
+	if err != nil {
+		log.Errorf("Error retrieving linked Jira issues: %+v", err)
+		return nil, fmt.Errorf("error retrieving linked Jira issues: %w", err)
+	}
+
 	return issues, nil
 }
 
+// searchPages returns every Jira issue matching the JQL query, requesting
+// them a page of searchOpts.MaxResults issues at a time. Each page is
+// requested with exponential backoff, so that a failed page is retried on
+// its own, keeping the pages already collected.
+func (j *jiraClient) searchPages(jql string, searchOpts *jira.SearchOptions) ([]jira.Issue, error) {
+	opts := *searchOpts
+
+	var issues []jira.Issue
+	for {
+		i, res, err := j.request(func() (interface{}, *jira.Response, error) {
+			return j.client.Issue.Search(j.cfg.RequestContext(), jql, &opts) //nolint:wrapcheck
+		})
+		if err != nil {
+			return nil, fmt.Errorf("searching Jira issues from %d: %w", opts.StartAt, err)
+		}
+		page, ok := i.([]jira.Issue)
+		if !ok {
+			return nil, fmt.Errorf("search Jira issues failed: expected []jira.Issue; got %T", i) //nolint:goerr113
+		}
+		issues = append(issues, page...)
+
+		if len(page) == 0 || res.StartAt+res.MaxResults >= res.Total {
+			return issues, nil
+		}
+		opts.StartAt = res.StartAt + res.MaxResults
+	}
+}
+
 // GetIssue returns a single Jira issue within the configured project
 // according to the issue key (e.g. "PROJ-13").
 func (j *jiraClient) GetIssue(key string) (*jira.Issue, error) {
 	i, res, err := j.request(func() (interface{}, *jira.Response, error) {
 		// TODO(j-v2): Add query options
-		return j.client.Issue.Get(j.cfg.Context(), key, nil) //nolint:wrapcheck
+		return j.client.Issue.Get(j.cfg.RequestContext(), key, nil) //nolint:wrapcheck
 	})
 	if err != nil {
 		log.Errorf("Error retrieving Jira issue: %+v", err)
-		return nil, getErrorBody(res)
+		return nil, getErrorBody(res, err)
 	}
 	issue, ok := i.(*jira.Issue)
 	if !ok {
@@ -196,9 +353,73 @@ func (j *jiraClient) GetIssue(key string) (*jira.Issue, error) {
 		return nil, fmt.Errorf("get Jira issue failed: expected *jira.Issue; got %T", i) //nolint:goerr113
 	}
 
+	// The client gets the comments with version 2, which renders the body
+	// of ADF comments to wiki markup, so they are listed again as ADF.
+	if j.usesADF() && issue.Fields != nil {
+		comments, err := j.listADFComments(key)
+		if err != nil {
+			return nil, err
+		}
+		issue.Fields.Comments = &jira.Comments{Comments: comments}
+	}
+
 	return issue, nil
 }
 
+// adfCommentPage is a page of the comments of a Jira issue, as listed by
+// version 3 of the Jira REST API.
+type adfCommentPage struct {
+	StartAt    int `json:"startAt"`
+	MaxResults int `json:"maxResults"`
+	Total      int `json:"total"`
+	Comments   []struct {
+		ID      string          `json:"id"`
+		Self    string          `json:"self"`
+		Author  *jira.User      `json:"author"`
+		Body    json.RawMessage `json:"body"`
+		Created string          `json:"created"`
+		Updated string          `json:"updated"`
+	} `json:"comments"`
+}
+
+// listADFComments returns all the comments of the Jira issue, in the order
+// they were created, with their ADF documents as their bodies, as JSON.
+func (j *jiraClient) listADFComments(key string) ([]*jira.Comment, error) {
+	var comments []*jira.Comment
+	for {
+		endpoint := fmt.Sprintf("issue/%s/comment?orderBy=created&startAt=%d", key, len(comments))
+		req, err := j.client.NewRequest(j.cfg.RequestContext(), http.MethodGet, j.cfg.JiraAPIPath(endpoint), nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating comment list request: %w", err)
+		}
+
+		var page adfCommentPage
+		_, res, err := j.request(func() (interface{}, *jira.Response, error) {
+			res, err := j.client.Do(req, &page)
+			return nil, res, err //nolint:wrapcheck
+		})
+		if err != nil {
+			log.Errorf("Error listing comments of Jira issue %s: %v", key, err)
+			return nil, getErrorBody(res, err)
+		}
+
+		for _, c := range page.Comments {
+			comments = append(comments, &jira.Comment{
+				ID:      c.ID,
+				Self:    c.Self,
+				Author:  c.Author,
+				Body:    string(c.Body),
+				Created: c.Created,
+				Updated: c.Updated,
+			})
+		}
+
+		if len(page.Comments) == 0 || len(comments) >= page.Total {
+			return comments, nil
+		}
+	}
+}
+
 // CreateIssue creates a new Jira issue according to the fields provided in
 // the provided issue object. It returns the created issue, with all the
 // fields provided (including e.g. ID and Key).
@@ -208,11 +429,30 @@ func (j *jiraClient) CreateIssue(issue *jira.Issue) (*jira.Issue, error) {
 	// TODO(dry-run): Simplify logic
 	if !j.dryRun {
 		i, res, err := j.request(func() (interface{}, *jira.Response, error) {
-			return j.client.Issue.Create(j.cfg.Context(), issue) //nolint:wrapcheck
+			i, res, err := j.client.Issue.Create(j.cfg.RequestContext(), issue)
+			if err != nil {
+				// Unlike updates, failed creations don't parse the error of
+				// the response, which tells whether the reporter was rejected.
+				return nil, res, jira.NewJiraError(res, err) //nolint:wrapcheck
+			}
+			return i, res, nil
 		})
 		if err != nil {
+			// The error was parsed from the body of the failed response,
+			// which is not read any further.
+			synchttp.CloseBody(res)
+
+			if reporterRejected(err) {
+				if retry, ok := withoutFields(issue, []string{"reporter"}); ok {
+					log.Warn(
+						"Jira rejected the reporter of the new issue; creating it with the acting user as the reporter",
+					)
+					return j.CreateIssue(retry)
+				}
+			}
+
 			log.Errorf("Error creating Jira issue: %+v", err)
-			return nil, getErrorBody(res)
+			return nil, fmt.Errorf("creating Jira issue: %w", err)
 		}
 		is, ok := i.(*jira.Issue)
 		if !ok {
@@ -250,11 +490,26 @@ func (j *jiraClient) UpdateIssue(issue *jira.Issue) (*jira.Issue, error) {
 	if !j.dryRun { //nolint:nestif // TODO(lint): complex nested blocks (nestif)
 		i, res, err := j.request(func() (interface{}, *jira.Response, error) {
 			// TODO(j-v2): Add query options
-			return j.client.Issue.Update(j.cfg.Context(), issue, nil) //nolint:wrapcheck
+			return j.client.Issue.Update(j.cfg.RequestContext(), issue, nil) //nolint:wrapcheck
 		})
 		if err != nil {
+			if fields := offScreenFields(err); len(fields) > 0 {
+				if retry, ok := withoutFields(issue, fields); ok {
+					// The error was parsed from the body of the failed
+					// response, which is not read any further.
+					synchttp.CloseBody(res)
+					log.Warnf(
+						"Fields %v of Jira issue %s can't be set, as they are not on its edit screen; "+
+							"updating the other fields",
+						fields,
+						issue.Key,
+					)
+					return j.UpdateIssue(retry)
+				}
+			}
+
 			log.Errorf("Error updating Jira issue %s: %v", issue.Key, err)
-			return nil, getErrorBody(res)
+			return nil, getErrorBody(res, err)
 		}
 		is, ok := i.(*jira.Issue)
 		if !ok {
@@ -272,11 +527,13 @@ func (j *jiraClient) UpdateIssue(issue *jira.Issue) (*jira.Issue, error) {
 		log.Infof("  Summary: %s", fields.Summary)
 		log.Infof("  Description: %s", truncate(fields.Description, 50))
 		key := j.cfg.GetFieldKey(config.GitHubLabels)
-		if labels, err := fields.Unknowns.String(key); err == nil {
-			log.Infof("  Labels: %s", labels)
+		if value, ok := FieldValue(newIssue, key); ok {
+			if labels, ok := value.([]string); ok {
+				log.Infof("  Labels: %s", labels)
+			}
 		}
 		key = j.cfg.GetFieldKey(config.GitHubStatus)
-		if state, err := fields.Unknowns.String(key); err == nil {
+		if state, err := FieldString(newIssue, key); err == nil {
 			log.Infof("  State: %s", state)
 		}
 		log.Info("")
@@ -285,10 +542,201 @@ func (j *jiraClient) UpdateIssue(issue *jira.Issue) (*jira.Issue, error) {
 	return newIssue, nil
 }
 
+// offScreenMessage is part of the error Jira returns for a field which can't
+// be set because it is not on the screen of the operation, e.g.
+// "Field 'customfield_10005' cannot be set. It is not on the appropriate
+// screen, or unknown."
+const offScreenMessage = "not on the appropriate screen"
+
+// offScreenFields returns the IDs of the fields which the error of a Jira
+// request reports as not being on the screen of the operation, sorted.
+func offScreenFields(err error) []string {
+	var jerr *jira.Error
+	if !errors.As(err, &jerr) {
+		return nil
+	}
+
+	var fields []string
+	for field, message := range jerr.Errors {
+		if strings.Contains(message, offScreenMessage) {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+// reporterRejected returns whether the error of a Jira request reports that
+// the reporter of the issue can't be set, e.g. because the acting user lacks
+// the "Modify Reporter" permission, or the reporter is not on the screen.
+func reporterRejected(err error) bool {
+	var jerr *jira.Error
+	if !errors.As(err, &jerr) {
+		return false
+	}
+
+	_, ok := jerr.Errors["reporter"]
+	return ok
+}
+
+// withoutFields returns a copy of the issue without the fields with the
+// given IDs. The boolean is false if the issue has none of these fields, so
+// that a request is not retried unchanged.
+func withoutFields(issue *jira.Issue, ids []string) (*jira.Issue, bool) {
+	fields := *issue.Fields
+	fields.Unknowns = tcontainer.NewMarshalMap()
+	for k, v := range issue.Fields.Unknowns {
+		fields.Unknowns[k] = v
+	}
+
+	removed := false
+	for _, id := range ids {
+		switch id {
+		case "summary":
+			removed = removed || fields.Summary != ""
+			fields.Summary = ""
+		case "description":
+			removed = removed || fields.Description != ""
+			fields.Description = ""
+		case "environment":
+			removed = removed || fields.Environment != ""
+			fields.Environment = ""
+		case "duedate":
+			removed = removed || !time.Time(fields.Duedate).IsZero()
+			fields.Duedate = jira.Date{}
+		case "reporter":
+			removed = removed || fields.Reporter != nil
+			fields.Reporter = nil
+		case "labels":
+			removed = removed || fields.Labels != nil
+			fields.Labels = nil
+		case "components":
+			removed = removed || fields.Components != nil
+			fields.Components = nil
+		case "fixVersions":
+			removed = removed || fields.FixVersions != nil
+			fields.FixVersions = nil
+		default:
+			if _, ok := fields.Unknowns[id]; ok {
+				removed = true
+				delete(fields.Unknowns, id)
+			}
+		}
+	}
+
+	copied := *issue
+	copied.Fields = &fields
+
+	return &copied, removed
+}
+
 // maxBodyLength is the maximum length of a Jira comment body, which is currently
 // 2^15-1.
 const maxBodyLength = 1 << 15
 
+// usesADF reports whether comment bodies are Atlassian Document Format (ADF)
+// documents, as version 3 of the Jira REST API expects them, rather than
+// wiki markup.
+func (j *jiraClient) usesADF() bool {
+	return j.cfg.GetJiraAPIVersion() == options.JiraAPIVersion3
+}
+
+// commentBody returns the body of a GitHub comment as it is posted to Jira,
+// converted to Jira wiki markup if `convert-markdown` is enabled, or with its
+// wiki markup characters escaped if `escape-markup` is. With ADF, the body is
+// kept as Markdown, which markup.ToADF converts.
+func (j *jiraClient) commentBody(comment *gogh.IssueComment) string {
+	if j.usesADF() {
+		return comment.GetBody()
+	}
+	if j.cfg.ShouldConvertMarkdown() {
+		return markup.ToJira(comment.GetBody())
+	}
+	if j.cfg.ShouldEscapeMarkup() {
+		return markup.Escape(comment.GetBody())
+	}
+	return comment.GetBody()
+}
+
+// commentMarkerFormat is the format of the hidden anchor starting the Jira
+// comments copied from GitHub. Its argument is the ID of the GitHub comment.
+const commentMarkerFormat = "{anchor:gh-comment:%d}"
+
+// CommentHeader holds the fields of a GitHub comment available to the
+// `comment-template`.
+type CommentHeader struct {
+	ID        int64
+	URL       string
+	Login     string
+	UserURL   string
+	Name      string
+	CreatedAt time.Time
+}
+
+// commentText returns the full text of the Jira comment of a GitHub comment:
+// a hidden anchor recording the ID of the GitHub comment, so that the
+// comment is matched even if its header is edited, a header identifying the
+// GitHub comment and its author, and the body.
+//
+// Without a `comment-template`, the header is the historical one. With a
+// template, the header is rendered from it on a single line.
+//
+// ADF has no hidden anchors, so with ADF the links are written in Markdown
+// for markup.ToADF, and a templated header is prefixed with the linked ID of
+// the GitHub comment instead.
+func (j *jiraClient) commentText(comment *gogh.IssueComment, user *gogh.User, commentBody string) (string, error) {
+	marker := fmt.Sprintf(commentMarkerFormat, comment.GetID())
+	link := func(text, url string) string {
+		return fmt.Sprintf("[%s|%s]", text, url)
+	}
+	if j.usesADF() {
+		marker = ""
+		link = func(text, url string) string {
+			return fmt.Sprintf("[%s](%s)", text, url)
+		}
+	}
+	id := fmt.Sprintf("(ID %d)", comment.GetID())
+
+	tmpl := j.cfg.GetCommentTemplate()
+	if tmpl == nil {
+		header := "Comment " + link(id, comment.GetHTMLURL())
+		header = fmt.Sprintf("%s from GitHub user %s", header, link(user.GetLogin(), user.GetHTMLURL()))
+		if user.GetName() != "" {
+			header = fmt.Sprintf("%s (%s)", header, user.GetName())
+		}
+		return fmt.Sprintf(
+			"%s%s at %s:\n\n%s",
+			marker,
+			header,
+			comment.CreatedAt.Format(commentDateFormat),
+			commentBody,
+		), nil
+	}
+
+	var header strings.Builder
+	err := tmpl.Execute(&header, CommentHeader{
+		ID:        comment.GetID(),
+		URL:       comment.GetHTMLURL(),
+		Login:     user.GetLogin(),
+		UserURL:   user.GetHTMLURL(),
+		Name:      user.GetName(),
+		CreatedAt: comment.GetCreatedAt().Time,
+	})
+	if err != nil {
+		return "", fmt.Errorf("rendering comment template: %w", err)
+	}
+
+	// The body starts after the first blank line, so the header must not
+	// contain line breaks.
+	headerLine := strings.ReplaceAll(strings.TrimSpace(header.String()), "\n", " ")
+	if j.usesADF() {
+		headerLine = link(id, comment.GetHTMLURL()) + " " + headerLine
+	}
+
+	return marker + headerLine + "\n\n" + commentBody, nil
+}
+
 // CreateComment adds a comment to the provided Jira issue using the fields from
 // the provided GitHub comment. It then returns the created comment.
 func (j *jiraClient) CreateComment(
@@ -301,17 +749,14 @@ func (j *jiraClient) CreateComment(
 		return nil, fmt.Errorf("getting GitHub user: %w", err)
 	}
 
-	body := fmt.Sprintf("Comment [(ID %d)|%s]", comment.GetID(), comment.GetHTMLURL())
-	body = fmt.Sprintf("%s from GitHub user [%s|%s]", body, user.GetLogin(), user.GetHTMLURL())
-	if user.GetName() != "" {
-		body = fmt.Sprintf("%s (%s)", body, user.GetName())
+	// The body is converted before it is truncated, so that the length
+	// limit applies to what is actually posted.
+	commentBody := j.commentBody(comment)
+
+	body, err := j.commentText(comment, user, commentBody)
+	if err != nil {
+		return nil, err
 	}
-	body = fmt.Sprintf(
-		"%s at %s:\n\n%s",
-		body,
-		comment.CreatedAt.Format(commentDateFormat),
-		comment.GetBody(),
-	)
 
 	if len(body) > maxBodyLength {
 		body = body[:maxBodyLength]
@@ -322,21 +767,10 @@ func (j *jiraClient) CreateComment(
 	}
 
 	// TODO(dry-run): Simplify logic
-	if !j.dryRun { //nolint:nestif // TODO(lint): complex nested blocks (nestif)
-		com, res, err := j.request(func() (interface{}, *jira.Response, error) {
-			return j.client.Issue.AddComment(j.cfg.Context(), issue.ID, newComment) //nolint:wrapcheck
-		})
+	if !j.dryRun {
+		co, err := j.addComment(issue, newComment)
 		if err != nil {
-			log.Errorf("Error creating Jira comment on issue %s. Error: %v", issue.Key, err)
-			return nil, getErrorBody(res)
-		}
-		co, ok := com.(*jira.Comment)
-		if !ok {
-			log.Errorf("Create Jira comment did not return comment! Got: %v", com)
-			return nil, fmt.Errorf( //nolint:goerr113
-				"create Jira comment failed: expected *jira.Comment; got %T",
-				com,
-			)
+			return nil, err
 		}
 
 		newComment = co
@@ -350,13 +784,69 @@ func (j *jiraClient) CreateComment(
 			log.Infof("  User: %s", user.GetLogin())
 		}
 		log.Infof("  Posted at: %s", comment.CreatedAt.Format(commentDateFormat))
-		log.Infof("  Body: %s", truncate(comment.GetBody(), 100))
+		log.Infof("  Body: %s", truncate(commentBody, 100))
 		log.Info("")
 	}
 
 	return newComment, nil
 }
 
+// AddComment adds a comment with the provided body to the Jira issue. It
+// then returns the created comment.
+func (j *jiraClient) AddComment(issue *jira.Issue, body string) (*jira.Comment, error) {
+	if len(body) > maxBodyLength {
+		body = body[:maxBodyLength]
+	}
+
+	newComment := &jira.Comment{
+		Body: body,
+	}
+
+	// TODO(dry-run): Simplify logic
+	if j.dryRun {
+		log.Info("")
+		log.Infof("Create comment on Jira issue %s:", issue.Key)
+		log.Infof("  Body: %s", truncate(body, 100))
+		log.Info("")
+
+		return newComment, nil
+	}
+
+	return j.addComment(issue, newComment)
+}
+
+// addComment adds the comment to the Jira issue using the real client. With
+// ADF, the request is built from the comment, as the client only supports
+// version 2 of the Jira REST API.
+func (j *jiraClient) addComment(issue *jira.Issue, comment *jira.Comment) (*jira.Comment, error) {
+	if j.usesADF() {
+		co, err := j.sendComment(http.MethodPost, fmt.Sprintf("issue/%s/comment", issue.Key), comment)
+		if err != nil {
+			log.Errorf("Error creating Jira comment on issue %s. Error: %v", issue.Key, err)
+			return nil, err
+		}
+		return co, nil
+	}
+
+	com, res, err := j.request(func() (interface{}, *jira.Response, error) {
+		return j.client.Issue.AddComment(j.cfg.RequestContext(), issue.ID, comment) //nolint:wrapcheck
+	})
+	if err != nil {
+		log.Errorf("Error creating Jira comment on issue %s. Error: %v", issue.Key, err)
+		return nil, getErrorBody(res, err)
+	}
+	co, ok := com.(*jira.Comment)
+	if !ok {
+		log.Errorf("Create Jira comment did not return comment! Got: %v", com)
+		return nil, fmt.Errorf( //nolint:goerr113
+			"create Jira comment failed: expected *jira.Comment; got %T",
+			com,
+		)
+	}
+
+	return co, nil
+}
+
 // UpdateComment updates a comment (identified by the `id` parameter) on a given
 // Jira with a new body from the fields of the given GitHub comment. It returns
 // the updated comment.
@@ -371,17 +861,14 @@ func (j *jiraClient) UpdateComment(
 		return nil, fmt.Errorf("getting GitHub user: %w", err)
 	}
 
-	body := fmt.Sprintf("Comment [(ID %d)|%s]", comment.GetID(), comment.GetHTMLURL())
-	body = fmt.Sprintf("%s from GitHub user [%s|%s]", body, user.GetLogin(), user.GetHTMLURL())
-	if user.GetName() != "" {
-		body = fmt.Sprintf("%s (%s)", body, user.GetName())
+	// The body is converted before it is truncated, so that the length
+	// limit applies to what is actually posted.
+	commentBody := j.commentBody(comment)
+
+	body, err := j.commentText(comment, user, commentBody)
+	if err != nil {
+		return nil, err
 	}
-	body = fmt.Sprintf(
-		"%s at %s:\n\n%s",
-		body,
-		comment.CreatedAt.Format(commentDateFormat),
-		comment.GetBody(),
-	)
 
 	if len(body) > maxBodyLength {
 		body = body[:maxBodyLength]
@@ -393,41 +880,13 @@ func (j *jiraClient) UpdateComment(
 	}
 
 	// TODO(dry-run): Simplify logic
-	if !j.dryRun { //nolint:nestif // TODO(lint): complex nested blocks (nestif)
+	if !j.dryRun {
 		// As it is, the Jira API we're using doesn't have any way to update comments natively.
 		// So, we have to build the request ourselves.
-		request := struct {
-			Body string `json:"body"`
-		}{
-			Body: body,
-		}
-
-		req, err := j.client.NewRequest(
-			j.cfg.Context(),
-			"PUT",
-			fmt.Sprintf("rest/api/2/issue/%s/comment/%s", issue.Key, id),
-			request,
-		)
-		if err != nil {
-			log.Errorf("Error creating comment update request: %s", err)
-			return nil, fmt.Errorf("creating comment update request: %w", err)
-		}
-
-		com, res, err := j.request(func() (interface{}, *jira.Response, error) {
-			res, err := j.client.Do(req, nil)
-			return nil, res, err //nolint:wrapcheck
-		})
+		co, err := j.sendComment(http.MethodPut, fmt.Sprintf("issue/%s/comment/%s", issue.Key, id), updatedComment)
 		if err != nil {
 			log.Errorf("Error updating comment: %+v", err)
-			return nil, getErrorBody(res)
-		}
-		co, ok := com.(*jira.Comment)
-		if !ok {
-			log.Errorf("Update Jira comment did not return comment! Got: %v", com)
-			return nil, fmt.Errorf( //nolint:goerr113
-				"update Jira comment failed: expected *jira.Comment; got %T",
-				com,
-			)
+			return nil, err
 		}
 
 		updatedComment = co
@@ -441,17 +900,402 @@ func (j *jiraClient) UpdateComment(
 			log.Infof("  User: %s", user.GetLogin())
 		}
 		log.Infof("  Posted at: %s", comment.CreatedAt.Format(commentDateFormat))
-		log.Infof("  Body: %s", truncate(comment.GetBody(), 100))
+		log.Infof("  Body: %s", truncate(commentBody, 100))
 		log.Info("")
 	}
 
 	return updatedComment, nil
 }
 
+// sendComment sends a request with the body of the comment to the endpoint of
+// the Jira REST API, and returns the comment with the ID that Jira responded
+// with. With ADF, the body is sent as the ADF document of markup.ToADF, and
+// the body of the returned comment is the JSON of that document.
+func (j *jiraClient) sendComment(method, endpoint string, comment *jira.Comment) (*jira.Comment, error) {
+	var body interface{} = comment.Body
+	if j.usesADF() {
+		body = markup.ToADF(comment.Body)
+	}
+
+	request := struct {
+		Body interface{} `json:"body"`
+	}{
+		Body: body,
+	}
+
+	req, err := j.client.NewRequest(j.cfg.RequestContext(), method, j.cfg.JiraAPIPath(endpoint), request)
+	if err != nil {
+		return nil, fmt.Errorf("creating comment request: %w", err)
+	}
+
+	// Only the ID of the response is decoded: its body is an ADF document
+	// with version 3, which jira.Comment can't hold.
+	var response struct {
+		ID string `json:"id"`
+	}
+	_, res, err := j.request(func() (interface{}, *jira.Response, error) {
+		res, err := j.client.Do(req, &response)
+		return nil, res, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, getErrorBody(res, err)
+	}
+
+	sent := &jira.Comment{
+		ID:   response.ID,
+		Body: comment.Body,
+	}
+	if sent.ID == "" {
+		sent.ID = comment.ID
+	}
+	if j.usesADF() {
+		doc, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encoding comment document: %w", err)
+		}
+		sent.Body = string(doc)
+	}
+
+	return sent, nil
+}
+
+// CreateIssueLink links two Jira issues with the link type, inward issue and
+// outward issue (identified by their Key fields) of the provided link.
+func (j *jiraClient) CreateIssueLink(link *jira.IssueLink) error {
+	// TODO(dry-run): Simplify logic
+	if j.dryRun {
+		log.Info("")
+		log.Infof("Create %s link on Jira issues:", link.Type.Name)
+		log.Infof("  Inward issue: %s", link.InwardIssue.Key)
+		log.Infof("  Outward issue: %s", link.OutwardIssue.Key)
+		log.Info("")
+
+		return nil
+	}
+
+	_, res, err := j.request(func() (interface{}, *jira.Response, error) {
+		res, err := j.client.Issue.AddLink(j.cfg.RequestContext(), link)
+		return nil, res, err //nolint:wrapcheck
+	})
+	if err != nil {
+		log.Errorf(
+			"Error creating %s link from Jira issue %s to %s: %v",
+			link.Type.Name,
+			link.InwardIssue.Key,
+			link.OutwardIssue.Key,
+			err,
+		)
+		return getErrorBody(res, err)
+	}
+
+	return nil
+}
+
+// AddRemoteLink adds a remote link to the Jira issue, or updates its link
+// with the same global ID.
+func (j *jiraClient) AddRemoteLink(issue *jira.Issue, link *jira.RemoteLink) error {
+	// TODO(dry-run): Simplify logic
+	if j.dryRun {
+		log.Info("")
+		log.Infof("Add remote link to Jira issue %s:", issue.Key)
+		log.Infof("  Title: %s", link.Object.Title)
+		log.Infof("  URL: %s", link.Object.URL)
+		log.Info("")
+
+		return nil
+	}
+
+	_, res, err := j.request(func() (interface{}, *jira.Response, error) {
+		return j.client.Issue.AddRemoteLink(j.cfg.RequestContext(), issue.Key, link) //nolint:wrapcheck
+	})
+	if err != nil {
+		log.Errorf("Error adding remote link %s to Jira issue %s: %v", link.Object.URL, issue.Key, err)
+		return getErrorBody(res, err)
+	}
+
+	return nil
+}
+
+// errLastSyncFieldMissing is returned by GetLastSyncTime if the
+// `github-last-sync` field is listed in `optional-fields` and does not exist.
+var errLastSyncFieldMissing = errors.New(
+	"the since date can't be derived from Jira without the github-last-sync custom field",
+)
+
+// RefreshFieldIDs pulls the IDs of the custom fields from Jira again if the
+// `field-refresh-interval` elapsed since they were last pulled. It only
+// reads from Jira, so it also does in dry-run mode.
+func (j *jiraClient) RefreshFieldIDs() (bool, error) {
+	refreshed, err := j.cfg.RefreshFieldIDs(j.client)
+	if err != nil {
+		return false, fmt.Errorf("refreshing custom field IDs: %w", err)
+	}
+	return refreshed, nil
+}
+
+// GetLastSyncTime returns the latest `github-last-sync` value across the
+// issues of the configured project, or the zero time if no issue has been
+// synchronized yet.
+func (j *jiraClient) GetLastSyncTime() (time.Time, error) {
+	if !j.cfg.HasField(config.GitHubLastSync) {
+		return time.Time{}, errLastSyncFieldMissing
+	}
+
+	fieldID := j.cfg.GetFieldID(config.GitHubLastSync)
+	jql := fmt.Sprintf(
+		"project='%s' AND cf[%s] is not EMPTY ORDER BY cf[%s] DESC",
+		j.cfg.GetProjectKey(),
+		fieldID,
+		fieldID,
+	)
+	log.Debugf("JQL query used: %s", jql)
+
+	searchOpts := &jira.SearchOptions{
+		MaxResults: lastSyncSearchResults,
+		Fields:     []string{j.cfg.GetFieldKey(config.GitHubLastSync)},
+	}
+
+	i, res, err := j.request(func() (interface{}, *jira.Response, error) {
+		return j.client.Issue.Search(j.cfg.RequestContext(), jql, searchOpts) //nolint:wrapcheck
+	})
+	if err != nil {
+		log.Errorf("Error retrieving last synchronized Jira issues: %+v", err)
+		return time.Time{}, getErrorBody(res, err)
+	}
+	issues, ok := i.([]jira.Issue)
+	if !ok {
+		return time.Time{}, fmt.Errorf("search Jira issues failed: expected []jira.Issue; got %T", i) //nolint:goerr113
+	}
+
+	// The results are already sorted, but the maximum is computed anyway, as
+	// the order of values Jira can't parse as dates is undefined.
+	var latest time.Time
+	for k := range issues {
+		value, err := FieldString(&issues[k], j.cfg.GetFieldKey(config.GitHubLastSync))
+		if err != nil {
+			continue
+		}
+
+		t, err := time.Parse(options.DateFormat, value)
+		if err != nil {
+			log.Debugf("Ignoring invalid `github-last-sync` value %q on Jira issue %s", value, issues[k].Key)
+			continue
+		}
+
+		if t.After(latest) {
+			latest = t
+		}
+	}
+
+	return latest, nil
+}
+
+// UpdateVersion updates a version of the configured project, identified by
+// its ID, with the non-empty fields of the provided version.
+func (j *jiraClient) UpdateVersion(version *jira.Version) (*jira.Version, error) {
+	// TODO(dry-run): Simplify logic
+	if j.dryRun {
+		log.Info("")
+		log.Infof("Update Jira version %s:", version.Name)
+		if version.Released != nil {
+			log.Infof("  Released: %t", *version.Released)
+		}
+		if version.ReleaseDate != "" {
+			log.Infof("  Release date: %s", version.ReleaseDate)
+		}
+		log.Info("")
+
+		return version, nil
+	}
+
+	v, res, err := j.request(func() (interface{}, *jira.Response, error) {
+		return j.client.Version.Update(j.cfg.RequestContext(), version) //nolint:wrapcheck
+	})
+	if err != nil {
+		log.Errorf("Error updating Jira version %s: %v", version.Name, err)
+		return nil, getErrorBody(res, err)
+	}
+	updated, ok := v.(*jira.Version)
+	if !ok {
+		log.Errorf("Update Jira version did not return version! Got: %v", v)
+		return nil, fmt.Errorf("update Jira version failed: expected *jira.Version; got %T", v) //nolint:goerr113
+	}
+
+	return updated, nil
+}
+
+// EnsureVersion returns the version of the configured project with the given
+// name, creating it if it doesn't exist yet.
+func (j *jiraClient) EnsureVersion(name string) (*jira.Version, error) {
+	project := j.cfg.GetProject()
+	for i := range project.Versions {
+		if project.Versions[i].Name == name {
+			return &project.Versions[i], nil
+		}
+	}
+
+	projectID, err := strconv.Atoi(project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("parsing ID %q of Jira project %s: %w", project.ID, project.Key, err)
+	}
+
+	version := &jira.Version{
+		Name:      name,
+		ProjectID: projectID,
+	}
+
+	// TODO(dry-run): Simplify logic
+	if j.dryRun {
+		log.Info("")
+		log.Infof("Create Jira version %s", name)
+		log.Info("")
+	} else {
+		v, res, err := j.request(func() (interface{}, *jira.Response, error) {
+			return j.client.Version.Create(j.cfg.RequestContext(), version) //nolint:wrapcheck
+		})
+		if err != nil {
+			log.Errorf("Error creating Jira version %s: %v", name, err)
+			return nil, getErrorBody(res, err)
+		}
+		created, ok := v.(*jira.Version)
+		if !ok {
+			log.Errorf("Create Jira version did not return version! Got: %v", v)
+			return nil, fmt.Errorf("create Jira version failed: expected *jira.Version; got %T", v) //nolint:goerr113
+		}
+		version = created
+
+		log.Infof("Created Jira version %s", name)
+	}
+
+	// The project versions are only loaded once, so record the version to
+	// avoid creating it again for the other issues of the milestone.
+	project.Versions = append(project.Versions, *version)
+
+	return version, nil
+}
+
+// ClearFixVersions removes every fix version of the Jira issue. Fix versions
+// can't be cleared with UpdateIssue, which omits empty fields.
+func (j *jiraClient) ClearFixVersions(issue *jira.Issue) error {
+	// TODO(dry-run): Simplify logic
+	if j.dryRun {
+		log.Info("")
+		log.Infof("Clear fix versions of Jira issue %s", issue.Key)
+		log.Info("")
+
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"fixVersions": []interface{}{},
+		},
+	}
+
+	_, res, err := j.request(func() (interface{}, *jira.Response, error) {
+		res, err := j.client.Issue.UpdateIssue(j.cfg.RequestContext(), issue.Key, data)
+		return nil, res, err //nolint:wrapcheck
+	})
+	if err != nil {
+		log.Errorf("Error clearing fix versions of Jira issue %s: %v", issue.Key, err)
+		return getErrorBody(res, err)
+	}
+
+	return nil
+}
+
+// FindSprint returns the active or future sprint of the `jira-board-id` board
+// with the given name, or nil if there is none. Closed sprints are ignored,
+// as issues can't be moved to them.
+func (j *jiraClient) FindSprint(name string) (*jira.Sprint, error) {
+	boardID := j.cfg.GetJiraBoardID()
+	opts := &jira.GetAllSprintsOptions{State: "active,future"}
+
+	for {
+		s, res, err := j.request(func() (interface{}, *jira.Response, error) {
+			return j.client.Board.GetAllSprints(j.cfg.RequestContext(), boardID, opts) //nolint:wrapcheck
+		})
+		if err != nil {
+			log.Errorf("Error retrieving sprints of Jira board %d: %v", boardID, err)
+			return nil, getErrorBody(res, err)
+		}
+		sprints, ok := s.(*jira.SprintsList)
+		if !ok {
+			log.Errorf("Get Jira sprints did not return sprints! Got: %v", s)
+			return nil, fmt.Errorf("get Jira sprints failed: expected *jira.SprintsList; got %T", s) //nolint:goerr113
+		}
+
+		for i := range sprints.Values {
+			if sprints.Values[i].Name == name {
+				return &sprints.Values[i], nil
+			}
+		}
+
+		if sprints.IsLast || len(sprints.Values) == 0 {
+			return nil, nil
+		}
+		opts.StartAt += len(sprints.Values)
+	}
+}
+
+// GetTransitions returns the workflow transitions available from the current
+// status of the Jira issue with the given key.
+func (j *jiraClient) GetTransitions(issueKey string) ([]jira.Transition, error) {
+	t, res, err := j.request(func() (interface{}, *jira.Response, error) {
+		return j.client.Issue.GetTransitions(j.cfg.RequestContext(), issueKey) //nolint:wrapcheck
+	})
+	if err != nil {
+		log.Errorf("Error retrieving transitions of Jira issue %s: %v", issueKey, err)
+		return nil, getErrorBody(res, err)
+	}
+	transitions, ok := t.([]jira.Transition)
+	if !ok {
+		log.Errorf("Get Jira transitions did not return transitions! Got: %v", t)
+		return nil, fmt.Errorf("get Jira transitions failed: expected []jira.Transition; got %T", t) //nolint:goerr113
+	}
+
+	return transitions, nil
+}
+
+// DoTransition performs the workflow transition with the given ID on the
+// Jira issue. The transition must be available from the current status of
+// the issue; see GetTransitions.
+func (j *jiraClient) DoTransition(issue *jira.Issue, transitionID string) error {
+	// TODO(dry-run): Simplify logic
+	if j.dryRun {
+		log.Info("")
+		log.Infof("Transition Jira issue %s:", issue.Key)
+		log.Infof("  Transition: %s", transitionID)
+		log.Info("")
+
+		return nil
+	}
+
+	_, res, err := j.request(func() (interface{}, *jira.Response, error) {
+		res, err := j.client.Issue.DoTransition(j.cfg.RequestContext(), issue.Key, transitionID)
+		return nil, res, err //nolint:wrapcheck
+	})
+	if err != nil {
+		log.Errorf("Error transitioning Jira issue %s: %v", issue.Key, err)
+		return getErrorBody(res, err)
+	}
+
+	log.Debugf("Performed transition %s on Jira issue %s", transitionID, issue.Key)
+
+	return nil
+}
+
 // request executes a Jira request with exponential backoff, using the real
 // client.
 func (j *jiraClient) request(f func() (interface{}, *jira.Response, error)) (interface{}, *jira.Response, error) {
-	ret, resp, err := synchttp.NewJiraRequest(f, j.cfg.GetTimeout())
+	retry := synchttp.Retry{
+		InitialInterval: j.cfg.GetRetryInitialInterval(),
+		MaxInterval:     j.cfg.GetRetryMaxInterval(),
+		Multiplier:      j.cfg.GetRetryMultiplier(),
+	}
+	ret, resp, err := synchttp.NewJiraRequest(
+		j.cfg.RequestContext(), f, j.cfg.GetTimeout(), retry, j.cfg.GetMaxRetryAfter(), j.cfg.ShouldLogRateLimitWaits(),
+	)
 	if err != nil {
 		return ret, resp, fmt.Errorf("request error: %w", err)
 	}
@@ -478,28 +1322,34 @@ func truncate(s string, length int) string {
 	return fmt.Sprintf("%s...", s[0:length])
 }
 
-func getJQLQuery(projectKey, fieldID string, ids []int) string {
-	idStrs := make([]string, len(ids))
-	for i, v := range ids {
-		idStrs[i] = fmt.Sprint(v)
-	}
+// getJQLQueries returns the JQL queries matching the Jira issues of the
+// GitHub issues with the given IDs, each listing at most maxJQLIssueLength
+// IDs, so that the issues are always filtered by Jira rather than by
+// scanning the whole project. The queries must not filter on the status of
+// the Jira issues: a done or archived Jira issue which is not matched would
+// have a duplicate created for its GitHub issue.
+func getJQLQueries(projectKey, fieldID string, ids []int) []string {
+	var queries []string
+	for start := 0; start < len(ids); start += maxJQLIssueLength {
+		end := min(start+maxJQLIssueLength, len(ids))
+
+		idStrs := make([]string, 0, end-start)
+		for _, v := range ids[start:end] {
+			idStrs = append(idStrs, fmt.Sprint(v))
+		}
 
-	// If the list of IDs is too long, we get a 414 Request-URI Too Large, so in that case,
-	// we'll need to do the filtering ourselves.
-	var jql string
-	if len(ids) < maxJQLIssueLength {
-		jql = fmt.Sprintf(
+		jql := fmt.Sprintf(
 			"project='%s' AND cf[%s] in (%s)",
 			projectKey,
 			fieldID,
 			strings.Join(idStrs, ","),
 		)
-	} else {
-		jql = fmt.Sprintf("project='%s'", projectKey)
+		log.Debugf("JQL query used: %s", jql)
+
+		queries = append(queries, jql)
 	}
 
-	log.Debugf("JQL query used: %s", jql)
-	return jql
+	return queries
 }
 
 // getErrorBody reads the HTTP response body of a Jira API response,
@@ -507,7 +1357,14 @@ func getJQLQuery(projectKey, fieldID string, ids []int) string {
 // of the body. If an error occurs during reading, that error is
 // instead printed and returned. This function closes the body for
 // further reading.
-func getErrorBody(res *jira.Response) error {
+//
+// A request which failed without a response, such as on a network error or
+// once its context is done, has no body to read, so err is returned as is.
+func getErrorBody(res *jira.Response, err error) error {
+	if res == nil || res.Body == nil {
+		return err
+	}
+
 	defer res.Body.Close()
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
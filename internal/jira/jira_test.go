@@ -0,0 +1,869 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+	"github.com/trivago/tgo/tcontainer"
+	jira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/config/configtest"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/markup"
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/options"
+)
+
+// newTestClient returns a jiraClient which sends its requests to the
+// handler and uses a test configuration with the provided values.
+func newTestClient(t *testing.T, handler http.HandlerFunc, values map[string]interface{}) *jiraClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := jira.NewClient(server.URL, server.Client())
+	if err != nil {
+		t.Fatalf("creating Jira client: %v", err)
+	}
+
+	cfg := configtest.NewConfig(context.Background(), values)
+
+	return &jiraClient{
+		cfg:    cfg,
+		client: client,
+		dryRun: cfg.IsDryRun(),
+	}
+}
+
+// abortHandler closes the connection of every request without writing a
+// response, so that the requests fail the way they do on a network error.
+func abortHandler(w http.ResponseWriter, r *http.Request) {
+	panic(http.ErrAbortHandler)
+}
+
+func TestGetLastSyncTime(t *testing.T) {
+	fieldKey := "customfield_" + configtest.FieldIDGitHubLastSync
+
+	var jql string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		jql = r.URL.Query().Get("jql")
+		fmt.Fprintf(w, `{"issues": [
+			{"key": "TEST-3", "fields": {%[1]q: "2023-03-04T10:00:00.000+0000"}},
+			{"key": "TEST-1", "fields": {%[1]q: "2023-05-06T08:30:00.000+0200"}},
+			{"key": "TEST-2", "fields": {%[1]q: "not a date"}}
+		]}`, fieldKey)
+	}
+
+	j := newTestClient(t, handler, map[string]interface{}{options.ConfigKeyTimeout: time.Second})
+
+	since, err := j.GetLastSyncTime()
+	if err != nil {
+		t.Fatalf("GetLastSyncTime() returned error: %v", err)
+	}
+
+	expected := time.Date(2023, time.May, 6, 6, 30, 0, 0, time.UTC)
+	if !since.Equal(expected) {
+		t.Fatalf("Expected last sync time %v; got %v", expected, since)
+	}
+
+	if !strings.Contains(jql, "ORDER BY cf["+configtest.FieldIDGitHubLastSync+"] DESC") {
+		t.Fatalf("Expected JQL query ordered by the last sync field; got %q", jql)
+	}
+}
+
+func TestGetLastSyncTimeNoIssues(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"issues": []}`)
+	}
+
+	j := newTestClient(t, handler, map[string]interface{}{options.ConfigKeyTimeout: time.Second})
+
+	since, err := j.GetLastSyncTime()
+	if err != nil {
+		t.Fatalf("GetLastSyncTime() returned error: %v", err)
+	}
+	if !since.IsZero() {
+		t.Fatalf("Expected zero last sync time; got %v", since)
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	var performed string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/TEST-1/transitions" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			var body struct {
+				Transition struct {
+					ID string `json:"id"`
+				} `json:"transition"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decoding transition request: %v", err)
+			}
+			performed = body.Transition.ID
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		fmt.Fprint(w, `{"transitions": [
+			{"id": "11", "name": "Start Progress", "to": {"name": "In Progress"}},
+			{"id": "31", "name": "Done", "to": {"name": "Done"}}
+		]}`)
+	}
+
+	j := newTestClient(t, handler, map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+		options.ConfigKeyTimeout: time.Second,
+	})
+
+	transitions, err := j.GetTransitions("TEST-1")
+	if err != nil {
+		t.Fatalf("GetTransitions() returned error: %v", err)
+	}
+	if len(transitions) != 2 || transitions[1].Name != "Done" || transitions[1].To.Name != "Done" {
+		t.Fatalf("Expected the two available transitions; got %+v", transitions)
+	}
+
+	if err := j.DoTransition(&jira.Issue{Key: "TEST-1"}, "31"); err != nil {
+		t.Fatalf("DoTransition() returned error: %v", err)
+	}
+	if performed != "31" {
+		t.Fatalf("Expected transition 31 to be performed; got %q", performed)
+	}
+}
+
+func TestGetTransitionsWithoutResponse(t *testing.T) {
+	j := newTestClient(t, abortHandler, map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+		options.ConfigKeyTimeout: 10 * time.Millisecond,
+	})
+
+	if _, err := j.GetTransitions("TEST-1"); err == nil {
+		t.Fatal("Expected GetTransitions() to return an error")
+	}
+}
+
+func TestDoTransitionDryRun(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request %s %s in dry run", r.Method, r.URL.Path)
+	}
+
+	j := newTestClient(t, handler, map[string]interface{}{options.ConfigKeyTimeout: time.Second})
+
+	if err := j.DoTransition(&jira.Issue{Key: "TEST-1"}, "31"); err != nil {
+		t.Fatalf("DoTransition() returned error: %v", err)
+	}
+}
+
+func TestEnsureVersion(t *testing.T) {
+	var requested []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/api/2/version" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+
+		var body jira.Version
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding version request: %v", err)
+		}
+		requested = append(requested, body.Name)
+		fmt.Fprintf(w, `{"id": "201", "name": %q, "projectId": %d}`, body.Name, body.ProjectID)
+	}
+
+	j := newTestClient(t, handler, map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+		options.ConfigKeyTimeout: time.Second,
+	})
+	project := j.cfg.GetProject()
+	project.ID = "10000"
+	project.Versions = []jira.Version{{ID: "200", Name: "v1.0"}}
+
+	version, err := j.EnsureVersion("v1.0")
+	if err != nil {
+		t.Fatalf("EnsureVersion() returned error: %v", err)
+	}
+	if version.ID != "200" {
+		t.Fatalf("Expected existing version 200; got %+v", version)
+	}
+
+	for i := 0; i < 2; i++ {
+		version, err = j.EnsureVersion("v1.1")
+		if err != nil {
+			t.Fatalf("EnsureVersion() returned error: %v", err)
+		}
+		if version.ID != "201" || version.ProjectID != 10000 {
+			t.Fatalf("Expected created version 201 of project 10000; got %+v", version)
+		}
+	}
+
+	if len(requested) != 1 || requested[0] != "v1.1" {
+		t.Fatalf("Expected version v1.1 to be created once; got %v", requested)
+	}
+}
+
+func TestUpdateVersionWithoutResponse(t *testing.T) {
+	j := newTestClient(t, abortHandler, map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+		options.ConfigKeyTimeout: 10 * time.Millisecond,
+	})
+
+	if _, err := j.UpdateVersion(&jira.Version{ID: "200", Name: "v1.0", Released: new(bool)}); err == nil {
+		t.Fatal("Expected UpdateVersion() to return an error")
+	}
+}
+
+func TestFindSprintWithoutResponse(t *testing.T) {
+	j := newTestClient(t, abortHandler, map[string]interface{}{
+		options.ConfigKeyConfirm:     true,
+		options.ConfigKeyTimeout:     10 * time.Millisecond,
+		options.ConfigKeyJiraBoardID: 7,
+	})
+
+	if _, err := j.FindSprint("Sprint 1"); err == nil {
+		t.Fatal("Expected FindSprint() to return an error")
+	}
+}
+
+func TestCreateCommentConvertsMarkdown(t *testing.T) {
+	var posted string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/api/2/issue/10000/comment" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+
+		var body jira.Comment
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding comment request: %v", err)
+		}
+		posted = body.Body
+		fmt.Fprintf(w, `{"id": "20000", "body": %q}`, body.Body)
+	}
+
+	j := newTestClient(t, handler, map[string]interface{}{
+		options.ConfigKeyConfirm:         true,
+		options.ConfigKeyTimeout:         time.Second,
+		options.ConfigKeyConvertMarkdown: true,
+	})
+
+	ghComment := &gogh.IssueComment{
+		ID:        gogh.Int64(484163403),
+		HTMLURL:   gogh.String("https://github.com"),
+		User:      &gogh.User{Login: gogh.String("bilbo-baggins")},
+		Body:      gogh.String("**Fixed** in [v1.2](https://example.com/v1.2)"),
+		CreatedAt: &gogh.Timestamp{Time: time.Date(2019, time.April, 17, 16, 27, 0, 0, time.UTC)},
+	}
+	ghClient := &github.GitHubClientMock{
+		GetUserFn: func(login string) (*gogh.User, error) {
+			return &gogh.User{Login: gogh.String(login), HTMLURL: gogh.String("https://github.com/" + login)}, nil
+		},
+	}
+
+	if _, err := j.CreateComment(&jira.Issue{ID: "10000", Key: "TEST-1"}, ghComment, ghClient); err != nil {
+		t.Fatalf("CreateComment() returned error: %v", err)
+	}
+
+	if !strings.HasSuffix(posted, ":\n\n*Fixed* in [v1.2|https://example.com/v1.2]") {
+		t.Fatalf("Expected the comment body to be converted to wiki markup; got %q", posted)
+	}
+	if !strings.HasPrefix(posted, "{anchor:gh-comment:484163403}Comment [(ID 484163403)|https://github.com]") {
+		t.Fatalf("Expected the comment to start with the GitHub comment ID marker; got %q", posted)
+	}
+}
+
+func TestCreateCommentPostsADF(t *testing.T) {
+	var posted map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/api/3/issue/TEST-1/comment" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+
+		var body struct {
+			Body map[string]interface{} `json:"body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding comment request: %v", err)
+		}
+		posted = body.Body
+		fmt.Fprint(w, `{"id": "20000", "body": {"version": 1, "type": "doc", "content": []}}`)
+	}
+
+	j := newTestClient(t, handler, map[string]interface{}{
+		options.ConfigKeyConfirm:        true,
+		options.ConfigKeyTimeout:        time.Second,
+		options.ConfigKeyJiraAPIVersion: options.JiraAPIVersion3,
+	})
+
+	ghComment := &gogh.IssueComment{
+		ID:        gogh.Int64(484163403),
+		HTMLURL:   gogh.String("https://github.com"),
+		User:      &gogh.User{Login: gogh.String("bilbo-baggins")},
+		Body:      gogh.String("Fixed in [v1.2](https://example.com/v1.2)"),
+		CreatedAt: &gogh.Timestamp{Time: time.Date(2019, time.April, 17, 16, 27, 0, 0, time.UTC)},
+	}
+	ghClient := &github.GitHubClientMock{
+		GetUserFn: func(login string) (*gogh.User, error) {
+			return &gogh.User{Login: gogh.String(login), HTMLURL: gogh.String("https://github.com/" + login)}, nil
+		},
+	}
+
+	comment, err := j.CreateComment(&jira.Issue{ID: "10000", Key: "TEST-1"}, ghComment, ghClient)
+	if err != nil {
+		t.Fatalf("CreateComment() returned error: %v", err)
+	}
+
+	expected := "Comment (ID 484163403) from GitHub user bilbo-baggins at 16:27 PM, April 17 2019:\n\nFixed in v1.2"
+	if text := markup.ADFText(posted); text != expected {
+		t.Fatalf("Expected the comment to be posted as an ADF document of %q; got %v", expected, posted)
+	}
+
+	if comment.ID != "20000" {
+		t.Fatalf("Expected the created comment to have ID 20000; got %q", comment.ID)
+	}
+	if doc, ok := markup.ParseADF(comment.Body); !ok || markup.ADFText(doc) != expected {
+		t.Fatalf("Expected the body of the created comment to be its ADF document; got %q", comment.Body)
+	}
+}
+
+func TestGetIssueListsADFComments(t *testing.T) {
+	adfBody := func(text string) string {
+		return fmt.Sprintf(`{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":%q}]}]}`, text)
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/2/issue/TEST-1":
+			// Version 2 renders the ADF comments to wiki markup.
+			fmt.Fprint(w, `{"id": "10000", "key": "TEST-1", "fields": {"comment": {"comments": [{"id": "10001", "body": "first"}]}}}`)
+		case "/rest/api/3/issue/TEST-1/comment":
+			// Comments are listed one per page, to cover pagination.
+			switch r.URL.Query().Get("startAt") {
+			case "0":
+				fmt.Fprintf(w, `{"startAt": 0, "maxResults": 1, "total": 2, "comments": [{"id": "10001", "body": %s}]}`, adfBody("first"))
+			case "1":
+				fmt.Fprintf(w, `{"startAt": 1, "maxResults": 1, "total": 2, "comments": [{"id": "10002", "body": %s}]}`, adfBody("second"))
+			default:
+				t.Errorf("Unexpected comment page %q", r.URL.Query().Get("startAt"))
+			}
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+
+	j := newTestClient(t, handler, map[string]interface{}{
+		options.ConfigKeyTimeout:        time.Second,
+		options.ConfigKeyJiraAPIVersion: options.JiraAPIVersion3,
+	})
+
+	issue, err := j.GetIssue("TEST-1")
+	if err != nil {
+		t.Fatalf("GetIssue() returned error: %v", err)
+	}
+
+	comments := issue.Fields.Comments.Comments
+	if len(comments) != 2 {
+		t.Fatalf("Expected 2 comments; got %d", len(comments))
+	}
+	for i, expected := range []string{"first", "second"} {
+		doc, ok := markup.ParseADF(comments[i].Body)
+		if !ok || markup.ADFText(doc) != expected {
+			t.Fatalf("Expected comment %d to be the ADF document of %q; got %q", i, expected, comments[i].Body)
+		}
+	}
+}
+
+func TestCheckAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiVersion string
+		status     int
+		body       string
+		wantErr    error
+	}{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"accountId": "abc", "displayName": "Sync Bot"}`,
+		},
+		{
+			name:    "unauthorized",
+			status:  http.StatusUnauthorized,
+			body:    `{"errorMessages": ["Unauthorized"]}`,
+			wantErr: ErrAuthenticationFailed,
+		},
+		{
+			name:       "API v3",
+			apiVersion: options.JiraAPIVersion3,
+			status:     http.StatusOK,
+			body:       `{"accountId": "abc", "displayName": "Sync Bot"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			apiVersion := options.DefaultJiraAPIVersion
+			if tc.apiVersion != "" {
+				apiVersion = tc.apiVersion
+			}
+
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if r.URL.Path != "/rest/api/"+apiVersion+"/myself" {
+					t.Errorf("Unexpected request path %q", r.URL.Path)
+				}
+				if user, pass, ok := r.BasicAuth(); !ok || user != "sync-bot" || pass != "secret" {
+					t.Errorf("Expected basic authentication as sync-bot, got %q, %q, %t", user, pass, ok)
+				}
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			t.Cleanup(server.Close)
+
+			cfg := configtest.NewConfig(context.Background(), map[string]interface{}{
+				options.ConfigKeyJiraURI:        server.URL,
+				options.ConfigKeyJiraUser:       "sync-bot",
+				options.ConfigKeyJiraPassword:   "secret",
+				options.ConfigKeyJiraAPIVersion: tc.apiVersion,
+			})
+
+			user, err := CheckAuth(cfg)
+			if requests != 1 {
+				t.Fatalf("Expected a single request, got %d", requests)
+			}
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("Expected error %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckAuth() returned error: %v", err)
+			}
+			if user.DisplayName != "Sync Bot" {
+				t.Fatalf("Expected display name %q, got %q", "Sync Bot", user.DisplayName)
+			}
+		})
+	}
+}
+
+func TestListIssuesMatchesAllStatuses(t *testing.T) {
+	fieldKey := "customfield_" + configtest.FieldIDGitHubID
+
+	manyIDs := make([]int, maxJQLIssueLength)
+	for i := range manyIDs {
+		manyIDs[i] = 1001 + i
+	}
+
+	tests := []struct {
+		name string
+		ids  []int
+	}{
+		{name: "filtered by JQL", ids: []int{1001, 1002}},
+		{name: "filtered by chunked JQL", ids: manyIDs},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var jql string
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				jql = r.URL.Query().Get("jql")
+				fmt.Fprintf(w, `{"issues": [
+					{"key": "TEST-1", "fields": {"status": {"name": "Done", "statusCategory": {"key": "done"}}, %[1]q: 1001}},
+					{"key": "TEST-2", "fields": {"status": {"name": "Archived"}, %[1]q: 1002}}
+				]}`, fieldKey)
+			}
+
+			j := newTestClient(t, handler, nil)
+
+			issues, err := j.ListIssues(tc.ids)
+			if err != nil {
+				t.Fatalf("ListIssues() returned error: %v", err)
+			}
+
+			if strings.Contains(strings.ToLower(jql), "status") {
+				t.Errorf("Expected the matching query not to filter on status, got %q", jql)
+			}
+			if len(issues) != 2 {
+				t.Fatalf("Expected the done and archived issues to be matched, got %d issues", len(issues))
+			}
+		})
+	}
+}
+
+func TestGetJQLQueriesChunksIDs(t *testing.T) {
+	ids := func(n int) []int {
+		ids := make([]int, n)
+		for i := range ids {
+			ids[i] = 1001 + i
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name    string
+		ids     []int
+		queries int
+	}{
+		{name: "no IDs", ids: nil, queries: 0},
+		{name: "single chunk", ids: ids(maxJQLIssueLength), queries: 1},
+		{name: "one past the chunk", ids: ids(maxJQLIssueLength + 1), queries: 2},
+		{name: "several chunks", ids: ids(3 * maxJQLIssueLength), queries: 3},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			queries := getJQLQueries(configtest.ProjectKey, configtest.FieldIDGitHubID, tc.ids)
+			if len(queries) != tc.queries {
+				t.Fatalf("Expected %d queries, got %d: %v", tc.queries, len(queries), queries)
+			}
+
+			// Every ID is listed exactly once across the queries.
+			var listed []string
+			for _, jql := range queries {
+				prefix := fmt.Sprintf("project='%s' AND cf[%s] in (", configtest.ProjectKey, configtest.FieldIDGitHubID)
+				if !strings.HasPrefix(jql, prefix) || !strings.HasSuffix(jql, ")") {
+					t.Fatalf("Expected the query to filter on the GitHub ID, got %q", jql)
+				}
+				chunk := strings.Split(strings.TrimSuffix(strings.TrimPrefix(jql, prefix), ")"), ",")
+				if len(chunk) > maxJQLIssueLength {
+					t.Fatalf("Expected at most %d IDs per query, got %d", maxJQLIssueLength, len(chunk))
+				}
+				listed = append(listed, chunk...)
+			}
+			if len(listed) != len(tc.ids) {
+				t.Fatalf("Expected %d IDs listed, got %d", len(tc.ids), len(listed))
+			}
+			for i, id := range tc.ids {
+				if listed[i] != fmt.Sprint(id) {
+					t.Fatalf("Expected ID %d at position %d, got %s", id, i, listed[i])
+				}
+			}
+		})
+	}
+}
+
+func TestListIssuesQueriesEachChunk(t *testing.T) {
+	fieldKey := "customfield_" + configtest.FieldIDGitHubID
+
+	ids := make([]int, maxJQLIssueLength+1)
+	for i := range ids {
+		ids[i] = 1001 + i
+	}
+	last := ids[len(ids)-1]
+
+	var queries []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		jql := r.URL.Query().Get("jql")
+		queries = append(queries, jql)
+
+		// Each chunk matches the Jira issue of its first GitHub ID.
+		first := strings.Split(strings.SplitN(jql, "(", 2)[1], ",")[0]
+		first = strings.TrimSuffix(first, ")")
+		fmt.Fprintf(w, `{"issues": [{"key": "TEST-%[1]s", "fields": {%[2]q: %[1]s}}]}`, first, fieldKey)
+	}
+
+	j := newTestClient(t, handler, nil)
+
+	issues, err := j.ListIssues(ids)
+	if err != nil {
+		t.Fatalf("ListIssues() returned error: %v", err)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("Expected a query per chunk of IDs, got %d: %v", len(queries), queries)
+	}
+	if !strings.HasSuffix(queries[1], fmt.Sprintf(" in (%d)", last)) {
+		t.Fatalf("Expected the second query to list only the last ID, got %q", queries[1])
+	}
+
+	keys := []string{}
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+	}
+	expected := []string{"TEST-1001", fmt.Sprintf("TEST-%d", last)}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("Expected the issues of both chunks %v, got %v", expected, keys)
+	}
+}
+
+func TestListIssuesRetriesFailedPages(t *testing.T) {
+	fieldKey := "customfield_" + configtest.FieldIDGitHubID
+
+	requests := map[string]int{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		startAt := r.URL.Query().Get("startAt")
+		requests[startAt]++
+
+		w.Header().Set("Content-Type", "application/json")
+		if startAt == "" {
+			fmt.Fprintf(w, `{"startAt": 0, "maxResults": 1, "total": 2, "issues": [
+				{"key": "TEST-1", "fields": {%q: 1001}}
+			]}`, fieldKey)
+			return
+		}
+
+		// The second page fails once with a transient error.
+		if requests[startAt] == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"errorMessages": ["Service Unavailable"]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"startAt": 1, "maxResults": 1, "total": 2, "issues": [
+			{"key": "TEST-2", "fields": {%q: 1002}}
+		]}`, fieldKey)
+	}
+
+	j := newTestClient(t, handler, map[string]interface{}{
+		options.ConfigKeyTimeout: 5 * time.Second,
+	})
+
+	issues, err := j.ListIssues([]int{1001, 1002})
+	if err != nil {
+		t.Fatalf("ListIssues() returned error: %v", err)
+	}
+
+	if len(issues) != 2 || issues[0].Key != "TEST-1" || issues[1].Key != "TEST-2" {
+		t.Fatalf("Expected the issues of both pages; got %v", issues)
+	}
+	if requests[""] != 1 || requests["1"] != 2 {
+		t.Fatalf("Expected only the failed page to be retried; got requests %v", requests)
+	}
+}
+
+func TestCreateCommentUsesTemplate(t *testing.T) {
+	var posted string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body jira.Comment
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding comment request: %v", err)
+		}
+		posted = body.Body
+		fmt.Fprintf(w, `{"id": "20000", "body": %q}`, body.Body)
+	}
+
+	j := newTestClient(t, handler, map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+		options.ConfigKeyTimeout: time.Second,
+		options.ConfigKeyCommentTemplate: "[{{.Login}}|{{.UserURL}}] wrote on " +
+			"{{.CreatedAt.Format \"2006-01-02\"}}\n([GitHub|{{.URL}}]):",
+	})
+
+	ghComment := &gogh.IssueComment{
+		ID:        gogh.Int64(484163403),
+		HTMLURL:   gogh.String("https://github.com/c/1"),
+		User:      &gogh.User{Login: gogh.String("bilbo-baggins")},
+		Body:      gogh.String("Bla blibidy bloo bla"),
+		CreatedAt: &gogh.Timestamp{Time: time.Date(2019, time.April, 17, 16, 27, 0, 0, time.UTC)},
+	}
+	ghClient := &github.GitHubClientMock{
+		GetUserFn: func(login string) (*gogh.User, error) {
+			return &gogh.User{Login: gogh.String(login), HTMLURL: gogh.String("https://github.com/" + login)}, nil
+		},
+	}
+
+	if _, err := j.CreateComment(&jira.Issue{ID: "10000", Key: "TEST-1"}, ghComment, ghClient); err != nil {
+		t.Fatalf("CreateComment() returned error: %v", err)
+	}
+
+	expected := "{anchor:gh-comment:484163403}" +
+		"[bilbo-baggins|https://github.com/bilbo-baggins] wrote on 2019-04-17 ([GitHub|https://github.com/c/1]):" +
+		"\n\nBla blibidy bloo bla"
+	if posted != expected {
+		t.Fatalf("Expected comment %q, got %q", expected, posted)
+	}
+}
+
+func TestUpdateIssueDropsFieldsNotOnScreen(t *testing.T) {
+	reporterKey := "customfield_" + configtest.FieldIDGitHubReporter
+
+	var bodies []map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding update request: %v", err)
+		}
+		bodies = append(bodies, body.Fields)
+
+		if _, ok := body.Fields[reporterKey]; ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"errorMessages": [], "errors": {%q: "Field '%s' cannot be set. It is not on the appropriate screen, or unknown."}}`, reporterKey, reporterKey)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	// The rejected update is not retried with backoff, which would only
+	// fall back once the timeout is reached.
+	j := newTestClient(t, handler, map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+		options.ConfigKeyTimeout: time.Minute,
+	})
+
+	fields := &jira.IssueFields{Summary: "Login page is broken", Unknowns: tcontainer.NewMarshalMap()}
+	fields.Unknowns.Set(reporterKey, "octocat")
+	issue := &jira.Issue{Key: "TEST-1", Fields: fields}
+
+	if _, err := j.UpdateIssue(issue); err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("Expected the update to be retried once; got %d requests", len(bodies))
+	}
+	if _, ok := bodies[1][reporterKey]; ok {
+		t.Fatalf("Expected the retry to omit %s; got %v", reporterKey, bodies[1])
+	}
+	if bodies[1]["summary"] != "Login page is broken" {
+		t.Fatalf("Expected the retry to keep the summary; got %v", bodies[1])
+	}
+	if _, ok := issue.Fields.Unknowns[reporterKey]; !ok {
+		t.Fatal("Expected the issue passed to UpdateIssue to be left untouched")
+	}
+}
+
+func TestCreateIssueFallsBackWithoutReporter(t *testing.T) {
+	var bodies []map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding create request: %v", err)
+		}
+		bodies = append(bodies, body.Fields)
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, ok := body.Fields["reporter"]; ok {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errorMessages": [], "errors": {"reporter": "You do not have permission to modify the reporter of issues."}}`)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "10000", "key": "TEST-1"}`)
+	}
+
+	// The rejected request is not retried with backoff, which would only
+	// fall back once the timeout is reached.
+	j := newTestClient(t, handler, map[string]interface{}{
+		options.ConfigKeyConfirm: true,
+		options.ConfigKeyTimeout: time.Minute,
+	})
+
+	reporterKey := "customfield_" + configtest.FieldIDGitHubReporter
+	fields := &jira.IssueFields{
+		Summary:  "Login page is broken",
+		Reporter: &jira.User{AccountID: "service-account"},
+		Unknowns: tcontainer.NewMarshalMap(),
+	}
+	fields.Unknowns.Set(reporterKey, "octocat")
+	issue := &jira.Issue{Fields: fields}
+
+	created, err := j.CreateIssue(issue)
+	if err != nil {
+		t.Fatalf("CreateIssue() returned error: %v", err)
+	}
+	if created.Key != "TEST-1" {
+		t.Fatalf("Expected TEST-1 to be created; got %q", created.Key)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("Expected a single fallback request after the rejected one; got %d requests", len(bodies))
+	}
+	last := bodies[len(bodies)-1]
+	if _, ok := last["reporter"]; ok {
+		t.Fatalf("Expected the retry to omit the reporter; got %v", last)
+	}
+	if last[reporterKey] != "octocat" {
+		t.Fatalf("Expected the retry to keep the GitHub reporter; got %v", last)
+	}
+	if issue.Fields.Reporter == nil {
+		t.Fatal("Expected the issue passed to CreateIssue to be left untouched")
+	}
+}
+
+func TestFieldString(t *testing.T) {
+	key := "customfield_" + configtest.FieldIDGitHubStatus
+
+	unknowns := tcontainer.NewMarshalMap()
+	unknowns.Set(key, "open")
+	mixedCase := tcontainer.NewMarshalMap()
+	mixedCase.Set(strings.ToUpper(key), "closed")
+	wrongType := tcontainer.NewMarshalMap()
+	wrongType.Set(key, 1001.0)
+	null := tcontainer.NewMarshalMap()
+	null.Set(key, nil)
+
+	tests := []struct {
+		name     string
+		issue    *jira.Issue
+		expected string
+		absent   bool
+	}{
+		{name: "set", issue: &jira.Issue{Fields: &jira.IssueFields{Unknowns: unknowns}}, expected: "open"},
+		{name: "differently cased key", issue: &jira.Issue{Fields: &jira.IssueFields{Unknowns: mixedCase}}, expected: "closed"},
+		{name: "null", issue: &jira.Issue{Fields: &jira.IssueFields{Unknowns: null}}, absent: true},
+		{name: "nil unknowns", issue: &jira.Issue{Fields: &jira.IssueFields{}}, absent: true},
+		{name: "nil fields", issue: &jira.Issue{}, absent: true},
+		{name: "nil issue", issue: nil, absent: true},
+		{name: "not a string", issue: &jira.Issue{Fields: &jira.IssueFields{Unknowns: wrongType}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := FieldString(tc.issue, key)
+			if tc.absent {
+				if !errors.Is(err, ErrFieldAbsent) {
+					t.Fatalf("Expected error %v; got %v", ErrFieldAbsent, err)
+				}
+				return
+			}
+			if tc.expected == "" {
+				if err == nil || errors.Is(err, ErrFieldAbsent) {
+					t.Fatalf("Expected a type error; got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FieldString() returned error: %v", err)
+			}
+			if value != tc.expected {
+				t.Fatalf("Expected %q; got %q", tc.expected, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package jira
+
+import (
+	"time"
+
+	gogh "github.com/google/go-github/v56/github"
+	jira "github.com/uwu-tools/go-jira/v2/cloud"
+
+	"github.com/uwu-tools/gh-jira-issue-sync/internal/github"
+)
+
+// JiraClientMock is a Client whose behaviour is provided by its function
+// fields, for use in tests. Methods whose function field is nil echo back
+// their input, as the dry-run client does, and never fail.
+//
+//nolint:revive // JiraClientMock reads better than ClientMock at call sites
+type JiraClientMock struct {
+	ListIssuesFn         func(ids []int) ([]jira.Issue, error)
+	ListUnlinkedIssuesFn func() ([]jira.Issue, error)
+	ListLinkedIssuesFn   func() ([]jira.Issue, error)
+	GetIssueFn           func(key string) (*jira.Issue, error)
+	CreateIssueFn        func(issue *jira.Issue) (*jira.Issue, error)
+	UpdateIssueFn        func(issue *jira.Issue) (*jira.Issue, error)
+	CreateCommentFn      func(
+		issue *jira.Issue,
+		comment *gogh.IssueComment,
+		githubClient github.Client,
+	) (*jira.Comment, error)
+	UpdateCommentFn func(
+		issue *jira.Issue, id string, comment *gogh.IssueComment, githubClient github.Client,
+	) (*jira.Comment, error)
+	AddCommentFn       func(issue *jira.Issue, body string) (*jira.Comment, error)
+	CreateIssueLinkFn  func(link *jira.IssueLink) error
+	AddRemoteLinkFn    func(issue *jira.Issue, link *jira.RemoteLink) error
+	GetLastSyncTimeFn  func() (time.Time, error)
+	UpdateVersionFn    func(version *jira.Version) (*jira.Version, error)
+	EnsureVersionFn    func(name string) (*jira.Version, error)
+	ClearFixVersionsFn func(issue *jira.Issue) error
+	GetTransitionsFn   func(issueKey string) ([]jira.Transition, error)
+	DoTransitionFn     func(issue *jira.Issue, transitionID string) error
+	RefreshFieldIDsFn  func() (bool, error)
+	FindSprintFn       func(name string) (*jira.Sprint, error)
+}
+
+// ListIssues calls ListIssuesFn.
+func (m *JiraClientMock) ListIssues(ids []int) ([]jira.Issue, error) {
+	if m.ListIssuesFn == nil {
+		return nil, nil
+	}
+	return m.ListIssuesFn(ids)
+}
+
+// ListUnlinkedIssues calls ListUnlinkedIssuesFn.
+func (m *JiraClientMock) ListUnlinkedIssues() ([]jira.Issue, error) {
+	if m.ListUnlinkedIssuesFn == nil {
+		return nil, nil
+	}
+	return m.ListUnlinkedIssuesFn()
+}
+
+// ListLinkedIssues calls ListLinkedIssuesFn.
+func (m *JiraClientMock) ListLinkedIssues() ([]jira.Issue, error) {
+	if m.ListLinkedIssuesFn == nil {
+		return nil, nil
+	}
+	return m.ListLinkedIssuesFn()
+}
+
+// GetIssue calls GetIssueFn. If GetIssueFn is nil, it returns an issue with
+// only the key set.
+func (m *JiraClientMock) GetIssue(key string) (*jira.Issue, error) {
+	if m.GetIssueFn == nil {
+		return &jira.Issue{Key: key, Fields: &jira.IssueFields{}}, nil
+	}
+	return m.GetIssueFn(key)
+}
+
+// CreateIssue calls CreateIssueFn.
+func (m *JiraClientMock) CreateIssue(issue *jira.Issue) (*jira.Issue, error) {
+	if m.CreateIssueFn == nil {
+		return issue, nil
+	}
+	return m.CreateIssueFn(issue)
+}
+
+// UpdateIssue calls UpdateIssueFn.
+func (m *JiraClientMock) UpdateIssue(issue *jira.Issue) (*jira.Issue, error) {
+	if m.UpdateIssueFn == nil {
+		return issue, nil
+	}
+	return m.UpdateIssueFn(issue)
+}
+
+// CreateComment calls CreateCommentFn.
+func (m *JiraClientMock) CreateComment(
+	issue *jira.Issue, comment *gogh.IssueComment, githubClient github.Client,
+) (*jira.Comment, error) {
+	if m.CreateCommentFn == nil {
+		return &jira.Comment{}, nil
+	}
+	return m.CreateCommentFn(issue, comment, githubClient)
+}
+
+// UpdateComment calls UpdateCommentFn.
+func (m *JiraClientMock) UpdateComment(
+	issue *jira.Issue, id string, comment *gogh.IssueComment, githubClient github.Client,
+) (*jira.Comment, error) {
+	if m.UpdateCommentFn == nil {
+		return &jira.Comment{ID: id}, nil
+	}
+	return m.UpdateCommentFn(issue, id, comment, githubClient)
+}
+
+// AddComment calls AddCommentFn. If AddCommentFn is nil, it returns a
+// comment with only the body set.
+func (m *JiraClientMock) AddComment(issue *jira.Issue, body string) (*jira.Comment, error) {
+	if m.AddCommentFn == nil {
+		return &jira.Comment{Body: body}, nil
+	}
+	return m.AddCommentFn(issue, body)
+}
+
+// CreateIssueLink calls CreateIssueLinkFn.
+func (m *JiraClientMock) CreateIssueLink(link *jira.IssueLink) error {
+	if m.CreateIssueLinkFn == nil {
+		return nil
+	}
+	return m.CreateIssueLinkFn(link)
+}
+
+// AddRemoteLink calls AddRemoteLinkFn.
+func (m *JiraClientMock) AddRemoteLink(issue *jira.Issue, link *jira.RemoteLink) error {
+	if m.AddRemoteLinkFn == nil {
+		return nil
+	}
+	return m.AddRemoteLinkFn(issue, link)
+}
+
+// GetLastSyncTime calls GetLastSyncTimeFn. If GetLastSyncTimeFn is nil, it
+// returns the zero time.
+func (m *JiraClientMock) GetLastSyncTime() (time.Time, error) {
+	if m.GetLastSyncTimeFn == nil {
+		return time.Time{}, nil
+	}
+	return m.GetLastSyncTimeFn()
+}
+
+// UpdateVersion calls UpdateVersionFn.
+func (m *JiraClientMock) UpdateVersion(version *jira.Version) (*jira.Version, error) {
+	if m.UpdateVersionFn == nil {
+		return version, nil
+	}
+	return m.UpdateVersionFn(version)
+}
+
+// EnsureVersion calls EnsureVersionFn. If EnsureVersionFn is nil, it returns
+// a version with only the name set.
+func (m *JiraClientMock) EnsureVersion(name string) (*jira.Version, error) {
+	if m.EnsureVersionFn == nil {
+		return &jira.Version{Name: name}, nil
+	}
+	return m.EnsureVersionFn(name)
+}
+
+// ClearFixVersions calls ClearFixVersionsFn.
+func (m *JiraClientMock) ClearFixVersions(issue *jira.Issue) error {
+	if m.ClearFixVersionsFn == nil {
+		return nil
+	}
+	return m.ClearFixVersionsFn(issue)
+}
+
+// GetTransitions calls GetTransitionsFn.
+func (m *JiraClientMock) GetTransitions(issueKey string) ([]jira.Transition, error) {
+	if m.GetTransitionsFn == nil {
+		return nil, nil
+	}
+	return m.GetTransitionsFn(issueKey)
+}
+
+// DoTransition calls DoTransitionFn.
+func (m *JiraClientMock) DoTransition(issue *jira.Issue, transitionID string) error {
+	if m.DoTransitionFn == nil {
+		return nil
+	}
+	return m.DoTransitionFn(issue, transitionID)
+}
+
+// RefreshFieldIDs calls RefreshFieldIDsFn.
+func (m *JiraClientMock) RefreshFieldIDs() (bool, error) {
+	if m.RefreshFieldIDsFn == nil {
+		return false, nil
+	}
+	return m.RefreshFieldIDsFn()
+}
+
+// FindSprint calls FindSprintFn.
+func (m *JiraClientMock) FindSprint(name string) (*jira.Sprint, error) {
+	if m.FindSprintFn == nil {
+		return nil, nil
+	}
+	return m.FindSprintFn(name)
+}
@@ -0,0 +1,208 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markup
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// adfVersion is the version of the Atlassian Document Format documents
+// produced by ToADF.
+const adfVersion = 1
+
+// ToADF converts a GitHub Markdown body to a minimal Atlassian Document
+// Format (ADF) document, as expected by version 3 of the Jira REST API.
+// Fenced code blocks become code blocks, Markdown links become linked text,
+// and the rest of the body becomes paragraphs separated by blank lines, with
+// the line breaks within a paragraph kept. Any other Markdown is left as is.
+//
+// The document is built from maps and slices, so that it marshals to the JSON
+// of the document and compares equal to the document it unmarshals to.
+func ToADF(body string) interface{} {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+
+	content := []interface{}{}
+	var paragraph, code []string
+	fence, language := "", ""
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			content = append(content, adfParagraph(paragraph))
+			paragraph = nil
+		}
+	}
+
+	for _, line := range lines {
+		if fence != "" {
+			if m := fenceRegex.FindStringSubmatch(line); m != nil && m[1] == fence && m[2] == "" {
+				content = append(content, adfCodeBlock(code, language))
+				fence, code = "", nil
+				continue
+			}
+			code = append(code, line)
+			continue
+		}
+
+		if m := fenceRegex.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			fence, language = m[1], m[2]
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flushParagraph()
+			continue
+		}
+
+		paragraph = append(paragraph, line)
+	}
+
+	// A code block left open by the Markdown runs to the end of the body.
+	if fence != "" {
+		content = append(content, adfCodeBlock(code, language))
+	}
+	flushParagraph()
+
+	return map[string]interface{}{
+		"version": float64(adfVersion),
+		"type":    "doc",
+		"content": content,
+	}
+}
+
+// adfParagraph returns the ADF paragraph of the lines, separated by hard
+// breaks.
+func adfParagraph(lines []string) interface{} {
+	content := []interface{}{}
+	for i, line := range lines {
+		if i > 0 {
+			content = append(content, map[string]interface{}{"type": "hardBreak"})
+		}
+		content = append(content, adfInline(line)...)
+	}
+
+	return map[string]interface{}{
+		"type":    "paragraph",
+		"content": content,
+	}
+}
+
+// adfInline returns the ADF text nodes of a line, with Markdown links
+// converted to text with a link mark.
+func adfInline(line string) []interface{} {
+	var nodes []interface{}
+
+	last := 0
+	for _, loc := range linkRegex.FindAllStringSubmatchIndex(line, -1) {
+		nodes = appendADFText(nodes, line[last:loc[0]])
+		nodes = append(nodes, map[string]interface{}{
+			"type": "text",
+			"text": line[loc[2]:loc[3]],
+			"marks": []interface{}{
+				map[string]interface{}{
+					"type":  "link",
+					"attrs": map[string]interface{}{"href": line[loc[4]:loc[5]]},
+				},
+			},
+		})
+		last = loc[1]
+	}
+
+	return appendADFText(nodes, line[last:])
+}
+
+// appendADFText appends a text node of the text to the nodes, unless the text
+// is empty, which ADF does not allow.
+func appendADFText(nodes []interface{}, text string) []interface{} {
+	if text == "" {
+		return nodes
+	}
+	return append(nodes, map[string]interface{}{"type": "text", "text": text})
+}
+
+// adfCodeBlock returns the ADF code block of the lines, with the language of
+// the fence, if any.
+func adfCodeBlock(lines []string, language string) interface{} {
+	block := map[string]interface{}{
+		"type":    "codeBlock",
+		"content": appendADFText([]interface{}{}, strings.Join(lines, "\n")),
+	}
+	if language != "" {
+		block["attrs"] = map[string]interface{}{"language": language}
+	}
+
+	return block
+}
+
+// ParseADF returns the ADF document that a Jira comment body holds as JSON.
+// The boolean is false if the body is not an ADF document, such as the wiki
+// markup of a comment written with version 2 of the Jira REST API.
+func ParseADF(body string) (interface{}, bool) {
+	if !strings.HasPrefix(strings.TrimSpace(body), "{") {
+		return nil, false
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil || doc["type"] != "doc" {
+		return nil, false
+	}
+
+	return doc, true
+}
+
+// ADFText returns the text of an ADF document: its blocks separated by blank
+// lines, and the hard breaks of paragraphs as line breaks. Marks are dropped,
+// so a linked text only keeps its text.
+func ADFText(doc interface{}) string {
+	node, ok := doc.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	blocks, _ := node["content"].([]interface{})
+	texts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		texts = append(texts, adfNodeText(block))
+	}
+
+	return strings.Join(texts, "\n\n")
+}
+
+// adfNodeText returns the text of an ADF node and its children.
+func adfNodeText(n interface{}) string {
+	node, ok := n.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	switch node["type"] {
+	case "text":
+		text, _ := node["text"].(string)
+		return text
+	case "hardBreak":
+		return "\n"
+	}
+
+	var b strings.Builder
+	children, _ := node["content"].([]interface{})
+	for _, child := range children {
+		b.WriteString(adfNodeText(child))
+	}
+
+	return b.String()
+}
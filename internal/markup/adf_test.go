@@ -0,0 +1,95 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markup
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestToADF(t *testing.T) {
+	body := "First line\nsecond [line](https://example.com).\n\n```go\nfmt.Println(\"hi\")\n```\nLast"
+
+	expected := map[string]interface{}{
+		"version": float64(1),
+		"type":    "doc",
+		"content": []interface{}{
+			map[string]interface{}{
+				"type": "paragraph",
+				"content": []interface{}{
+					map[string]interface{}{"type": "text", "text": "First line"},
+					map[string]interface{}{"type": "hardBreak"},
+					map[string]interface{}{"type": "text", "text": "second "},
+					map[string]interface{}{
+						"type": "text",
+						"text": "line",
+						"marks": []interface{}{
+							map[string]interface{}{
+								"type":  "link",
+								"attrs": map[string]interface{}{"href": "https://example.com"},
+							},
+						},
+					},
+					map[string]interface{}{"type": "text", "text": "."},
+				},
+			},
+			map[string]interface{}{
+				"type":    "codeBlock",
+				"attrs":   map[string]interface{}{"language": "go"},
+				"content": []interface{}{map[string]interface{}{"type": "text", "text": "fmt.Println(\"hi\")"}},
+			},
+			map[string]interface{}{
+				"type":    "paragraph",
+				"content": []interface{}{map[string]interface{}{"type": "text", "text": "Last"}},
+			},
+		},
+	}
+
+	doc := ToADF(body)
+	if !reflect.DeepEqual(doc, expected) {
+		t.Fatalf("Expected ADF document:\n%#v\nGot:\n%#v", expected, doc)
+	}
+
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("encoding ADF document: %v", err)
+	}
+	parsed, ok := ParseADF(string(encoded))
+	if !ok {
+		t.Fatalf("Expected %s to be parsed as an ADF document", encoded)
+	}
+	if !reflect.DeepEqual(parsed, expected) {
+		t.Fatalf("Expected the parsed document to equal the converted one; got %#v", parsed)
+	}
+
+	if text := ADFText(parsed); text != "First line\nsecond line.\n\nfmt.Println(\"hi\")\n\nLast" {
+		t.Fatalf("Unexpected ADF text %q", text)
+	}
+}
+
+func TestParseADFRejectsWikiMarkup(t *testing.T) {
+	for _, body := range []string{
+		"{anchor:gh-comment:1}Comment [(ID 1)|https://github.com]",
+		`{"type": "paragraph"}`,
+		"",
+	} {
+		if _, ok := ParseADF(body); ok {
+			t.Fatalf("Expected %q not to be parsed as an ADF document", body)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package markup converts the GitHub Flavored Markdown of GitHub issues and
+// comments to the wiki markup rendered by Jira.
+package markup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	fenceRegex      = regexp.MustCompile("^\\s*(```|~~~)\\s*([\\w+-]*)\\s*$")
+	headingRegex    = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	quoteRegex      = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	listItemRegex   = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	ruleRegex       = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+	imageRegex      = regexp.MustCompile(`!\[[^\]]*]\(([^)\s]+)[^)]*\)`)
+	linkRegex       = regexp.MustCompile(`\[([^\]]+)]\(([^)\s]+)[^)]*\)`)
+	boldRegex       = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	italicRegex     = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	strikeRegex     = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	inlineCodeRegex = regexp.MustCompile("`([^`]+)`")
+)
+
+// escaper escapes the characters interpreted by Jira wiki markup with a
+// backslash. Backslashes are escaped as well, so that a literal backslash
+// does not escape the character following it.
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	"{", `\{`,
+	"}", `\}`,
+	"[", `\[`,
+	"]", `\]`,
+	"*", `\*`,
+	"_", `\_`,
+	"-", `\-`,
+	"+", `\+`,
+	"^", `\^`,
+	"~", `\~`,
+	"|", `\|`,
+	"!", `\!`,
+	"#", `\#`,
+)
+
+// boldPlaceholder stands in for the `*` of converted bold text while italic
+// text is converted, so that it is not converted again.
+const boldPlaceholder = "\x00"
+
+// ToJira converts a GitHub Markdown body to Jira wiki markup. Headings,
+// emphasis, strikethrough, inline code, fenced code blocks, links, images,
+// lists, block quotes and horizontal rules are converted; anything else is
+// left as is. The content of code blocks and inline code is never converted.
+func ToJira(body string) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+
+	converted := make([]string, 0, len(lines))
+	fence := ""
+	for _, line := range lines {
+		if fence != "" {
+			if m := fenceRegex.FindStringSubmatch(line); m != nil && m[1] == fence && m[2] == "" {
+				fence = ""
+				converted = append(converted, "{code}")
+				continue
+			}
+			converted = append(converted, line)
+			continue
+		}
+
+		if m := fenceRegex.FindStringSubmatch(line); m != nil {
+			fence = m[1]
+			if m[2] != "" {
+				converted = append(converted, fmt.Sprintf("{code:%s}", m[2]))
+			} else {
+				converted = append(converted, "{code}")
+			}
+			continue
+		}
+
+		converted = append(converted, convertLine(line))
+	}
+
+	// Close a code block left open by the Markdown, as Jira would otherwise
+	// not render the rest of the body at all.
+	if fence != "" {
+		converted = append(converted, "{code}")
+	}
+
+	return strings.Join(converted, "\n")
+}
+
+// convertLine converts a line outside of code blocks.
+func convertLine(line string) string {
+	if ruleRegex.MatchString(line) {
+		return "----"
+	}
+
+	if m := headingRegex.FindStringSubmatch(line); m != nil {
+		return fmt.Sprintf("h%d. %s", len(m[1]), convertInline(m[2]))
+	}
+
+	if m := quoteRegex.FindStringSubmatch(line); m != nil {
+		return "bq. " + convertInline(m[1])
+	}
+
+	if m := listItemRegex.FindStringSubmatch(line); m != nil {
+		// Nested items are indented by (at least) two spaces per level.
+		depth := len(strings.ReplaceAll(m[1], "\t", "  "))/2 + 1
+		marker := "*"
+		if m[2][0] >= '0' && m[2][0] <= '9' {
+			marker = "#"
+		}
+		return strings.Repeat(marker, depth) + " " + convertInline(m[3])
+	}
+
+	return convertInline(line)
+}
+
+// convertInline converts the inline markup of a line, leaving inline code
+// untouched.
+func convertInline(line string) string {
+	var b strings.Builder
+
+	last := 0
+	for _, loc := range inlineCodeRegex.FindAllStringSubmatchIndex(line, -1) {
+		b.WriteString(convertText(line[last:loc[0]]))
+		b.WriteString("{{" + line[loc[2]:loc[3]] + "}}")
+		last = loc[1]
+	}
+	b.WriteString(convertText(line[last:]))
+
+	return b.String()
+}
+
+// convertText converts the inline markup of text without inline code.
+func convertText(s string) string {
+	s = imageRegex.ReplaceAllString(s, "!$1!")
+	s = linkRegex.ReplaceAllString(s, "[$1|$2]")
+	s = boldRegex.ReplaceAllString(s, boldPlaceholder+"$1$2"+boldPlaceholder)
+	s = italicRegex.ReplaceAllString(s, "_${1}_")
+	s = strikeRegex.ReplaceAllString(s, "-$1-")
+	return strings.ReplaceAll(s, boldPlaceholder, "*")
+}
+
+// Escape escapes the characters of a body which Jira wiki markup
+// interprets, so that Jira displays the body literally rather than
+// rendering it. Unlike ToJira, it does not convert any Markdown.
+func Escape(body string) string {
+	return escaper.Replace(body)
+}
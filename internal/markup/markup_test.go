@@ -0,0 +1,128 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markup
+
+import "testing"
+
+func TestToJira(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "plain text",
+			body:     "Nothing to see here.",
+			expected: "Nothing to see here.",
+		},
+		{
+			name:     "headings",
+			body:     "# Title\n### Details ###",
+			expected: "h1. Title\nh3. Details",
+		},
+		{
+			name:     "emphasis",
+			body:     "**bold**, __also bold__, *italic*, _italic_ and ~~struck~~",
+			expected: "*bold*, *also bold*, _italic_, _italic_ and -struck-",
+		},
+		{
+			name:     "snake case is not emphasis",
+			body:     "set snake_case_name",
+			expected: "set snake_case_name",
+		},
+		{
+			name:     "inline code",
+			body:     "run `go test **/*` now",
+			expected: "run {{go test **/*}} now",
+		},
+		{
+			name:     "links and images",
+			body:     "see [the docs](https://example.com/docs \"Docs\") and ![logo](https://example.com/logo.png)",
+			expected: "see [the docs|https://example.com/docs] and !https://example.com/logo.png!",
+		},
+		{
+			name:     "lists",
+			body:     "- one\n  - nested\n* two\n1. first\n2. second",
+			expected: "* one\n** nested\n* two\n# first\n# second",
+		},
+		{
+			name:     "quotes and rules",
+			body:     "> quoted *text*\n\n---",
+			expected: "bq. quoted _text_\n\n----",
+		},
+		{
+			name:     "code block",
+			body:     "Before\r\n```go\r\n# not a heading\r\nx := **y\r\n```\r\nAfter",
+			expected: "Before\n{code:go}\n# not a heading\nx := **y\n{code}\nAfter",
+		},
+		{
+			name:     "unterminated code block",
+			body:     "~~~\nfoo",
+			expected: "{code}\nfoo\n{code}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToJira(tt.body); got != tt.expected {
+				t.Errorf("ToJira(%q) = %q; expected %q", tt.body, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "plain text",
+			body:     "Nothing to see here.",
+			expected: "Nothing to see here.",
+		},
+		{
+			name:     "macros and links",
+			body:     "use {code} or [a link|https://example.com]",
+			expected: `use \{code\} or \[a link\|https://example.com\]`,
+		},
+		{
+			name:     "emphasis",
+			body:     "*bold*, _italic_, -struck-, +under+, ^sup^ and ~sub~",
+			expected: `\*bold\*, \_italic\_, \-struck\-, \+under\+, \^sup\^ and \~sub\~`,
+		},
+		{
+			name:     "images and lists",
+			body:     "!image.png!\n# first",
+			expected: "\\!image.png\\!\n\\# first",
+		},
+		{
+			name:     "backslashes",
+			body:     `C:\path\{x}`,
+			expected: `C:\\path\\\{x\}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Escape(tt.body); got != tt.expected {
+				t.Errorf("Escape(%q) = %q; expected %q", tt.body, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metrics exposes Prometheus metrics about synchronizations.
+//
+// The metrics are always recorded, but only registered and served by Serve,
+// when `metrics-addr` is set.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+// Path is the path the metrics are served on.
+const Path = "/metrics"
+
+// shutdownTimeout is how long the server waits for the requests in flight
+// on shutdown.
+const shutdownTimeout = 5 * time.Second
+
+var (
+	// IssuesCreated counts the Jira issues created from GitHub issues.
+	IssuesCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "issues_created_total",
+		Help: "Number of Jira issues created from GitHub issues.",
+	})
+
+	// IssuesUpdated counts the Jira issues updated to match their GitHub
+	// issue. Jira issues which were already up to date are not counted.
+	IssuesUpdated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "issues_updated_total",
+		Help: "Number of Jira issues updated to match their GitHub issue.",
+	})
+
+	// IssuesFailed counts the GitHub issues which failed to synchronize.
+	IssuesFailed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "issues_failed_total",
+		Help: "Number of GitHub issues which failed to synchronize.",
+	})
+
+	// CommentsCreated counts the Jira comments created from GitHub comments.
+	CommentsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "comments_created_total",
+		Help: "Number of Jira comments created from GitHub comments.",
+	})
+
+	// SyncDuration observes the duration of synchronizations, over every
+	// repository.
+	SyncDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "sync_duration_seconds",
+		Help:    "Duration of synchronizations, in seconds.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	})
+)
+
+// collectors are the metrics registered by Serve.
+var collectors = []prometheus.Collector{
+	IssuesCreated,
+	IssuesUpdated,
+	IssuesFailed,
+	CommentsCreated,
+	SyncDuration,
+}
+
+// Serve registers the metrics with a new registry, and serves them on Path
+// of the listener until the context is canceled.
+func Serve(ctx context.Context, listener net.Listener) error {
+	registry := prometheus.NewRegistry()
+	for _, c := range collectors {
+		if err := registry.Register(c); err != nil {
+			return fmt.Errorf("registering metrics: %w", err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(Path, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: shutdownTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		// The context is done, so a new one bounds the shutdown.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Errorf("Error shutting down the metrics server: %v", err)
+		}
+	}()
+
+	log.Infof("Serving metrics on %s%s", listener.Addr(), Path)
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving metrics: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, listener)
+	}()
+
+	IssuesCreated.Inc()
+	SyncDuration.Observe(3)
+
+	res, err := http.Get("http://" + listener.Addr().String() + Path)
+	if err != nil {
+		t.Fatalf("getting metrics: %v", err)
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("reading metrics: %v", err)
+	}
+
+	for _, name := range []string{
+		"issues_created_total 1",
+		"issues_updated_total",
+		"issues_failed_total",
+		"comments_created_total",
+		"sync_duration_seconds_count 1",
+	} {
+		if !strings.Contains(string(body), name) {
+			t.Fatalf("Expected the metrics to contain %q; got:\n%s", name, body)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Serve() returned error on shutdown: %v", err)
+	}
+}
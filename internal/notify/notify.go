@@ -0,0 +1,88 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package notify sends notifications about synchronizations to external
+// services.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Summary is the JSON payload describing a synchronization.
+type Summary struct {
+	// Repo is the GitHub repository, in the form `owner/repo`.
+	Repo string `json:"repo"`
+	// JiraProject is the key of the Jira project.
+	JiraProject string `json:"jira-project"`
+
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+
+	// Error is the error which aborted the synchronization, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// HasFailures returns whether the synchronization failed, entirely or for
+// some of the issues.
+func (s *Summary) HasFailures() bool {
+	return s.Failed > 0 || s.Error != ""
+}
+
+// PostFailures posts the summary as JSON to the webhook URL if the
+// synchronization had failures, and does nothing otherwise.
+func PostFailures(ctx context.Context, client *http.Client, url string, summary *Summary) error {
+	if !summary.HasFailures() {
+		return nil
+	}
+
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("marshalling failure summary: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating failure webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting to failure webhook: %w", err)
+	}
+	defer res.Body.Close()
+
+	// Drain the body so that the connection can be reused.
+	io.Copy(io.Discard, res.Body) //nolint:errcheck
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("posting to failure webhook: unexpected status %s", res.Status) //nolint:goerr113
+	}
+
+	log.Debugf("Posted failure summary to webhook (%d failed)", summary.Failed)
+
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 uwu-tools Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPostFailures(t *testing.T) {
+	var calls int
+	var got Summary
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST request; got %s", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected JSON content type; got %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	summary := &Summary{
+		Repo:        "test-owner/test-repo",
+		JiraProject: "TEST",
+		Created:     2,
+		Updated:     5,
+		Failed:      1,
+	}
+
+	if err := PostFailures(context.Background(), server.Client(), server.URL, summary); err != nil {
+		t.Fatalf("PostFailures() returned error: %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("Expected webhook to be called once; got %d calls", calls)
+	}
+	if got != *summary {
+		t.Fatalf("Expected payload %+v; got %+v", *summary, got)
+	}
+}
+
+func TestPostFailuresCleanRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected webhook not to be called on a clean run")
+	}))
+	defer server.Close()
+
+	summary := &Summary{
+		Repo:        "test-owner/test-repo",
+		JiraProject: "TEST",
+		Created:     2,
+		Updated:     5,
+	}
+
+	if err := PostFailures(context.Background(), server.Client(), server.URL, summary); err != nil {
+		t.Fatalf("PostFailures() returned error: %v", err)
+	}
+}
+
+func TestPostFailuresWebhookError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	summary := &Summary{Error: "listing GitHub issues: rate limited"}
+	if err := PostFailures(context.Background(), server.Client(), server.URL, summary); err == nil {
+		t.Fatal("Expected an error when the webhook fails")
+	}
+}
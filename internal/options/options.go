@@ -24,7 +24,9 @@ import (
 
 type Options struct {
 	LogLevel     string
-	ConfigFile   string
+	Color        string
+	LogFormat    string
+	ConfigFiles  []string
 	GitHubToken  string
 	JiraUser     string
 	JiraPassword string
@@ -37,6 +39,97 @@ type Options struct {
 	Confirm        bool
 	Timeout        time.Duration
 	Period         time.Duration
+	MaxRunDuration time.Duration
+	MaxRetryAfter  time.Duration
+	LinkDuplicates bool
+	MatchStrategy  string
+
+	MaxDescriptionLength  int
+	SinceFromLastJiraSync bool
+	SyncedLabel           string
+
+	LabelSpaceReplacement string
+	FullReconcileEvery    int
+	SyncMode              string
+
+	ReleaseClosedMilestones bool
+	FailureWebhookURL       string
+	EnvironmentHeading      string
+	AssigneeChangeComments  bool
+	RelinkBySummary         bool
+	RespectJiraUpdates      bool
+	SyncUpdateWindow        time.Duration
+	CommentStripQuotes      bool
+	CommentSeparator        string
+	CreateRate              float64
+	SyncTimeline            bool
+	ConvertMarkdown         bool
+	EscapeMarkup            bool
+	DefaultReporter         string
+	IncludeLabels           []string
+	ExcludeLabels           []string
+	OptionalFields          []string
+	PruneIssues             bool
+	PruneTransition         string
+	PruneLabel              string
+	SyncMilestones          bool
+	StartupDelay            time.Duration
+	SyncIssueState          string
+	LinkClosingPullRequests bool
+	LogRateLimitWaits       bool
+	DueDateLabelPrefix      string
+	RateLimitBuffer         int
+	SyncLabelColors         bool
+	LogRunID                bool
+	MaxLabels               int
+	LabelOrder              string
+	FieldRefreshInterval    time.Duration
+	LabelPrefix             string
+	ExcludeJiraStatus       []string
+	ExcludedStatusComments  bool
+	MilestoneAsSprint       bool
+	JiraBoardID             int
+	Explain                 bool
+	MetricsAddr             string
+	NoSave                  bool
+	StateFile               string
+	JiraAPIVersion          string
+	RetryInitialInterval    time.Duration
+	RetryMaxInterval        time.Duration
+	RetryMultiplier         float64
+	JiraReporter            string
+
+	// CommentTemplate is a text/template rendering the header of the Jira
+	// comments copied from GitHub.
+	CommentTemplate string
+
+	// ReporterFormat is a text/template rendering the `github-reporter`
+	// field from the author of the GitHub issue.
+	ReporterFormat string
+
+	// CheckAuth only checks whether the Jira credentials authenticate.
+	CheckAuth bool
+
+	// JiraBearerToken is a Jira Data Center personal access token.
+	JiraBearerToken string
+
+	// GitHubAppID, GitHubAppInstallationID and GitHubAppPrivateKeyPath
+	// authenticate as an installation of a GitHub App, instead of with
+	// GitHubToken.
+	GitHubAppID             int64
+	GitHubAppInstallationID int64
+	GitHubAppPrivateKeyPath string
+
+	// ExportOutput is the file the `export` command writes to.
+	ExportOutput string
+
+	// ExportListUnmatched exports the GitHub issues without a Jira issue,
+	// instead of the mapping.
+	ExportListUnmatched bool
+
+	// Format is the format the `export` and `doctor` commands write: json,
+	// yaml, toml, or the default of the command, csv or text.
+	Format string
 }
 
 const (
@@ -46,38 +139,247 @@ const (
 	DateFormat = "2006-01-02T15:04:05-0700"
 
 	// Application config keys.
-	ConfigKeyLogLevel   = "log-level"
-	ConfigKeyConfigFile = "config"
-	ConfigKeySince      = "since"
-	ConfigKeyConfirm    = "confirm"
-	ConfigKeyPeriod     = "period"
-	ConfigKeyTimeout    = "timeout"
+	ConfigKeyLogLevel       = "log-level"
+	ConfigKeyColor          = "color"
+	ConfigKeyLogFormat      = "log-format"
+	ConfigKeyConfigFile     = "config"
+	ConfigKeySince          = "since"
+	ConfigKeyConfirm        = "confirm"
+	ConfigKeyPeriod         = "period"
+	ConfigKeyTimeout        = "timeout"
+	ConfigKeyMaxRunDuration = "max-run-duration"
+	ConfigKeyMaxRetryAfter  = "max-retry-after"
+
+	// Export command keys.
+	ConfigKeyExportOutput        = "output"
+	ConfigKeyExportListUnmatched = "list-unmatched"
+
+	// Export and doctor command keys.
+	ConfigKeyFormat = "format"
 
 	// GitHub config keys.
 	ConfigKeyRepoName    = "repo-name"
 	ConfigKeyGitHubToken = "github-token"
 
+	// GitHub App config keys.
+	ConfigKeyGitHubAppID             = "github-app-id"
+	ConfigKeyGitHubAppInstallationID = "github-app-installation-id"
+	ConfigKeyGitHubAppPrivateKeyPath = "github-app-private-key-path"
+
 	// Jira config keys.
 	ConfigKeyJiraURI            = "jira-uri"
 	ConfigKeyJiraProject        = "jira-project"
 	ConfigKeyJiraUser           = "jira-user"
 	ConfigKeyJiraPassword       = "jira-pass"
+	ConfigKeyJiraBearerToken    = "jira-bearer-token"
 	ConfigKeyJiraToken          = "jira-token"
 	ConfigKeyJiraSecret         = "jira-secret"
 	ConfigKeyJiraConsumerKey    = "jira-consumer-key"
 	ConfigKeyJiraPrivateKeyPath = "jira-private-key-path"
 	ConfigKeyJiraComponents     = "jira-components"
 
+	// Sync behaviour config keys.
+	ConfigKeyLinkDuplicates = "link-duplicates"
+	ConfigKeyMatchStrategy  = "match-strategy"
+
+	ConfigKeyMaxDescriptionLength  = "max-description-length"
+	ConfigKeySinceFromLastJiraSync = "since-from-last-jira-sync"
+	ConfigKeySyncedLabel           = "synced-label"
+	ConfigKeyLabelSpaceReplacement = "label-space-replacement"
+	ConfigKeyFullReconcileEvery    = "full-reconcile-every"
+	ConfigKeySyncMode              = "sync-mode"
+
+	ConfigKeyReleaseClosedMilestones = "release-closed-milestones"
+	ConfigKeyFailureWebhookURL       = "failure-webhook-url"
+	ConfigKeyEnvironmentHeading      = "environment-heading"
+	ConfigKeyAssigneeChangeComments  = "assignee-change-comments"
+	ConfigKeyRelinkBySummary         = "relink-by-summary"
+	ConfigKeyLabelTypeMap            = "label-type-map"
+	ConfigKeyRespectJiraUpdates      = "respect-jira-updates"
+	ConfigKeySyncUpdateWindow        = "sync-update-window"
+	ConfigKeyCommentStripQuotes      = "comment-strip-quotes"
+	ConfigKeyCommentSeparator        = "comment-separator"
+	ConfigKeyStatusTransitionMap     = "status-transition-map"
+	ConfigKeyCreateRate              = "create-rate"
+	ConfigKeySyncTimeline            = "sync-timeline"
+	ConfigKeyConvertMarkdown         = "convert-markdown"
+	ConfigKeyEscapeMarkup            = "escape-markup"
+	ConfigKeyDefaultReporter         = "default-reporter"
+	ConfigKeyIncludeLabels           = "include-labels"
+	ConfigKeyExcludeLabels           = "exclude-labels"
+	ConfigKeyOptionalFields          = "optional-fields"
+	ConfigKeyPruneIssues             = "prune-issues"
+	ConfigKeyPruneDeleted            = "prune-deleted"
+	ConfigKeyPruneTransition         = "prune-transition"
+	ConfigKeyPruneLabel              = "prune-label"
+	ConfigKeySyncMilestones          = "sync-milestones"
+	ConfigKeyStartupDelay            = "startup-delay"
+	ConfigKeySyncIssueState          = "sync-issue-state"
+	ConfigKeyCheckAuth               = "check-auth"
+	ConfigKeyCommentTemplate         = "comment-template"
+	ConfigKeyFieldTransforms         = "field-transforms"
+	ConfigKeyLinkClosingPullRequests = "link-closing-pull-requests"
+	ConfigKeyLogRateLimitWaits       = "log-rate-limit-waits"
+	ConfigKeyDueDateLabelPrefix      = "due-date-label-prefix"
+	ConfigKeyRateLimitBuffer         = "rate-limit-buffer"
+	ConfigKeySyncLabelColors         = "sync-label-colors"
+	ConfigKeyLogRunID                = "log-run-id"
+	ConfigKeyMaxLabels               = "max-labels"
+	ConfigKeyLabelOrder              = "label-order"
+	ConfigKeyReporterFormat          = "reporter-format"
+	ConfigKeyFieldRefreshInterval    = "field-refresh-interval"
+	ConfigKeyLabelPrefix             = "label-prefix"
+	ConfigKeyExcludeJiraStatus       = "exclude-jira-status"
+	ConfigKeyExcludedStatusComments  = "excluded-status-comments"
+	ConfigKeyMilestoneAsSprint       = "milestone-as-sprint"
+	ConfigKeyJiraBoardID             = "jira-board-id"
+	ConfigKeyExplain                 = "explain"
+	ConfigKeyMetricsAddr             = "metrics-addr"
+	ConfigKeyNoSave                  = "no-save"
+	ConfigKeyStateFile               = "state-file"
+	ConfigKeyJiraAPIVersion          = "jira-api-version"
+	ConfigKeyRetryInitialInterval    = "retry-initial-interval"
+	ConfigKeyRetryMaxInterval        = "retry-max-interval"
+	ConfigKeyRetryMultiplier         = "retry-multiplier"
+	ConfigKeyJiraReporter            = "jira-reporter"
+
+	// Issue match strategies.
+	//
+	// MatchStrategyJiraField matches GitHub issues to Jira issues using the
+	// `github-id` custom field of the Jira issue.
+	MatchStrategyJiraField = "jira-field"
+	// MatchStrategyGitHubMarker matches GitHub issues to Jira issues using the
+	// Jira issue key written back to the GitHub issue.
+	MatchStrategyGitHubMarker = "github-marker"
+
+	// Sync modes.
+	//
+	// SyncModeCreateOnly creates missing Jira issues, but never modifies
+	// existing ones.
+	SyncModeCreateOnly = "create-only"
+	// SyncModeUpdateOnly updates existing Jira issues, but never creates new
+	// ones.
+	SyncModeUpdateOnly = "update-only"
+	// SyncModeBoth creates missing Jira issues and updates existing ones.
+	SyncModeBoth = "both"
+
+	// States of the GitHub issues to synchronize.
+	//
+	// IssueStateOpen only synchronizes open GitHub issues.
+	IssueStateOpen = "open"
+	// IssueStateClosed only synchronizes closed GitHub issues.
+	IssueStateClosed = "closed"
+	// IssueStateAll synchronizes GitHub issues of any state.
+	IssueStateAll = "all"
+
+	// Color modes of the log output.
+	//
+	// ColorAuto colors the log output if it is a terminal.
+	ColorAuto = "auto"
+	// ColorAlways always colors the log output.
+	ColorAlways = "always"
+	// ColorNever never colors the log output.
+	ColorNever = "never"
+
+	// Formats of the log output.
+	//
+	// LogFormatText logs entries as text, with their fields as `key=value`.
+	LogFormatText = "text"
+	// LogFormatJSON logs each entry as a JSON object.
+	LogFormatJSON = "json"
+
+	// Versions of the Jira REST API of the requests built by issue-sync.
+	//
+	// JiraAPIVersion2 is the Jira REST API v2, whose bodies are wiki markup.
+	JiraAPIVersion2 = "2"
+	// JiraAPIVersion3 is the Jira Cloud REST API v3, whose rich text bodies
+	// are Atlassian Document Format (ADF) documents.
+	JiraAPIVersion3 = "3"
+
+	// Orders of the labels kept when a GitHub issue has more than
+	// `max-labels`.
+	//
+	// LabelOrderGitHub keeps the labels in the order GitHub lists them.
+	LabelOrderGitHub = "github"
+	// LabelOrderName keeps the labels in alphabetical order of their names.
+	LabelOrderName = "name"
+
 	// Default values
 	//
 	// DefaultLogLevel is the level logrus should default to if the configured
 	// option can't be parsed.
 	DefaultLogLevel       = logrus.InfoLevel
 	DefaultConfigFileName = ".issue-sync.json"
+	DefaultStateFileName  = ".issue-sync-state.json"
 	DefaultSince          = "1970-01-01T00:00:00+0000"
+	DefaultColor          = ColorAuto
+	DefaultLogFormat      = LogFormatText
 	DefaultConfirm        = false
 	DefaultPeriod         = time.Hour
 	DefaultTimeout        = 30 * time.Second
+	DefaultLinkDuplicates = false
+	DefaultMaxRunDuration = time.Duration(0)
+	DefaultMaxRetryAfter  = time.Minute
+	DefaultMatchStrategy  = MatchStrategyJiraField
+
+	DefaultMaxDescriptionLength  = 0
+	DefaultSinceFromLastJiraSync = false
+	DefaultSyncedLabel           = ""
+	DefaultLabelSpaceReplacement = "-"
+	DefaultFullReconcileEvery    = 0
+	DefaultSyncMode              = SyncModeBoth
+
+	DefaultReleaseClosedMilestones = false
+	DefaultFailureWebhookURL       = ""
+	DefaultEnvironmentHeading      = ""
+	DefaultAssigneeChangeComments  = false
+	DefaultRelinkBySummary         = false
+	DefaultRespectJiraUpdates      = false
+	DefaultSyncUpdateWindow        = time.Minute
+	DefaultCommentStripQuotes      = false
+	DefaultCommentSeparator        = ""
+	DefaultCreateRate              = 0.0
+	DefaultSyncTimeline            = false
+	DefaultConvertMarkdown         = false
+	DefaultEscapeMarkup            = false
+
+	// DefaultDefaultReporter is the login GitHub shows for deleted accounts.
+	DefaultDefaultReporter = "ghost"
+
+	DefaultPruneIssues     = false
+	DefaultPruneTransition = "Done"
+	DefaultPruneLabel      = "orphaned"
+
+	DefaultSyncMilestones = false
+	DefaultStartupDelay   = time.Duration(0)
+	DefaultSyncIssueState = IssueStateAll
+
+	DefaultLinkClosingPullRequests = false
+	DefaultLogRateLimitWaits       = true
+	DefaultDueDateLabelPrefix      = ""
+	DefaultRateLimitBuffer         = 0
+	DefaultSyncLabelColors         = false
+	DefaultLogRunID                = false
+	DefaultMaxLabels               = 0
+	DefaultLabelOrder              = LabelOrderGitHub
+	DefaultFieldRefreshInterval    = time.Duration(0)
+	DefaultLabelPrefix             = ""
+	DefaultExcludedStatusComments  = false
+	DefaultMilestoneAsSprint       = false
+	DefaultJiraBoardID             = 0
+	DefaultExplain                 = false
+	DefaultMetricsAddr             = ""
+	DefaultNoSave                  = false
+	DefaultStateFile               = ""
+	DefaultJiraAPIVersion          = JiraAPIVersion2
+	DefaultRetryInitialInterval    = 500 * time.Millisecond
+	DefaultRetryMaxInterval        = time.Minute
+	DefaultRetryMultiplier         = 1.5
+	DefaultJiraReporter            = ""
+
+	// DefaultIssueType is the type of created Jira issues whose GitHub
+	// labels match no rule of `label-type-map`.
+	DefaultIssueType = "Task"
 )
 
 var DefaultLogLevelStr = DefaultLogLevel.String()